@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
@@ -23,12 +24,20 @@ type Agent struct {
 	peerClient  *cluster.PeerClient
 	clusterMgr  *cluster.Manager
 	incidentMgr *consensus.IncidentManager
+	reports     *consensus.ReportTracker
 	alerter     *alert.Dispatcher
 	startTime   time.Time
 
+	// captureSlots bounds the traces running for path captures.
+	captureSlots chan struct{}
+
 	mu             sync.RWMutex
 	lastHeartbeat  time.Time
 	lastConfigSync time.Time
+
+	// regionShortfall holds, by monitor ID and incident kind, the number
+	// of online regions last logged as too few for a regions quorum.
+	regionShortfall map[string]int
 }
 
 // New creates a new Agent instance.
@@ -40,8 +49,12 @@ func New(cfg *config.Config, st store.Store) *Agent {
 		peerClient:  cluster.NewPeerClient(),
 		clusterMgr:  cluster.NewManager(cfg, st),
 		incidentMgr: consensus.NewIncidentManager(st),
+		reports:     consensus.NewReportTracker(st),
 		alerter:     alert.NewDispatcher(st),
 		startTime:   time.Now(),
+
+		captureSlots:    make(chan struct{}, maxConcurrentTraces),
+		regionShortfall: make(map[string]int),
 	}
 
 	// Set up result callback: non-coordinators push results to coordinator
@@ -63,6 +76,18 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	// Register all check types
 	checker.RegisterAll()
+	checker.Register(checker.NewDNSChecker(a.store))
+	checker.Register(checker.NewKeywordChecker(a.store))
+	checker.Register(checker.NewDomainChecker(a.store))
+	checker.Register(&pushChecker{store: a.store})
+	execCfg := a.config.Exec
+	if execCfg == nil {
+		execCfg = &config.ExecConfig{}
+	}
+	checker.Register(checker.NewExecChecker(execCfg.Allow, execCfg.MaxOutputBytes))
+	if secrets := a.config.Secrets; secrets != nil {
+		checker.AllowSecrets(secrets.Env, secrets.Files)
+	}
 
 	// Start the monitor sync loop
 	go a.syncLoop(ctx)
@@ -164,6 +189,9 @@ func (a *Agent) pushConfigSync() {
 		log.Printf("[agent] config-sync: error loading monitors: %v", err)
 		return
 	}
+	for i := range monitors {
+		monitors[i] = model.WithholdSecrets(monitors[i])
+	}
 
 	nodes, err := a.store.ListNodes()
 	if err != nil {
@@ -238,6 +266,9 @@ func (a *Agent) applyConfigSync(sync *model.ConfigSync) {
 			log.Printf("[agent] config-pull: error checking monitor %s: %v", m.ID, err)
 			continue
 		}
+		// Inline secrets are withheld from config sync, so clear their
+		// placeholders rather than check with them.
+		model.KeepRedactedSecrets(m.Options, nil)
 		if existing != nil {
 			if err := a.store.UpdateMonitor(m); err != nil {
 				log.Printf("[agent] config-pull: error updating monitor %s: %v", m.ID, err)
@@ -298,42 +329,126 @@ func (a *Agent) evaluateConsensus() {
 		return
 	}
 
-	totalNodes := len(onlineNodes)
-	if totalNodes == 0 {
+	if len(onlineNodes) == 0 {
 		return
 	}
 
+	// Push monitors are evaluated only here, where every ping ends up, so
+	// the coordinator's own results decide them.
+	var self []model.Node
+	for _, n := range onlineNodes {
+		if n.ID == a.config.NodeID {
+			self = append(self, n)
+		}
+	}
+
 	for _, monitor := range monitors {
-		a.evaluateMonitorConsensus(&monitor, onlineNodes, totalNodes)
+		if monitor.CheckType == model.CheckPush {
+			if len(self) > 0 {
+				a.evaluateMonitorConsensus(&monitor, self)
+			}
+			continue
+		}
+		a.evaluateMonitorConsensus(&monitor, onlineNodes)
+	}
+}
+
+// incidentTrack describes which results open and close one kind of incident.
+type incidentTrack struct {
+	kind       string
+	severity   string
+	quorumType string
+	quorumN    int
+	failing    []model.CheckStatus // statuses counted toward failure_threshold
+	recovered  []model.CheckStatus // statuses counted toward recovery_threshold
+}
+
+func (a *Agent) evaluateMonitorConsensus(monitor *model.Monitor, onlineNodes []model.Node) {
+	// Without a degraded track, degraded results count as failures, as any
+	// result other than up always has.
+	down := incidentTrack{
+		kind:       model.IncidentKindDown,
+		severity:   model.SeverityCritical,
+		quorumType: monitor.QuorumType,
+		quorumN:    monitor.QuorumN,
+		failing:    []model.CheckStatus{model.StatusDown, model.StatusDegraded},
+		recovered:  []model.CheckStatus{model.StatusUp},
+	}
+	if monitor.Options == nil || monitor.Options.Degraded == nil || !monitor.Options.Degraded.Incidents {
+		a.evaluateIncidentTrack(monitor, onlineNodes, down)
+		return
+	}
+
+	// Slow-but-up services get their own incidents when the monitor opts in.
+	down.failing = []model.CheckStatus{model.StatusDown}
+	down.recovered = []model.CheckStatus{model.StatusUp, model.StatusDegraded}
+	a.evaluateIncidentTrack(monitor, onlineNodes, down)
+
+	// A monitor that goes down is no longer degraded, so its degraded
+	// incident closes and the down incident takes over.
+	d := monitor.Options.Degraded
+	track := incidentTrack{
+		kind:       model.IncidentKindDegraded,
+		severity:   d.Severity,
+		quorumType: d.QuorumType,
+		quorumN:    d.QuorumN,
+		failing:    []model.CheckStatus{model.StatusDegraded},
+		recovered:  []model.CheckStatus{model.StatusUp, model.StatusDown},
 	}
+	if track.severity == "" {
+		track.severity = model.SeverityWarning
+	}
+	if track.quorumType == "" {
+		track.quorumType = monitor.QuorumType
+		track.quorumN = monitor.QuorumN
+	}
+	a.evaluateIncidentTrack(monitor, onlineNodes, track)
 }
 
-func (a *Agent) evaluateMonitorConsensus(monitor *model.Monitor, onlineNodes []model.Node, totalNodes int) {
-	failCount := 0
+func (a *Agent) evaluateIncidentTrack(monitor *model.Monitor, onlineNodes []model.Node, track incidentTrack) {
+	var failingNodes []model.Node
 	var failingNodeIDs []string
 
 	for _, node := range onlineNodes {
-		failures, err := a.store.CountConsecutiveFailures(monitor.ID, node.ID)
+		failures, err := a.store.CountConsecutiveResults(monitor.ID, node.ID, track.failing...)
 		if err != nil {
 			log.Printf("[consensus] error counting failures for monitor=%s node=%s: %v", monitor.ID, node.ID, err)
 			continue
 		}
 		if failures >= monitor.FailureThreshold {
-			failCount++
+			failingNodes = append(failingNodes, node)
 			failingNodeIDs = append(failingNodeIDs, node.ID)
 		}
 	}
 
-	quorumMet := consensus.EvaluateQuorum(monitor.QuorumType, monitor.QuorumN, failCount, totalNodes)
+	// A failure seen by one node alone is ignored when that node has a
+	// history of reporting outages the others did not see.
+	votes := failingNodes
+	if len(failingNodes) == 1 && len(onlineNodes) > 1 && consensus.Trust(failingNodes[0]) < a.minTrust() {
+		votes = nil
+	}
+
+	var onlineRegions int
+	if track.quorumType == "regions" {
+		onlineRegions = a.onlineRegions(monitor, track, onlineNodes)
+	}
+
+	quorumMet := consensus.EvaluateQuorum(track.quorumType, track.quorumN, votes, onlineNodes)
+	if track.kind == model.IncidentKindDown {
+		a.reports.Observe(monitor.ID, onlineNodes, failingNodeIDs, quorumMet)
+	}
 
 	if quorumMet {
-		incident, err := a.incidentMgr.GetOrCreateIncident(monitor.ID)
+		incident, err := a.incidentMgr.GetOrCreateIncident(monitor.ID, track.kind, track.severity)
 		if err != nil {
 			log.Printf("[consensus] error getting/creating incident for monitor %s: %v", monitor.ID, err)
 			return
 		}
 		if incident.Status == model.IncidentSuspect {
-			if err := a.incidentMgr.ConfirmIncident(incident, failingNodeIDs); err != nil {
+			if track.kind == model.IncidentKindDown && pathCaptureEnabled(monitor) {
+				go a.capturePaths(incident.ID, *monitor, failingNodeIDs, onlineNodes)
+			}
+			if err := a.incidentMgr.ConfirmIncident(incident, failingNodes); err != nil {
 				log.Printf("[consensus] error confirming incident %s: %v", incident.ID, err)
 				return
 			}
@@ -341,24 +456,29 @@ func (a *Agent) evaluateMonitorConsensus(monitor *model.Monitor, onlineNodes []m
 		}
 	} else {
 		// Check if there's an active incident to resolve
-		incident, err := a.store.GetActiveIncident(monitor.ID)
+		incident, err := a.store.GetActiveIncident(monitor.ID, track.kind)
 		if err != nil || incident == nil {
 			return
 		}
 
 		// Count nodes with enough consecutive successes for recovery
-		recoveryCount := 0
+		var recoveredNodes []model.Node
 		for _, node := range onlineNodes {
-			successes, err := a.store.CountConsecutiveSuccesses(monitor.ID, node.ID)
+			successes, err := a.store.CountConsecutiveResults(monitor.ID, node.ID, track.recovered...)
 			if err != nil {
 				continue
 			}
 			if successes >= monitor.RecoveryThreshold {
-				recoveryCount++
+				recoveredNodes = append(recoveredNodes, node)
 			}
 		}
 
-		recoveryQuorumMet := consensus.EvaluateQuorum(monitor.QuorumType, monitor.QuorumN, recoveryCount, totalNodes)
+		// A regions quorum can only recover from the regions still online.
+		recoveryN := track.quorumN
+		if track.quorumType == "regions" {
+			recoveryN = min(recoveryN, onlineRegions)
+		}
+		recoveryQuorumMet := consensus.EvaluateQuorum(track.quorumType, recoveryN, recoveredNodes, onlineNodes)
 		if recoveryQuorumMet {
 			if err := a.incidentMgr.ResolveIncident(incident); err != nil {
 				log.Printf("[consensus] error resolving incident %s: %v", incident.ID, err)
@@ -369,6 +489,108 @@ func (a *Agent) evaluateMonitorConsensus(monitor *model.Monitor, onlineNodes []m
 	}
 }
 
+// onlineRegions returns the number of regions among the online nodes. It
+// logs when the track's regions quorum needs more, as its incidents cannot
+// be confirmed until enough regions are back.
+func (a *Agent) onlineRegions(monitor *model.Monitor, track incidentTrack, onlineNodes []model.Node) int {
+	regions := len(consensus.GroupByRegion(onlineNodes))
+	key := monitor.ID + "/" + track.kind
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if track.quorumN <= regions {
+		delete(a.regionShortfall, key)
+		return regions
+	}
+	if logged, ok := a.regionShortfall[key]; !ok || logged != regions {
+		a.regionShortfall[key] = regions
+		log.Printf("[consensus] monitor %s needs %s incidents confirmed from %d regions, but only %d are online",
+			monitor.ID, track.kind, track.quorumN, regions)
+	}
+	return regions
+}
+
+// minTrust returns the trust score below which a node's solitary failures
+// are suppressed.
+func (a *Agent) minTrust() float64 {
+	if a.config.Consensus != nil && a.config.Consensus.MinTrust != nil {
+		return *a.config.Consensus.MinTrust
+	}
+	return consensus.DefaultMinTrust
+}
+
+// maxConcurrentTraces is how many path capture traces run at once across
+// all incidents.
+const maxConcurrentTraces = 4
+
+// pathCaptureEnabled reports whether a down incident on the monitor should
+// record traceroutes from every node. Capture is on unless the monitor opts
+// out; captureSlots bounds the probes it sends, and nodes without raw
+// sockets skip their trace.
+func pathCaptureEnabled(monitor *model.Monitor) bool {
+	// Push monitors have no target, and a domain's registration doesn't
+	// depend on the network path to it.
+	if monitor.CheckType == model.CheckPush || monitor.CheckType == model.CheckDomain {
+		return false
+	}
+	return monitor.Options == nil || monitor.Options.Traceroute == nil || !monitor.Options.Traceroute.NoCapture
+}
+
+// capturePaths traces the monitor's target from every online node and
+// stores the paths with the incident, marking the nodes that were failing
+// so their routes can be compared with healthy ones.
+func (a *Agent) capturePaths(incidentID string, monitor model.Monitor, failingNodeIDs []string, onlineNodes []model.Node) {
+	failing := make(map[string]bool, len(failingNodeIDs))
+	for _, id := range failingNodeIDs {
+		failing[id] = true
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range onlineNodes {
+		wg.Add(1)
+		go func(node model.Node) {
+			defer wg.Done()
+			a.captureSlots <- struct{}{}
+			defer func() { <-a.captureSlots }()
+
+			var trace *model.TraceResult
+			if node.ID == a.config.NodeID {
+				if !checker.RawSocketsAvailable() {
+					log.Printf("[consensus] skipping path capture on this node: raw sockets unavailable")
+					return
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), checker.TraceDuration(&monitor))
+				trace = checker.TraceMonitor(ctx, &monitor)
+				cancel()
+			} else {
+				var err error
+				trace, err = a.peerClient.RequestTrace(node.Address, &model.PeerTraceRequest{
+					MonitorID:   monitor.ID,
+					IncidentID:  incidentID,
+					RequestedBy: a.config.NodeID,
+				})
+				if err != nil {
+					log.Printf("[consensus] path capture from node %s failed: %v", node.ID, err)
+					return
+				}
+			}
+
+			capture := &model.PathCapture{
+				IncidentID:  incidentID,
+				NodeID:      node.ID,
+				Failing:     failing[node.ID],
+				TraceResult: *trace,
+				CapturedAt:  time.Now().UnixMilli(),
+			}
+			if err := a.store.InsertPathCapture(capture); err != nil {
+				log.Printf("[consensus] error storing path from node %s for incident %s: %v", node.ID, incidentID, err)
+			}
+		}(node)
+	}
+	wg.Wait()
+	log.Printf("[consensus] captured paths from %d nodes for incident %s", len(onlineNodes), incidentID)
+}
+
 func (a *Agent) syncLoop(ctx context.Context) {
 	// Initial sync
 	a.syncMonitors()
@@ -392,6 +614,14 @@ func (a *Agent) syncMonitors() {
 		log.Printf("[agent] error loading monitors: %v", err)
 		return
 	}
+
+	// Pings for push monitors are relayed to the coordinator, so other
+	// nodes have nothing to evaluate.
+	if a.config.Role != model.RoleCoordinator {
+		monitors = slices.DeleteFunc(monitors, func(m model.Monitor) bool {
+			return m.CheckType == model.CheckPush
+		})
+	}
 	a.scheduler.SyncMonitors(monitors)
 }
 
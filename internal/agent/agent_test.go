@@ -0,0 +1,225 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/config"
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+func TestPathCaptureEnabled(t *testing.T) {
+	noCapture := &model.MonitorOptions{Traceroute: &model.TracerouteOptions{NoCapture: true}}
+
+	tests := []struct {
+		name    string
+		monitor model.Monitor
+		want    bool
+	}{
+		{"no options", model.Monitor{CheckType: model.CheckTCP}, true},
+		{"traceroute options", model.Monitor{
+			CheckType: model.CheckTCP,
+			Options:   &model.MonitorOptions{Traceroute: &model.TracerouteOptions{MaxHops: 10}},
+		}, true},
+		{"opted out", model.Monitor{CheckType: model.CheckTCP, Options: noCapture}, false},
+		{"push monitor", model.Monitor{CheckType: model.CheckPush}, false},
+		{"domain monitor", model.Monitor{CheckType: model.CheckDomain}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathCaptureEnabled(&tt.monitor); got != tt.want {
+				t.Errorf("pathCaptureEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestAgent returns a coordinator agent backed by a fresh store with the
+// given nodes and monitor.
+func newTestAgent(t *testing.T, nodes []model.Node, monitor *model.Monitor) (*Agent, *store.SQLiteStore) {
+	t.Helper()
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	for i := range nodes {
+		if err := st.CreateNode(&nodes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.CreateMonitor(monitor); err != nil {
+		t.Fatal(err)
+	}
+	return New(&config.Config{NodeID: nodes[0].ID, Role: model.RoleCoordinator}, st), st
+}
+
+// report stores one result per node, each newer than the last.
+func report(t *testing.T, st *store.SQLiteStore, monitorID string, statuses map[string]model.CheckStatus) {
+	t.Helper()
+	for nodeID, status := range statuses {
+		err := st.InsertCheckResult(&model.CheckResult{
+			MonitorID: monitorID, NodeID: nodeID, Status: status, Timestamp: time.Now().UnixNano(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRegionsQuorumRecoversFromOnlineRegions(t *testing.T) {
+	nodes := []model.Node{
+		{ID: "eu", Location: "eu-west"},
+		{ID: "us", Location: "us-east"},
+		{ID: "ap", Location: "ap-south"},
+	}
+	monitor := &model.Monitor{
+		ID: "m1", Name: "api", CheckType: model.CheckTCP, Target: "api.example.com", Port: 443,
+		FailureThreshold: 1, RecoveryThreshold: 1, QuorumType: "regions", QuorumN: 3, Enabled: true,
+	}
+	a, st := newTestAgent(t, nodes, monitor)
+
+	report(t, st, "m1", map[string]model.CheckStatus{"eu": model.StatusDown, "us": model.StatusDown, "ap": model.StatusDown})
+	a.evaluateMonitorConsensus(monitor, nodes)
+	incident, _ := st.GetActiveIncident("m1", model.IncidentKindDown)
+	if incident == nil || incident.Status != model.IncidentConfirmed {
+		t.Fatalf("incident = %+v, want a confirmed incident", incident)
+	}
+
+	// With ap offline, three regions can no longer agree on anything, but
+	// the two regions left seeing the monitor up is a recovery.
+	online := nodes[:2]
+	report(t, st, "m1", map[string]model.CheckStatus{"eu": model.StatusUp, "us": model.StatusUp})
+	a.evaluateMonitorConsensus(monitor, online)
+	if incident, _ := st.GetActiveIncident("m1", model.IncidentKindDown); incident != nil {
+		t.Errorf("incident %s still active after every online region recovered", incident.ID)
+	}
+	if got := a.regionShortfall["m1/"+model.IncidentKindDown]; got != 2 {
+		t.Errorf("logged region shortfall = %d, want 2 online regions", got)
+	}
+}
+
+func TestDegradedTrack(t *testing.T) {
+	nodes := []model.Node{{ID: "a"}, {ID: "b"}}
+	newMonitor := func(degraded *model.DegradedOptions) *model.Monitor {
+		return &model.Monitor{
+			ID: "m1", Name: "api", CheckType: model.CheckHTTP, Target: "api.example.com",
+			FailureThreshold: 1, RecoveryThreshold: 1, QuorumType: "all", Enabled: true,
+			Options: &model.MonitorOptions{Degraded: degraded},
+		}
+	}
+	degraded := map[string]model.CheckStatus{"a": model.StatusDegraded, "b": model.StatusDegraded}
+	down := map[string]model.CheckStatus{"a": model.StatusDown, "b": model.StatusDown}
+	up := map[string]model.CheckStatus{"a": model.StatusUp, "b": model.StatusUp}
+
+	active := func(st *store.SQLiteStore, kind string) bool {
+		incident, _ := st.GetActiveIncident("m1", kind)
+		return incident != nil
+	}
+
+	t.Run("degraded counts as down without the track", func(t *testing.T) {
+		monitor := newMonitor(nil)
+		a, st := newTestAgent(t, nodes, monitor)
+		report(t, st, "m1", degraded)
+		a.evaluateMonitorConsensus(monitor, nodes)
+		if !active(st, model.IncidentKindDown) || active(st, model.IncidentKindDegraded) {
+			t.Error("degraded results without a degraded track should open a down incident")
+		}
+	})
+
+	t.Run("degraded track", func(t *testing.T) {
+		monitor := newMonitor(&model.DegradedOptions{Incidents: true})
+		a, st := newTestAgent(t, nodes, monitor)
+
+		report(t, st, "m1", degraded)
+		a.evaluateMonitorConsensus(monitor, nodes)
+		incident, _ := st.GetActiveIncident("m1", model.IncidentKindDegraded)
+		if incident == nil || incident.Severity != model.SeverityWarning || active(st, model.IncidentKindDown) {
+			t.Fatalf("degraded incident = %+v, want a warning and no down incident", incident)
+		}
+
+		// Going down hands over from the degraded incident to a down one.
+		report(t, st, "m1", down)
+		a.evaluateMonitorConsensus(monitor, nodes)
+		if active(st, model.IncidentKindDegraded) || !active(st, model.IncidentKindDown) {
+			t.Error("down results should close the degraded incident and open a down incident")
+		}
+
+		// Coming back degraded recovers the outage and reopens the degraded incident.
+		report(t, st, "m1", degraded)
+		a.evaluateMonitorConsensus(monitor, nodes)
+		if active(st, model.IncidentKindDown) || !active(st, model.IncidentKindDegraded) {
+			t.Error("degraded results should resolve the down incident")
+		}
+
+		report(t, st, "m1", up)
+		a.evaluateMonitorConsensus(monitor, nodes)
+		if active(st, model.IncidentKindDown) || active(st, model.IncidentKindDegraded) {
+			t.Error("up results should resolve every incident")
+		}
+	})
+}
+
+func TestPushChecker(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	c := &pushChecker{store: st}
+
+	now := time.Now()
+	ago := func(d time.Duration) int64 { return now.Add(-d).UnixMilli() }
+	hour := time.Hour
+
+	tests := []struct {
+		name    string
+		created time.Duration // how long ago the monitor was created
+		pings   []model.PushPing
+		want    model.CheckStatus
+		wantErr string
+	}{
+		{"awaiting first ping", 30 * time.Minute, nil, model.StatusUp, ""},
+		{"first ping overdue", 2 * hour, nil, model.StatusDown, "since the monitor was created"},
+		{"recent success", 2 * hour, []model.PushPing{{Kind: model.PushSuccess, ReceivedAt: ago(10 * time.Minute)}}, model.StatusUp, ""},
+		{"within grace", 2 * hour, []model.PushPing{{Kind: model.PushSuccess, ReceivedAt: ago(hour + 30*time.Second)}}, model.StatusUp, ""},
+		{"missed ping", 3 * hour, []model.PushPing{{Kind: model.PushSuccess, ReceivedAt: ago(2 * hour)}}, model.StatusDown, "no ping for"},
+		{"reported failure", 2 * hour, []model.PushPing{{Kind: model.PushFail, Message: "disk full", ReceivedAt: ago(time.Minute)}}, model.StatusDown, "disk full"},
+		{"run in progress", 2 * hour, []model.PushPing{
+			{Kind: model.PushSuccess, ReceivedAt: ago(50 * time.Minute)},
+			{Kind: model.PushStart, ReceivedAt: ago(10 * time.Second)},
+		}, model.StatusUp, ""},
+		{"run never finished", 2 * hour, []model.PushPing{
+			{Kind: model.PushSuccess, ReceivedAt: ago(50 * time.Minute)},
+			{Kind: model.PushStart, ReceivedAt: ago(5 * time.Minute)},
+		}, model.StatusDown, "has not finished"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := &model.Monitor{ID: fmt.Sprintf("m%d", i), CheckType: model.CheckPush,
+				IntervalMS: hour.Milliseconds()}
+			if err := st.CreateMonitor(monitor); err != nil {
+				t.Fatal(err)
+			}
+			monitor.CreatedAt = ago(tt.created)
+			for _, p := range tt.pings {
+				p.MonitorID, p.NodeID = monitor.ID, "n1"
+				if err := st.InsertPushPing(&p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			result, err := c.Check(context.Background(), monitor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantErr) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/checker"
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+const (
+	// defaultPushGrace is how late a ping may be before a push monitor
+	// goes down, unless the monitor sets grace_ms.
+	defaultPushGrace = time.Minute
+
+	// pushCheckInterval caps how often push monitors are evaluated, so a
+	// missed ping is noticed soon after its deadline even on daily jobs.
+	pushCheckInterval = 30 * time.Second
+)
+
+// pushChecker evaluates push monitors from the pings the coordinator has
+// received instead of probing a target. It runs only on the coordinator.
+type pushChecker struct {
+	store store.Store
+}
+
+func (c *pushChecker) Type() model.CheckType { return model.CheckPush }
+
+// Check reports a push monitor down when no ping arrived within the
+// interval plus grace, when a started run has not finished within the
+// grace period, or when the last run reported failure. The latency is the
+// last run's duration, so latency thresholds flag slow runs.
+func (c *pushChecker) Check(ctx context.Context, monitor *model.Monitor) (*checker.Result, error) {
+	last, err := c.store.GetLastPushPing(monitor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loading last ping: %w", err)
+	}
+
+	now := time.Now()
+	period := time.Duration(monitor.IntervalMS) * time.Millisecond
+	grace := pushGrace(monitor)
+	result := &checker.Result{Status: model.StatusUp, Details: map[string]any{}}
+
+	if last == nil {
+		// The first ping is due one period after the monitor was created.
+		if since := now.Sub(time.UnixMilli(monitor.CreatedAt)); since > period+grace {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("no ping received in %s since the monitor was created", since.Round(time.Second))
+		} else {
+			result.Details["awaiting_first_ping"] = true
+		}
+		return result, nil
+	}
+
+	result.Details["last_ping_at"] = last.ReceivedAt
+	result.Details["last_ping_kind"] = last.Kind
+	result.Details["last_ping_node"] = last.NodeID
+
+	finished := last
+	if last.Kind == model.PushStart {
+		result.Details["run_started_at"] = last.ReceivedAt
+		if finished, err = c.store.GetLastPushPing(monitor.ID, model.PushSuccess, model.PushFail); err != nil {
+			return nil, fmt.Errorf("loading last finished run: %w", err)
+		}
+	}
+	if finished != nil {
+		result.LatencyMS = float64(finished.DurationMS)
+		if finished.DurationMS > 0 {
+			result.Details["run_duration_ms"] = finished.DurationMS
+		}
+		if finished.Message != "" {
+			result.Details["message"] = finished.Message
+		}
+	}
+
+	since := now.Sub(time.UnixMilli(last.ReceivedAt))
+	switch {
+	case last.Kind == model.PushStart && since > grace:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("run started %s ago has not finished", since.Round(time.Second))
+	case since > period+grace:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("no ping for %s, expected every %s", since.Round(time.Second), period)
+	case finished != nil && finished.Kind == model.PushFail:
+		result.Status = model.StatusDown
+		result.Error = "job reported failure"
+		if finished.Message != "" {
+			result.Error += ": " + finished.Message
+		}
+	}
+	return result, nil
+}
+
+// pushGrace returns the monitor's grace period.
+func pushGrace(monitor *model.Monitor) time.Duration {
+	if monitor.Options != nil && monitor.Options.Push != nil && monitor.Options.Push.GraceMS > 0 {
+		return time.Duration(monitor.Options.Push.GraceMS) * time.Millisecond
+	}
+	return defaultPushGrace
+}
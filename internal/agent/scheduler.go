@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"sync"
 	"time"
@@ -73,6 +74,9 @@ func (s *Scheduler) startMonitor(m model.Monitor) {
 	if interval < time.Second {
 		interval = time.Second
 	}
+	if m.CheckType == model.CheckPush && interval > pushCheckInterval {
+		interval = pushCheckInterval
+	}
 
 	ticker := time.NewTicker(interval)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -149,7 +153,7 @@ func (s *Scheduler) executeCheck(ctx context.Context, monitorID string) {
 	}
 
 	for i := 0; i < attempts; i++ {
-		lastResult, lastErr = c.Check(checkCtx, monitor)
+		lastResult, lastErr = checker.Run(checkCtx, c, monitor)
 		if lastErr != nil {
 			log.Printf("[scheduler] check error for %s (attempt %d): %v", monitorID, i+1, lastErr)
 			continue
@@ -168,6 +172,7 @@ func (s *Scheduler) executeCheck(ctx context.Context, monitorID string) {
 			lastResult.Error = lastErr.Error()
 		}
 	}
+	checker.ApplyLatencyThresholds(lastResult, monitor)
 
 	result := &model.CheckResult{
 		MonitorID:  monitorID,
@@ -178,6 +183,13 @@ func (s *Scheduler) executeCheck(ctx context.Context, monitorID string) {
 		Error:      lastResult.Error,
 		Timestamp:  time.Now().UnixMilli(),
 	}
+	if len(lastResult.Details) > 0 {
+		if details, err := json.Marshal(lastResult.Details); err == nil {
+			result.Details = details
+		} else {
+			log.Printf("[scheduler] failed to encode details for %s: %v", monitorID, err)
+		}
+	}
 
 	if err := s.store.InsertCheckResult(result); err != nil {
 		log.Printf("[scheduler] failed to store result for %s: %v", monitorID, err)
@@ -11,6 +11,8 @@ import (
 	"log"
 	"net/http"
 	"net/smtp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/model"
@@ -29,8 +31,8 @@ func NewDispatcher(st store.Store) *Dispatcher {
 
 // SendAlert sends an alert for a confirmed incident to all enabled channels.
 func (d *Dispatcher) SendAlert(incident *model.Incident, monitor *model.Monitor) {
-	log.Printf("[ALERT] INCIDENT CONFIRMED: monitor=%s (%s) target=%s incident=%s confirming_nodes=%v",
-		monitor.Name, monitor.CheckType, monitor.Target, incident.ID, incident.ConfirmingNodes)
+	log.Printf("[ALERT] INCIDENT CONFIRMED: monitor=%s (%s) target=%s incident=%s kind=%s severity=%s confirming_nodes=%v confirming_regions=%v",
+		monitor.Name, monitor.CheckType, monitor.Target, incident.ID, incident.Kind, incident.Severity, incident.ConfirmingNodes, incident.ConfirmingRegions)
 
 	d.dispatch(incident, monitor, "alert")
 }
@@ -57,10 +59,16 @@ func (d *Dispatcher) SendTest(channelID string) error {
 	incident := &model.Incident{
 		ID:              "test-" + now.Format("20060102-150405"),
 		MonitorID:       "test-monitor",
+		Kind:            model.IncidentKindDown,
+		Severity:        model.SeverityCritical,
 		Status:          model.IncidentConfirmed,
 		StartedAt:       now.Add(-2 * time.Minute).UnixMilli(),
 		ConfirmedAt:     now.UnixMilli(),
 		ConfirmingNodes: []string{"test-node-1", "test-node-2"},
+		ConfirmingRegions: map[string][]string{
+			"us-east": {"test-node-1"},
+			"eu-west": {"test-node-2"},
+		},
 	}
 	monitor := &model.Monitor{
 		ID:        "test-monitor",
@@ -200,10 +208,16 @@ func (d *Dispatcher) sendEmail(ch *model.AlertChannel, incident *model.Incident,
 	}
 
 	status := "DOWN"
+	if incident.Kind == model.IncidentKindDegraded {
+		status = "DEGRADED"
+	}
 	if eventType == "recovery" {
 		status = "RECOVERED"
 	}
 	label := "ALERT"
+	if incident.Severity == model.SeverityWarning {
+		label = "WARNING"
+	}
 	if eventType == "recovery" {
 		label = "RECOVERY"
 	}
@@ -211,10 +225,13 @@ func (d *Dispatcher) sendEmail(ch *model.AlertChannel, incident *model.Incident,
 	subject := fmt.Sprintf("[PingMesh] %s: %s (%s) is %s", label, monitor.Name, monitor.Target, status)
 
 	startedAt := time.UnixMilli(incident.StartedAt).Format(time.RFC3339)
-	body := fmt.Sprintf("PingMesh Alert\n\nEvent: %s\nMonitor: %s\nType: %s\nTarget: %s\nGroup: %s\n\nIncident ID: %s\nStarted At: %s\nConfirming Nodes: %d\n",
+	body := fmt.Sprintf("PingMesh Alert\n\nEvent: %s\nMonitor: %s\nType: %s\nTarget: %s\nGroup: %s\n\nIncident ID: %s\nKind: %s\nSeverity: %s\nStarted At: %s\nConfirming Nodes: %d\n",
 		eventType, monitor.Name, monitor.CheckType, monitor.Target, monitor.GroupName,
-		incident.ID, startedAt, len(incident.ConfirmingNodes))
+		incident.ID, incident.Kind, incident.Severity, startedAt, len(incident.ConfirmingNodes))
 
+	if len(incident.ConfirmingRegions) > 0 {
+		body += fmt.Sprintf("Confirming Regions: %s\n", formatRegions(incident.ConfirmingRegions))
+	}
 	if incident.ConfirmedAt > 0 {
 		body += fmt.Sprintf("Confirmed At: %s\n", time.UnixMilli(incident.ConfirmedAt).Format(time.RFC3339))
 	}
@@ -276,14 +293,20 @@ func sendMailTLS(addr string, auth smtp.Auth, from, to string, msg []byte, host
 
 func buildIncidentDetail(inc *model.Incident) model.IncidentDetail {
 	d := model.IncidentDetail{
-		ID:              inc.ID,
-		Status:          string(inc.Status),
-		StartedAt:       time.UnixMilli(inc.StartedAt).Format(time.RFC3339),
-		ConfirmingNodes: inc.ConfirmingNodes,
+		ID:                inc.ID,
+		Kind:              inc.Kind,
+		Severity:          inc.Severity,
+		Status:            string(inc.Status),
+		StartedAt:         time.UnixMilli(inc.StartedAt).Format(time.RFC3339),
+		ConfirmingNodes:   inc.ConfirmingNodes,
+		ConfirmingRegions: inc.ConfirmingRegions,
 	}
 	if d.ConfirmingNodes == nil {
 		d.ConfirmingNodes = []string{}
 	}
+	if d.ConfirmingRegions == nil {
+		d.ConfirmingRegions = map[string][]string{}
+	}
 	if inc.ConfirmedAt > 0 {
 		d.ConfirmedAt = time.UnixMilli(inc.ConfirmedAt).Format(time.RFC3339)
 	}
@@ -303,3 +326,18 @@ func buildMonitorSummary(m *model.Monitor) model.MonitorSummary {
 		Group:     m.GroupName,
 	}
 }
+
+// formatRegions lists regions with their node counts, e.g.
+// "eu-west (1), us-east (2)".
+func formatRegions(regions map[string][]string) string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%d)", name, len(regions[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -18,6 +18,7 @@ func (s *Server) registerCLIRoutes(mux *http.ServeMux) {
 	// Node endpoints
 	mux.HandleFunc("GET /api/v1/nodes", s.handleListNodes)
 	mux.HandleFunc("GET /api/v1/nodes/{id}", s.handleGetNode)
+	mux.HandleFunc("PUT /api/v1/nodes/{id}", s.handleUpdateNode)
 	mux.HandleFunc("DELETE /api/v1/nodes/{id}", s.handleDeleteNode)
 
 	// Monitor endpoints
@@ -26,10 +27,12 @@ func (s *Server) registerCLIRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/monitors/{id}", s.handleGetMonitor)
 	mux.HandleFunc("PUT /api/v1/monitors/{id}", s.handleUpdateMonitor)
 	mux.HandleFunc("DELETE /api/v1/monitors/{id}", s.handleDeleteMonitor)
+	mux.HandleFunc("POST /api/v1/monitors/{id}/accept-baseline", s.handleAcceptBaseline)
 
 	// Status & incidents
 	mux.HandleFunc("GET /api/v1/status", s.handleStatus)
 	mux.HandleFunc("GET /api/v1/incidents", s.handleListIncidents)
+	mux.HandleFunc("GET /api/v1/incidents/{id}/paths", s.handleListIncidentPaths)
 
 	// History
 	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
@@ -51,6 +54,10 @@ func (s *Server) registerCLIRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /api/v1/alerts/channels/{id}", s.handleDeleteAlertChannel)
 	mux.HandleFunc("POST /api/v1/alerts/channels/{id}/test", s.handleTestAlertChannel)
 	mux.HandleFunc("GET /api/v1/alerts/history", s.handleAlertHistory)
+
+	// Push monitor pings from local jobs
+	mux.HandleFunc("/api/v1/push/{token}", s.handlePush)
+	mux.HandleFunc("/api/v1/push/{token}/{kind}", s.handlePush)
 }
 
 func (s *Server) handleListNodes(w http.ResponseWriter, r *http.Request) {
@@ -79,6 +86,55 @@ func (s *Server) handleGetNode(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, node)
 }
 
+func (s *Server) handleUpdateNode(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	node, err := s.store.GetNode(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if node == nil {
+		writeError(w, http.StatusNotFound, "node not found")
+		return
+	}
+
+	var updates model.NodeUpdate
+	if err := readJSON(r, &updates); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if updates.Weight < 0 || updates.Weight > 100 {
+		writeError(w, http.StatusBadRequest, "weight must be between 0 and 100")
+		return
+	}
+
+	if updates.Location != "" {
+		node.Location = updates.Location
+	}
+	if updates.Weight != 0 {
+		node.Weight = updates.Weight
+	}
+
+	if err := s.store.UpdateNode(node); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if updates.ResetTrust {
+		if err := s.store.ResetNodeTrust(id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	node, err = s.store.GetNode(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, node)
+}
+
 func (s *Server) handleDeleteNode(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if err := s.store.DeleteNode(id); err != nil {
@@ -98,6 +154,9 @@ func (s *Server) handleListMonitors(w http.ResponseWriter, r *http.Request) {
 	if monitors == nil {
 		monitors = []model.Monitor{}
 	}
+	for i := range monitors {
+		monitors[i] = model.RedactSecrets(monitors[i])
+	}
 	writeJSON(w, http.StatusOK, monitors)
 }
 
@@ -108,6 +167,50 @@ func (s *Server) handleCreateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateMonitor(&m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.validateRegions(&m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.validatePushToken(&m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if m.CheckType == model.CheckPush {
+		if err := setPushToken(&m, nil); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// A missed deadline is already certain, so one result is enough.
+		if m.FailureThreshold == 0 {
+			m.FailureThreshold = 1
+		}
+		if m.RecoveryThreshold == 0 {
+			m.RecoveryThreshold = 1
+		}
+	}
+
+	// Registrations change slowly and RDAP servers rate limit, so domain
+	// checks run daily and act on a single result.
+	if m.CheckType == model.CheckDomain {
+		if m.IntervalMS == 0 {
+			m.IntervalMS = 24 * 60 * 60 * 1000
+		}
+		if m.TimeoutMS == 0 {
+			m.TimeoutMS = 15000
+		}
+		if m.FailureThreshold == 0 {
+			m.FailureThreshold = 1
+		}
+		if m.RecoveryThreshold == 0 {
+			m.RecoveryThreshold = 1
+		}
+	}
+
 	now := time.Now().UnixMilli()
 	m.ID = uuid.New().String()
 	m.CreatedAt = now
@@ -142,7 +245,12 @@ func (s *Server) handleCreateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, m)
+	// The push URL is shown once, to whoever created the monitor.
+	created := model.RedactSecrets(m)
+	if m.CheckType == model.CheckPush {
+		created.Options.Push.Token = m.Options.Push.Token
+	}
+	writeJSON(w, http.StatusCreated, created)
 }
 
 func (s *Server) handleGetMonitor(w http.ResponseWriter, r *http.Request) {
@@ -156,7 +264,7 @@ func (s *Server) handleGetMonitor(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "monitor not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, monitor)
+	writeJSON(w, http.StatusOK, model.RedactSecrets(*monitor))
 }
 
 func (s *Server) handleUpdateMonitor(w http.ResponseWriter, r *http.Request) {
@@ -197,6 +305,29 @@ func (s *Server) handleUpdateMonitor(w http.ResponseWriter, r *http.Request) {
 	if updates.GroupName != "" {
 		existing.GroupName = updates.GroupName
 	}
+	previousOptions := existing.Options
+	if updates.Options != nil {
+		model.KeepRedactedSecrets(updates.Options, existing.Options)
+		existing.Options = updates.Options
+	}
+	if existing.CheckType == model.CheckPush {
+		if err := setPushToken(existing, previousOptions); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := validateMonitor(existing); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.validateRegions(existing); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.validatePushToken(existing); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	existing.UpdatedAt = time.Now().UnixMilli()
 
 	if err := s.store.UpdateMonitor(existing); err != nil {
@@ -204,7 +335,34 @@ func (s *Server) handleUpdateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, existing)
+	writeJSON(w, http.StatusOK, model.RedactSecrets(*existing))
+}
+
+// handleAcceptBaseline accepts what a monitor's target serves now, such as
+// changed page content or DNS answers, as its new baseline. Each node
+// relearns its baseline on its next check after the change syncs.
+func (s *Server) handleAcceptBaseline(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	monitor, err := s.store.GetMonitor(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if monitor == nil {
+		writeError(w, http.StatusNotFound, "monitor not found")
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	monitor.BaselineAcceptedAt = now
+	monitor.UpdatedAt = now
+	if err := s.store.UpdateMonitor(monitor); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.RedactSecrets(*monitor))
 }
 
 func (s *Server) handleDeleteMonitor(w http.ResponseWriter, r *http.Request) {
@@ -251,6 +409,28 @@ func (s *Server) handleListIncidents(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, incidents)
 }
 
+func (s *Server) handleListIncidentPaths(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	incident, err := s.store.GetIncident(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if incident == nil {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	captures, err := s.store.ListPathCaptures(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if captures == nil {
+		captures = []model.PathCapture{}
+	}
+	writeJSON(w, http.StatusOK, captures)
+}
+
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	monitorID := r.URL.Query().Get("monitor")
 	nodeID := r.URL.Query().Get("node")
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"log"
 	"net"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pingmesh/pingmesh/internal/checker"
 	"github.com/pingmesh/pingmesh/internal/cluster"
 	"github.com/pingmesh/pingmesh/internal/model"
 )
@@ -19,6 +21,12 @@ func (s *Server) registerPeerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/peer/config-sync", s.handlePeerConfigSyncPull)
 	mux.HandleFunc("POST /api/v1/peer/join", s.handlePeerJoin)
 	mux.HandleFunc("POST /api/v1/peer/result", s.handlePeerResult)
+	mux.HandleFunc("POST /api/v1/peer/traceroute", s.handlePeerTraceroute)
+	mux.HandleFunc("POST /api/v1/peer/push-ping", s.handlePeerPushPing)
+
+	// Push monitor pings from jobs that can reach this node
+	mux.HandleFunc("/api/v1/push/{token}", s.handlePush)
+	mux.HandleFunc("/api/v1/push/{token}/{kind}", s.handlePush)
 }
 
 // handlePeerCheck handles a request from a peer to execute a check.
@@ -26,6 +34,60 @@ func (s *Server) handlePeerCheck(w http.ResponseWriter, r *http.Request) {
 	writeError(w, http.StatusNotImplemented, "peer check not yet implemented")
 }
 
+// handlePeerTraceroute traces the path from this node to a monitor's target
+// so the coordinator can attach it to an incident.
+func (s *Server) handlePeerTraceroute(w http.ResponseWriter, r *http.Request) {
+	var req model.PeerTraceRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	monitor, err := s.store.GetMonitor(req.MonitorID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "loading monitor: "+err.Error())
+		return
+	}
+	if monitor == nil {
+		writeError(w, http.StatusNotFound, "monitor not found")
+		return
+	}
+
+	if !checker.RawSocketsAvailable() {
+		writeError(w, http.StatusServiceUnavailable, "raw sockets unavailable; run the agent as root or with CAP_NET_RAW")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), checker.TraceDuration(monitor))
+	defer cancel()
+
+	log.Printf("[peer] tracing %s for incident %s (requested by %s)", monitor.Target, req.IncidentID, req.RequestedBy)
+	writeJSON(w, http.StatusOK, checker.TraceMonitor(ctx, monitor))
+}
+
+// handlePeerPushPing stores a push monitor ping relayed by the node that
+// received it.
+func (s *Server) handlePeerPushPing(w http.ResponseWriter, r *http.Request) {
+	if s.config.Role != model.RoleCoordinator {
+		writeError(w, http.StatusForbidden, "only the coordinator accepts push pings")
+		return
+	}
+
+	var ping model.PushPing
+	if err := readJSON(r, &ping); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := s.recordPushPing(&ping); err != nil {
+		log.Printf("[peer] push-ping: error storing ping for monitor %s: %v", ping.MonitorID, err)
+		writeError(w, http.StatusInternalServerError, "storing ping failed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
 // handlePeerHeartbeat handles a heartbeat from a peer node.
 func (s *Server) handlePeerHeartbeat(w http.ResponseWriter, r *http.Request) {
 	var hb model.Heartbeat
@@ -59,6 +121,7 @@ func (s *Server) handlePeerConfigSync(w http.ResponseWriter, r *http.Request) {
 			log.Printf("[peer] config-sync: error checking monitor %s: %v", m.ID, err)
 			continue
 		}
+		model.KeepRedactedSecrets(m.Options, nil) // inline secrets are withheld
 		if existing != nil {
 			if err := s.store.UpdateMonitor(m); err != nil {
 				log.Printf("[peer] config-sync: error updating monitor %s: %v", m.ID, err)
@@ -163,6 +226,7 @@ func (s *Server) handlePeerJoin(w http.ResponseWriter, r *http.Request) {
 		Status:    model.NodeOnline,
 		LastSeen:  now,
 		CreatedAt: now,
+		Weight:    1,
 	}
 	if err := s.store.CreateNode(node); err != nil {
 		log.Printf("[peer] join: error creating node record: %v", err)
@@ -194,6 +258,9 @@ func (s *Server) handlePeerConfigSyncPull(w http.ResponseWriter, r *http.Request
 		writeError(w, http.StatusInternalServerError, "loading monitors: "+err.Error())
 		return
 	}
+	for i := range monitors {
+		monitors[i] = model.WithholdSecrets(monitors[i])
+	}
 	nodes, err := s.store.ListNodes()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "loading nodes: "+err.Error())
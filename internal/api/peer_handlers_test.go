@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/config"
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+func TestPeerConfigSyncWithholdsSecrets(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	st.CreateMonitor(&model.Monitor{ID: "m1", Name: "api", CheckType: model.CheckHTTP, Target: "https://api.example.com",
+		Options: &model.MonitorOptions{HTTP: &model.HTTPOptions{BearerToken: "http-token"}}})
+	st.CreateMonitor(&model.Monitor{ID: "m2", Name: "db", CheckType: model.CheckPostgres, Target: "db.internal",
+		Options: &model.MonitorOptions{Database: &model.DatabaseOptions{Credentials: model.Credentials{
+			Username: "monitor", Password: "db-pass"}}}})
+	coordinator := &Server{config: &config.Config{NodeID: "n1", Role: model.RoleCoordinator}, store: st}
+
+	w := httptest.NewRecorder()
+	coordinator.handlePeerConfigSyncPull(w, httptest.NewRequest(http.MethodGet, "/api/v1/peer/config-sync", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("pull = %d %s", w.Code, w.Body)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("http-token")) || bytes.Contains(w.Body.Bytes(), []byte("db-pass")) {
+		t.Fatalf("config sync leaked inline secrets: %s", w.Body)
+	}
+	if m, _ := st.GetMonitor("m1"); m.Options.HTTP.BearerToken != "http-token" {
+		t.Errorf("coordinator bearer token = %q after a pull, want it kept", m.Options.HTTP.BearerToken)
+	}
+
+	// A node applying the sync stores the monitors without the placeholders.
+	nodeStore, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "node.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nodeStore.Close()
+	node := &Server{config: &config.Config{NodeID: "n2", Role: model.RoleNode}, store: nodeStore}
+	var sync model.ConfigSync
+	json.Unmarshal(w.Body.Bytes(), &sync)
+	body, _ := json.Marshal(sync)
+	node.handlePeerConfigSync(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/peer/config-sync", bytes.NewReader(body)))
+
+	m, _ := nodeStore.GetMonitor("m2")
+	if m == nil {
+		t.Fatal("synced monitor missing on the node")
+	}
+	if m.Options.Database.Password != "" || m.Options.Database.Username != "monitor" {
+		t.Errorf("node credentials = %+v, want the username without a password", m.Options.Database.Credentials)
+	}
+}
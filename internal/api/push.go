@@ -0,0 +1,144 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// maxPushMessage bounds the message a job can attach to a ping.
+const maxPushMessage = 1024
+
+// handlePush records a ping from a job reporting to a push monitor. The
+// optional last path segment gives the kind: start, success (the default)
+// or fail. A run's duration comes from the duration_ms query parameter, or
+// else from the preceding start ping. The msg parameter or request body is
+// kept as the ping's message.
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	switch kind {
+	case "":
+		kind = model.PushSuccess
+	case model.PushStart, model.PushSuccess, model.PushFail:
+	default:
+		writeError(w, http.StatusNotFound, "unknown ping kind: must be start, success or fail")
+		return
+	}
+
+	monitor, err := s.findPushMonitor(r.PathValue("token"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if monitor == nil {
+		writeError(w, http.StatusNotFound, "unknown push token")
+		return
+	}
+
+	ping := &model.PushPing{
+		MonitorID:  monitor.ID,
+		NodeID:     s.config.NodeID,
+		Kind:       kind,
+		ReceivedAt: time.Now().UnixMilli(),
+	}
+	if v := r.URL.Query().Get("duration_ms"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 {
+			writeError(w, http.StatusBadRequest, "duration_ms: must be a non-negative integer")
+			return
+		}
+		ping.DurationMS = ms
+	}
+	ping.Message = r.URL.Query().Get("msg")
+	if ping.Message == "" && r.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, maxPushMessage))
+		ping.Message = string(body)
+	}
+	ping.Message = strings.TrimSpace(ping.Message)
+	if len(ping.Message) > maxPushMessage {
+		ping.Message = ping.Message[:maxPushMessage]
+	}
+
+	if s.config.Role == model.RoleCoordinator || s.config.Coordinator == nil {
+		err = s.recordPushPing(ping)
+	} else {
+		err = s.peerClient.ForwardPushPing(s.config.Coordinator.Address, ping)
+	}
+	if err != nil {
+		log.Printf("[api] push: error recording %s ping for monitor %s: %v", kind, monitor.ID, err)
+		writeError(w, http.StatusServiceUnavailable, "recording ping failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// findPushMonitor returns the push monitor whose token matches, or nil.
+func (s *Server) findPushMonitor(token string) (*model.Monitor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	monitors, err := s.store.ListMonitors("")
+	if err != nil {
+		return nil, err
+	}
+	for i := range monitors {
+		m := &monitors[i]
+		if m.CheckType != model.CheckPush || m.Options == nil || m.Options.Push == nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(m.Options.Push.Token), []byte(token)) == 1 {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// recordPushPing stores a ping on the coordinator. A finishing ping without
+// its own duration is timed from the run's start ping.
+func (s *Server) recordPushPing(ping *model.PushPing) error {
+	if ping.Kind != model.PushStart && ping.DurationMS == 0 {
+		last, err := s.store.GetLastPushPing(ping.MonitorID)
+		if err != nil {
+			return err
+		}
+		if last != nil && last.Kind == model.PushStart && ping.ReceivedAt >= last.ReceivedAt {
+			ping.DurationMS = ping.ReceivedAt - last.ReceivedAt
+		}
+	}
+	return s.store.InsertPushPing(ping)
+}
+
+// setPushToken gives a push monitor its URL token, keeping the one from
+// previous (the options before an update) or generating a new one.
+func setPushToken(m *model.Monitor, previous *model.MonitorOptions) error {
+	if m.Options == nil {
+		m.Options = &model.MonitorOptions{}
+	}
+	if m.Options.Push == nil {
+		m.Options.Push = &model.PushOptions{}
+	}
+	if m.Options.Push.Token != "" {
+		return nil
+	}
+	if previous != nil && previous.Push != nil && previous.Push.Token != "" {
+		m.Options.Push.Token = previous.Push.Token
+		return nil
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generating push token: %w", err)
+	}
+	m.Options.Push.Token = hex.EncodeToString(b)
+	return nil
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/config"
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+const testPushToken = "0483cab00decc4d21daf30e991ea7524"
+
+func TestHandlePush(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	st.CreateNode(&model.Node{ID: "n1"})
+	st.CreateMonitor(&model.Monitor{ID: "m1", Name: "backup", CheckType: model.CheckPush, IntervalMS: 86400000,
+		Options: &model.MonitorOptions{Push: &model.PushOptions{Token: testPushToken}}})
+	s := &Server{config: &config.Config{NodeID: "n1", Role: model.RoleCoordinator}, store: st}
+
+	push := func(token, kind, query, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/push/"+token+"?"+query, strings.NewReader(body))
+		req.SetPathValue("token", token)
+		req.SetPathValue("kind", kind)
+		w := httptest.NewRecorder()
+		s.handlePush(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		kind     string
+		query    string
+		body     string
+		wantCode int
+		want     *model.PushPing
+	}{
+		{"unknown token", "ffffffffffffffffffffffffffffffff", "", "", "", http.StatusNotFound, nil},
+		{"empty token", "", "", "", "", http.StatusNotFound, nil},
+		{"unknown kind", testPushToken, "done", "", "", http.StatusNotFound, nil},
+		{"bad duration", testPushToken, "", "duration_ms=-5", "", http.StatusBadRequest, nil},
+		{"success", testPushToken, "", "duration_ms=1500&msg=ok", "", http.StatusOK,
+			&model.PushPing{Kind: model.PushSuccess, DurationMS: 1500, Message: "ok"}},
+		{"start", testPushToken, model.PushStart, "", "", http.StatusOK,
+			&model.PushPing{Kind: model.PushStart}},
+		{"fail with body", testPushToken, model.PushFail, "duration_ms=20", "  disk full\n", http.StatusOK,
+			&model.PushPing{Kind: model.PushFail, DurationMS: 20, Message: "disk full"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := push(tt.token, tt.kind, tt.query, tt.body); code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if tt.want == nil {
+				return
+			}
+			last, _ := st.GetLastPushPing("m1")
+			if last == nil || last.Kind != tt.want.Kind || last.DurationMS != tt.want.DurationMS ||
+				last.Message != tt.want.Message || last.NodeID != "n1" {
+				t.Errorf("last ping = %+v, want %+v", last, tt.want)
+			}
+		})
+	}
+
+	// A finishing ping without a duration is timed from the start ping.
+	push(testPushToken, model.PushStart, "", "")
+	time.Sleep(20 * time.Millisecond)
+	if code := push(testPushToken, model.PushSuccess, "", ""); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if last, _ := st.GetLastPushPing("m1"); last.DurationMS < 20 {
+		t.Errorf("duration from start ping = %d, want the time since it", last.DurationMS)
+	}
+}
+
+func TestSetPushToken(t *testing.T) {
+	m := &model.Monitor{CheckType: model.CheckPush}
+	if err := setPushToken(m, nil); err != nil {
+		t.Fatal(err)
+	}
+	token := m.Options.Push.Token
+	if len(token) != 32 {
+		t.Fatalf("generated token %q, want 32 hex characters", token)
+	}
+
+	other := &model.Monitor{CheckType: model.CheckPush}
+	setPushToken(other, nil)
+	if other.Options.Push.Token == token {
+		t.Error("two monitors were given the same token")
+	}
+
+	// An update without a token keeps the previous one.
+	updated := &model.Monitor{CheckType: model.CheckPush, Options: &model.MonitorOptions{Push: &model.PushOptions{GraceMS: 5000}}}
+	setPushToken(updated, m.Options)
+	if updated.Options.Push.Token != token || updated.Options.Push.GraceMS != 5000 {
+		t.Errorf("updated push options = %+v, want token %s kept", updated.Options.Push, token)
+	}
+}
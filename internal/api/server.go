@@ -52,6 +52,7 @@ type Server struct {
 	config     *config.Config
 	store      store.Store
 	clusterMgr *cluster.Manager
+	peerClient *cluster.PeerClient
 	logBuf          *logbuf.Buffer
 	agentInfo       AgentInfo
 	alertDispatcher AlertDispatcher
@@ -65,6 +66,7 @@ func NewServer(cfg *config.Config, st store.Store, opts ...ServerOption) *Server
 		config:     cfg,
 		store:      st,
 		clusterMgr: cluster.NewManager(cfg, st),
+		peerClient: cluster.NewPeerClient(),
 	}
 
 	for _, opt := range opts {
@@ -0,0 +1,339 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/checker"
+	"github.com/pingmesh/pingmesh/internal/consensus"
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// validateMonitor rejects monitor settings that could never produce a
+// successful check, so mistakes surface at create/update time.
+func validateMonitor(m *model.Monitor) error {
+	timeoutMS := m.TimeoutMS
+	if timeoutMS == 0 {
+		timeoutMS = 5000 // the default set on create
+	}
+
+	switch m.CheckType {
+	case model.CheckHTTP, model.CheckHTTPKeyword:
+		if _, err := checker.TargetURL(m.Target, "http", m.Port); err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+	case model.CheckHTTPS:
+		if _, err := checker.TargetURL(m.Target, "https", m.Port); err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+	case model.CheckWebSocket, model.CheckSSE:
+		if _, err := checker.StreamURL(m.Target, m.Port); err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+	case model.CheckHTTPFlow:
+		if m.Options == nil || m.Options.HTTPFlow == nil {
+			return fmt.Errorf("options.http_flow: http_flow checks need steps")
+		}
+		if m.Target != "" {
+			if _, err := checker.TargetURL(m.Target, "https", m.Port); err != nil {
+				return fmt.Errorf("target: %w", err)
+			}
+		}
+	case model.CheckExec:
+		if m.Options == nil || m.Options.Exec == nil {
+			return fmt.Errorf("options.exec: exec checks need a command")
+		}
+	case model.CheckDomain:
+		var opts *model.DomainOptions
+		if m.Options != nil {
+			opts = m.Options.Domain
+		}
+		if err := checker.ValidateDomainOptions(m.Target, opts); err != nil {
+			return err
+		}
+	case model.CheckDNS:
+		if err := checker.ValidateDNSRecordType(m.DNSRecordType); err != nil {
+			return fmt.Errorf("dns_record_type: %w", err)
+		}
+	case model.CheckGRPC:
+		if m.Port <= 0 {
+			return fmt.Errorf("port: grpc checks need a port")
+		}
+	case model.CheckUDP:
+		if m.Port <= 0 {
+			return fmt.Errorf("port: udp checks need a port")
+		}
+		if m.Options == nil || m.Options.UDP == nil || m.Options.UDP.Send == "" {
+			return fmt.Errorf("options.udp: udp checks need a payload to send")
+		}
+	case model.CheckRADIUS:
+		if m.Options == nil || m.Options.UDP == nil {
+			return fmt.Errorf("options.udp: radius checks need the shared secret")
+		}
+	case model.CheckTraceroute:
+		opts := &model.TracerouteOptions{}
+		if m.Options != nil && m.Options.Traceroute != nil {
+			opts = m.Options.Traceroute
+		}
+		if err := checker.ValidateTracerouteOptions(opts, timeoutMS); err != nil {
+			return fmt.Errorf("options.traceroute: %w", err)
+		}
+	}
+
+	switch m.QuorumType {
+	case "", "majority":
+	case "n_of_m", "regions":
+		if m.QuorumN < 1 {
+			return fmt.Errorf("quorum_n: must be at least 1 for %s", m.QuorumType)
+		}
+	default:
+		return fmt.Errorf("quorum_type: must be majority, n_of_m or regions")
+	}
+	// Only the coordinator evaluates push monitors, so they have one region.
+	if m.CheckType == model.CheckPush && usesRegions(m) {
+		return fmt.Errorf("quorum_type: regions does not apply to push monitors, which only the coordinator evaluates")
+	}
+
+	if m.Options == nil {
+		return nil
+	}
+	if err := checker.ValidateAddressFamily(m.CheckType, m.Options.AddressFamily); err != nil {
+		return fmt.Errorf("options.address_family: %w", err)
+	}
+	if err := checker.ValidateAssertions(m.Options.Assertions); err != nil {
+		return fmt.Errorf("options.assertions: %w", err)
+	}
+	if err := validateHTTPOptions(m.Options.HTTP); err != nil {
+		return err
+	}
+	if err := validateTLSOptions(m.Options.TLS); err != nil {
+		return err
+	}
+	if m.Options.HTTPFlow != nil {
+		if err := checker.ValidateHTTPFlowOptions(m.Target, m.Options.HTTPFlow); err != nil {
+			return fmt.Errorf("options.http_flow: %w", err)
+		}
+	}
+	if m.Options.Exec != nil {
+		if err := checker.ValidateExecOptions(m.Options.Exec); err != nil {
+			return fmt.Errorf("options.exec: %w", err)
+		}
+	}
+	if m.Options.DNS != nil {
+		if err := checker.ValidateDNSOptions(m.Options.DNS); err != nil {
+			return fmt.Errorf("options.dns: %w", err)
+		}
+		if m.Options.DNS.Match == model.DNSMatchRegex && len(m.Options.DNS.Expected) == 0 {
+			if _, err := regexp.Compile(m.DNSExpected); err != nil {
+				return fmt.Errorf("dns_expected: invalid regex: %w", err)
+			}
+		}
+	}
+	if m.Options.TCP != nil {
+		if err := checker.ValidateTCPOptions(m.Options.TCP); err != nil {
+			return fmt.Errorf("options.tcp: %w", err)
+		}
+	}
+	if m.Options.ICMP != nil {
+		if err := checker.ValidateICMPOptions(m.Options.ICMP, timeoutMS); err != nil {
+			return fmt.Errorf("options.icmp: %w", err)
+		}
+	}
+	// Traceroute checks were validated against their timeout above; path
+	// capture runs under its own.
+	if m.Options.Traceroute != nil && m.CheckType != model.CheckTraceroute {
+		if err := checker.ValidateTracerouteOptions(m.Options.Traceroute, 0); err != nil {
+			return fmt.Errorf("options.traceroute: %w", err)
+		}
+	}
+	if m.Options.GRPC != nil {
+		if err := checker.ValidateGRPCOptions(m.Options.GRPC); err != nil {
+			return fmt.Errorf("options.grpc: %w", err)
+		}
+	}
+	if m.Options.Database != nil {
+		if err := checker.ValidateDatabaseOptions(m.CheckType, m.Options.Database); err != nil {
+			return fmt.Errorf("options.database: %w", err)
+		}
+	}
+	if m.Options.Mail != nil {
+		if err := checker.ValidateMailOptions(m.CheckType, m.Options.Mail); err != nil {
+			return fmt.Errorf("options.mail: %w", err)
+		}
+	}
+	if m.Options.UDP != nil {
+		if err := checker.ValidateUDPOptions(m.CheckType, m.Options.UDP); err != nil {
+			return fmt.Errorf("options.udp: %w", err)
+		}
+	}
+	if m.Options.Stream != nil {
+		if err := checker.ValidateStreamOptions(m.CheckType, m.Options.Stream); err != nil {
+			return fmt.Errorf("options.stream: %w", err)
+		}
+	}
+	if m.Options.Content != nil {
+		if err := checker.ValidateContentOptions(m.CheckType, m.Options.Content); err != nil {
+			return fmt.Errorf("options.content: %w", err)
+		}
+	}
+	if m.Options.Assets != nil {
+		if err := checker.ValidateAssetOptions(m.CheckType, m.Options.Assets); err != nil {
+			return fmt.Errorf("options.assets: %w", err)
+		}
+	}
+	if err := validatePushOptions(m.Options.Push); err != nil {
+		return err
+	}
+	if err := validateLatencyOptions(m.Options.Latency); err != nil {
+		return err
+	}
+	if err := validateDegradedOptions(m.Options.Degraded); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateHTTPOptions(h *model.HTTPOptions) error {
+	if h == nil {
+		return nil
+	}
+	if h.Method != "" {
+		switch strings.ToUpper(h.Method) {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		default:
+			return fmt.Errorf("options.http.method: unsupported method %q", h.Method)
+		}
+	}
+	if h.BearerToken != "" && h.BasicAuthUser != "" {
+		return fmt.Errorf("options.http: bearer_token and basic_auth_user are mutually exclusive")
+	}
+	if h.AcceptedStatus != "" {
+		if _, err := checker.ParseStatusRanges(h.AcceptedStatus); err != nil {
+			return fmt.Errorf("options.http.accepted_status: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateTLSOptions(t *model.TLSOptions) error {
+	if t == nil {
+		return nil
+	}
+	switch t.StartTLS {
+	case "", "smtp", "imap", "pop3":
+	default:
+		return fmt.Errorf("options.tls.starttls: must be smtp, imap or pop3")
+	}
+	if t.ExpiryWarnDays < 0 {
+		return fmt.Errorf("options.tls.expiry_warn_days: must not be negative")
+	}
+	return nil
+}
+
+// pushTokenPattern keeps user-chosen push tokens URL-safe and hard to guess.
+var pushTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+func validatePushOptions(p *model.PushOptions) error {
+	if p == nil {
+		return nil
+	}
+	if p.Token != "" && !pushTokenPattern.MatchString(p.Token) {
+		return fmt.Errorf("options.push.token: must be 16-128 letters, digits, '-' or '_'")
+	}
+	if p.GraceMS < 0 {
+		return fmt.Errorf("options.push.grace_ms: must not be negative")
+	}
+	return nil
+}
+
+func validateLatencyOptions(l *model.LatencyOptions) error {
+	if l == nil {
+		return nil
+	}
+	if l.WarnMS < 0 || l.CriticalMS < 0 {
+		return fmt.Errorf("options.latency: thresholds must not be negative")
+	}
+	if l.WarnMS > 0 && l.CriticalMS > 0 && l.WarnMS >= l.CriticalMS {
+		return fmt.Errorf("options.latency: warn_ms must be below critical_ms")
+	}
+	for phase, t := range l.Phases {
+		if !slices.Contains(model.HTTPPhases, phase) {
+			return fmt.Errorf("options.latency.phases: unknown phase %q (want %s)", phase, strings.Join(model.HTTPPhases, ", "))
+		}
+		if t.WarnMS < 0 || t.CriticalMS < 0 {
+			return fmt.Errorf("options.latency.phases.%s: thresholds must not be negative", phase)
+		}
+		if t.WarnMS > 0 && t.CriticalMS > 0 && t.WarnMS >= t.CriticalMS {
+			return fmt.Errorf("options.latency.phases.%s: warn_ms must be below critical_ms", phase)
+		}
+	}
+	return nil
+}
+
+// usesRegions reports whether the monitor's down or degraded incidents
+// need a regions quorum.
+func usesRegions(m *model.Monitor) bool {
+	if m.QuorumType == "regions" {
+		return true
+	}
+	return m.Options != nil && m.Options.Degraded != nil && m.Options.Degraded.QuorumType == "regions"
+}
+
+// validateRegions rejects a regions quorum while no node has a location,
+// as every node would vote from the same unlabelled region.
+func (s *Server) validateRegions(m *model.Monitor) error {
+	if !usesRegions(m) {
+		return nil
+	}
+	nodes, err := s.store.ListNodes()
+	if err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, n := range nodes {
+		if consensus.NodeRegion(n) != consensus.UnlabelledRegion {
+			return nil
+		}
+	}
+	return fmt.Errorf("quorum_type: regions needs node locations; set them with pingmesh node edit --location")
+}
+
+// validatePushToken rejects a push token another monitor already uses, as
+// its pings could only be recorded for one of them.
+func (s *Server) validatePushToken(m *model.Monitor) error {
+	if m.CheckType != model.CheckPush || m.Options == nil || m.Options.Push == nil || m.Options.Push.Token == "" {
+		return nil
+	}
+	other, err := s.findPushMonitor(m.Options.Push.Token)
+	if err != nil {
+		return fmt.Errorf("listing monitors: %w", err)
+	}
+	if other != nil && other.ID != m.ID {
+		return fmt.Errorf("options.push.token: already used by monitor %q", other.Name)
+	}
+	return nil
+}
+
+func validateDegradedOptions(d *model.DegradedOptions) error {
+	if d == nil {
+		return nil
+	}
+	switch d.QuorumType {
+	case "", "majority":
+	case "n_of_m", "regions":
+		if d.QuorumN < 1 {
+			return fmt.Errorf("options.degraded.quorum_n: must be at least 1 for %s", d.QuorumType)
+		}
+	default:
+		return fmt.Errorf("options.degraded.quorum_type: must be majority, n_of_m or regions")
+	}
+	switch d.Severity {
+	case "", model.SeverityWarning, model.SeverityCritical:
+	default:
+		return fmt.Errorf("options.degraded.severity: must be warning or critical")
+	}
+	return nil
+}
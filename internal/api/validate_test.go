@@ -0,0 +1,121 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+func TestValidateMonitorQuorum(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor model.Monitor
+		wantErr string
+	}{
+		{"majority", model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432}, ""},
+		{"n_of_m without n", model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432, QuorumType: "n_of_m"}, "quorum_n"},
+		{"unknown type", model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432, QuorumType: "all"}, "quorum_type"},
+		{"regions on push", model.Monitor{CheckType: model.CheckPush, QuorumType: "regions", QuorumN: 2}, "push monitors"},
+		{"degraded regions on push", model.Monitor{CheckType: model.CheckPush, Options: &model.MonitorOptions{
+			Degraded: &model.DegradedOptions{Incidents: true, QuorumType: "regions", QuorumN: 2},
+		}}, "push monitors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMonitor(&tt.monitor)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateMonitor() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateMonitor() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRegions(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	s := &Server{store: st}
+	st.CreateNode(&model.Node{ID: "a"})
+	st.CreateNode(&model.Node{ID: "b"})
+
+	regions := &model.Monitor{QuorumType: "regions", QuorumN: 2}
+	if err := s.validateRegions(regions); err == nil {
+		t.Error("regions quorum accepted while no node has a location")
+	}
+	if err := s.validateRegions(&model.Monitor{QuorumType: "majority"}); err != nil {
+		t.Errorf("majority quorum rejected: %v", err)
+	}
+
+	st.UpdateNode(&model.Node{ID: "a", Location: "eu-west"})
+	if err := s.validateRegions(regions); err != nil {
+		t.Errorf("regions quorum rejected with a labelled node: %v", err)
+	}
+}
+
+func TestValidatePushToken(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	s := &Server{store: st}
+	push := func(id, token string) *model.Monitor {
+		return &model.Monitor{ID: id, Name: id, CheckType: model.CheckPush,
+			Options: &model.MonitorOptions{Push: &model.PushOptions{Token: token}}}
+	}
+	st.CreateMonitor(push("backup", testPushToken))
+
+	tests := []struct {
+		name    string
+		monitor *model.Monitor
+		wantErr bool
+	}{
+		{"new token", push("", "ffffffffffffffffffffffffffffffff"), false},
+		{"generated token", push("", ""), false},
+		{"duplicate on create", push("", testPushToken), true},
+		{"duplicate on update", push("report", testPushToken), true},
+		{"unchanged on update", push("backup", testPushToken), false},
+	}
+	for _, tt := range tests {
+		err := s.validatePushToken(tt.monitor)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: validatePushToken() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateMonitorTraceroute(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor model.Monitor
+		wantErr bool
+	}{
+		{"defaults", model.Monitor{CheckType: model.CheckTraceroute, Target: "example.com"}, false},
+		{"defaults over a short timeout", model.Monitor{CheckType: model.CheckTraceroute, Target: "example.com", TimeoutMS: 2000}, true},
+		{"probes over the default timeout", model.Monitor{CheckType: model.CheckTraceroute, Target: "example.com",
+			Options: &model.MonitorOptions{Traceroute: &model.TracerouteOptions{Probes: 6}}}, true},
+		{"fits a longer timeout", model.Monitor{CheckType: model.CheckTraceroute, Target: "example.com", TimeoutMS: 10000,
+			Options: &model.MonitorOptions{Traceroute: &model.TracerouteOptions{Probes: 6}}}, false},
+		// Path capture runs under its own timeout.
+		{"path capture", model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432, TimeoutMS: 1000,
+			Options: &model.MonitorOptions{Traceroute: &model.TracerouteOptions{Probes: 6}}}, false},
+		{"path capture limits", model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432,
+			Options: &model.MonitorOptions{Traceroute: &model.TracerouteOptions{MaxHops: 100}}}, true},
+	}
+	for _, tt := range tests {
+		if err := validateMonitor(&tt.monitor); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateMonitor() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// maxActualLen caps how much of an observed value is echoed into result details.
+const maxActualLen = 200
+
+// assertionFailure describes a failed assertion in result details.
+type assertionFailure struct {
+	Source   string `json:"source"`
+	Property string `json:"property,omitempty"`
+	Operator string `json:"operator"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ValidateAssertions checks that every assertion is well formed, so that
+// typos surface when the monitor is saved rather than as failed checks.
+func ValidateAssertions(assertions []model.Assertion) error {
+	for i, a := range assertions {
+		if err := validateAssertion(a); err != nil {
+			return fmt.Errorf("assertion %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateAssertion(a model.Assertion) error {
+	switch a.Source {
+	case model.AssertSourceBody:
+	case model.AssertSourceHeader:
+		if a.Property == "" {
+			return fmt.Errorf("header assertions need a header name in property")
+		}
+	case model.AssertSourceJSON:
+		if _, err := parseJSONPath(a.Property); err != nil {
+			return err
+		}
+	case model.AssertSourceTiming:
+		if !slices.Contains(model.HTTPPhases, a.Property) {
+			return fmt.Errorf("timing assertions need a phase in property (%s)", strings.Join(model.HTTPPhases, ", "))
+		}
+	default:
+		return fmt.Errorf("unknown source %q", a.Source)
+	}
+
+	switch a.Operator {
+	case model.AssertEquals, model.AssertNotEquals, model.AssertContains, model.AssertNotContains:
+	case model.AssertMatches, model.AssertNotMatches:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	case model.AssertExists, model.AssertNotExists:
+		if a.Source == model.AssertSourceBody {
+			return fmt.Errorf("operator %q is not supported for body assertions", a.Operator)
+		}
+	case model.AssertLessThan, model.AssertGreaterThan:
+		if _, err := strconv.ParseFloat(a.Value, 64); err != nil {
+			return fmt.Errorf("operator %q needs a numeric value", a.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", a.Operator)
+	}
+	return nil
+}
+
+// evaluateAssertions runs all assertions against a response and its phase
+// timings and returns the ones that failed.
+func evaluateAssertions(assertions []model.Assertion, resp *http.Response, body []byte, timings map[string]float64) []assertionFailure {
+	var failures []assertionFailure
+
+	var doc any
+	var docErr error
+	docParsed := false
+
+	for _, a := range assertions {
+		var actual string
+		var present bool
+
+		switch a.Source {
+		case model.AssertSourceBody:
+			actual, present = string(body), true
+		case model.AssertSourceHeader:
+			values := resp.Header.Values(a.Property)
+			actual, present = strings.Join(values, ", "), len(values) > 0
+		case model.AssertSourceJSON:
+			if !docParsed {
+				docErr = json.Unmarshal(body, &doc)
+				docParsed = true
+			}
+			if docErr != nil {
+				failures = append(failures, newFailure(a, "", fmt.Sprintf("response is not valid JSON: %v", docErr)))
+				continue
+			}
+			v, ok, err := lookupJSON(doc, a.Property)
+			if err != nil {
+				failures = append(failures, newFailure(a, "", err.Error()))
+				continue
+			}
+			actual, present = jsonString(v), ok
+		case model.AssertSourceTiming:
+			var ms float64
+			ms, present = timings[a.Property+"_ms"]
+			actual = strconv.FormatFloat(ms, 'f', -1, 64)
+		}
+
+		if msg := applyOperator(a, actual, present); msg != "" {
+			failures = append(failures, newFailure(a, actual, msg))
+		}
+	}
+
+	return failures
+}
+
+// applyAssertions evaluates assertions and records the outcome on result,
+// marking it down if any fail.
+func applyAssertions(result *Result, assertions []model.Assertion, resp *http.Response, body []byte) {
+	failures := evaluateAssertions(assertions, resp, body, resultTimings(result))
+	result.Details["assertions_total"] = len(assertions)
+	if len(failures) == 0 {
+		return
+	}
+
+	result.Details["assertions_failed"] = failures
+	result.Status = model.StatusDown
+	if result.Error == "" {
+		result.Error = fmt.Sprintf("assertion failed: %s", failures[0].Message)
+		if len(failures) > 1 {
+			result.Error += fmt.Sprintf(" (and %d more)", len(failures)-1)
+		}
+	}
+}
+
+// applyOperator returns a failure message, or "" if the assertion holds.
+func applyOperator(a model.Assertion, actual string, present bool) string {
+	switch a.Operator {
+	case model.AssertExists:
+		if !present {
+			return fmt.Sprintf("%s %q is missing", a.Source, a.Property)
+		}
+		return ""
+	case model.AssertNotExists:
+		if present {
+			return fmt.Sprintf("%s %q is present", a.Source, a.Property)
+		}
+		return ""
+	}
+
+	if !present {
+		if a.Operator == model.AssertNotEquals || a.Operator == model.AssertNotContains || a.Operator == model.AssertNotMatches {
+			return ""
+		}
+		return fmt.Sprintf("%s %q is missing", a.Source, a.Property)
+	}
+
+	switch a.Operator {
+	case model.AssertEquals:
+		if actual != a.Value {
+			return fmt.Sprintf("expected %q, got %q", a.Value, truncateActual(actual))
+		}
+	case model.AssertNotEquals:
+		if actual == a.Value {
+			return fmt.Sprintf("expected value other than %q", a.Value)
+		}
+	case model.AssertContains:
+		if !strings.Contains(actual, a.Value) {
+			return fmt.Sprintf("%q not found", a.Value)
+		}
+	case model.AssertNotContains:
+		if strings.Contains(actual, a.Value) {
+			return fmt.Sprintf("%q found but must not be present", a.Value)
+		}
+	case model.AssertLessThan, model.AssertGreaterThan:
+		got, err := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+		if err != nil {
+			return fmt.Sprintf("%q is not a number", truncateActual(actual))
+		}
+		want, err := strconv.ParseFloat(a.Value, 64)
+		if err != nil {
+			return fmt.Sprintf("%q is not a number", a.Value)
+		}
+		if a.Operator == model.AssertLessThan && got >= want {
+			return fmt.Sprintf("%s is not less than %s", actual, a.Value)
+		}
+		if a.Operator == model.AssertGreaterThan && got <= want {
+			return fmt.Sprintf("%s is not greater than %s", actual, a.Value)
+		}
+	case model.AssertMatches, model.AssertNotMatches:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fmt.Sprintf("invalid regex: %v", err)
+		}
+		matched := re.MatchString(actual)
+		if a.Operator == model.AssertMatches && !matched {
+			return fmt.Sprintf("no match for /%s/", a.Value)
+		}
+		if a.Operator == model.AssertNotMatches && matched {
+			return fmt.Sprintf("/%s/ matched but must not", a.Value)
+		}
+	default:
+		return fmt.Sprintf("unknown operator %q", a.Operator)
+	}
+	return ""
+}
+
+func newFailure(a model.Assertion, actual, msg string) assertionFailure {
+	f := assertionFailure{
+		Source:   a.Source,
+		Property: a.Property,
+		Operator: a.Operator,
+		Expected: a.Value,
+		Message:  msg,
+	}
+	// Echoing a whole page body back is noise; only record values for
+	// headers, JSON fields and timings.
+	if a.Source != model.AssertSourceBody {
+		f.Actual = truncateActual(actual)
+	}
+	return f
+}
+
+func truncateActual(s string) string {
+	if len(s) <= maxActualLen {
+		return s
+	}
+	return s[:maxActualLen] + "..."
+}
+
+// jsonString renders a decoded JSON value for comparison. Strings compare by
+// their contents; everything else compares by its compact JSON encoding.
+func jsonString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// pathToken is one step of a parsed JSONPath or JSON pointer.
+type pathToken struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath accepts either a JSON pointer ("/items/0/name") or a simple
+// JSONPath ("$.items[0].name", "$['odd key']"). Wildcards and filters are
+// not supported.
+func parseJSONPath(path string) ([]pathToken, error) {
+	if path == "" {
+		return nil, fmt.Errorf("json assertions need a JSONPath or JSON pointer in property")
+	}
+
+	if strings.HasPrefix(path, "/") {
+		var tokens []pathToken
+		for _, part := range strings.Split(path[1:], "/") {
+			part = strings.ReplaceAll(part, "~1", "/")
+			part = strings.ReplaceAll(part, "~0", "~")
+			tok := pathToken{key: part}
+			if n, err := strconv.Atoi(part); err == nil && n >= 0 {
+				tok.index, tok.isIndex = n, true
+			}
+			tokens = append(tokens, tok)
+		}
+		return tokens, nil
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q: must start with $ or /", path)
+	}
+
+	var tokens []pathToken
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: empty key", path)
+			}
+			tokens = append(tokens, pathToken{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSON path %q: unclosed [", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				tokens = append(tokens, pathToken{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: bad index %q", path, inner)
+			}
+			tokens = append(tokens, pathToken{index: n, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return tokens, nil
+}
+
+// lookupJSON resolves a path against a decoded JSON document. The boolean
+// reports whether the value exists.
+func lookupJSON(doc any, path string) (any, bool, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cur := doc
+	for _, tok := range tokens {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[tok.key]
+			if !ok {
+				return nil, false, nil
+			}
+			cur = next
+		case []any:
+			if !tok.isIndex || tok.index >= len(v) {
+				return nil, false, nil
+			}
+			cur = v[tok.index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
@@ -0,0 +1,183 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []pathToken
+		wantErr bool
+	}{
+		{"$.items[0].name", []pathToken{{key: "items"}, {index: 0, isIndex: true}, {key: "name"}}, false},
+		{"$['odd key'][\"x\"]", []pathToken{{key: "odd key"}, {key: "x"}}, false},
+		{"$", nil, false},
+		{"/items/0/name", []pathToken{{key: "items"}, {key: "0", index: 0, isIndex: true}, {key: "name"}}, false},
+		{"/a~1b/c~0d", []pathToken{{key: "a/b"}, {key: "c~d"}}, false},
+		{"", nil, true},
+		{"items.name", nil, true},
+		{"$..name", nil, true},
+		{"$.items[0", nil, true},
+		{"$.items[-1]", nil, true},
+		{"$.items[*]", nil, true},
+		{"$x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseJSONPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseJSONPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLookupJSON(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{"status":"ok","items":[{"name":"a","n":1}],"odd key":null,"ready":true}`), &doc)
+
+	tests := []struct {
+		path    string
+		want    string
+		present bool
+	}{
+		{"$.status", "ok", true},
+		{"$.items[0].name", "a", true},
+		{"/items/0/n", "1", true},
+		{"$.ready", "true", true},
+		{"$['odd key']", "null", true},
+		{"$.items", `[{"n":1,"name":"a"}]`, true},
+		{"$.items[1]", "", false},
+		{"$.status.len", "", false},
+		{"$.missing", "", false},
+		{"$.items.name", "", false},
+	}
+	for _, tt := range tests {
+		v, ok, err := lookupJSON(doc, tt.path)
+		if err != nil {
+			t.Errorf("lookupJSON(%q) error = %v", tt.path, err)
+			continue
+		}
+		if ok != tt.present || (ok && jsonString(v) != tt.want) {
+			t.Errorf("lookupJSON(%q) = %s, %v, want %s, %v", tt.path, jsonString(v), ok, tt.want, tt.present)
+		}
+	}
+}
+
+func TestApplyOperator(t *testing.T) {
+	assert := func(op, value string) model.Assertion {
+		return model.Assertion{Source: model.AssertSourceHeader, Property: "X-Test", Operator: op, Value: value}
+	}
+	tests := []struct {
+		name    string
+		a       model.Assertion
+		actual  string
+		present bool
+		holds   bool
+	}{
+		{"equals", assert(model.AssertEquals, "ok"), "ok", true, true},
+		{"equals differs", assert(model.AssertEquals, "ok"), "OK", true, false},
+		{"not equals", assert(model.AssertNotEquals, "ok"), "down", true, true},
+		{"contains", assert(model.AssertContains, "ell"), "hello", true, true},
+		{"not contains", assert(model.AssertNotContains, "error"), "an error", true, false},
+		{"matches", assert(model.AssertMatches, `^v\d+$`), "v12", true, true},
+		{"not matches", assert(model.AssertNotMatches, `^v\d+$`), "v12", true, false},
+		{"less than", assert(model.AssertLessThan, "10"), " 9.5 ", true, true},
+		{"less than is strict", assert(model.AssertLessThan, "10"), "10", true, false},
+		{"greater than", assert(model.AssertGreaterThan, "10"), "11", true, true},
+		{"not a number", assert(model.AssertGreaterThan, "10"), "many", true, false},
+		{"exists", assert(model.AssertExists, ""), "", true, true},
+		{"exists missing", assert(model.AssertExists, ""), "", false, false},
+		{"not exists", assert(model.AssertNotExists, ""), "", false, true},
+		{"not exists present", assert(model.AssertNotExists, ""), "x", true, false},
+		{"missing fails equals", assert(model.AssertEquals, ""), "", false, false},
+		{"missing passes negations", assert(model.AssertNotContains, "x"), "", false, true},
+		{"unknown operator", assert("near", "x"), "x", true, false},
+	}
+	for _, tt := range tests {
+		if msg := applyOperator(tt.a, tt.actual, tt.present); (msg == "") != tt.holds {
+			t.Errorf("%s: applyOperator() = %q, want holds %v", tt.name, msg, tt.holds)
+		}
+	}
+}
+
+func TestValidateAssertions(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       model.Assertion
+		wantErr bool
+	}{
+		{"body contains", model.Assertion{Source: "body", Operator: "contains", Value: "ok"}, false},
+		{"json path", model.Assertion{Source: "json", Property: "$.a", Operator: "exists"}, false},
+		{"timing phase", model.Assertion{Source: "timing", Property: model.HTTPPhases[0], Operator: "less_than", Value: "100"}, false},
+		{"unknown source", model.Assertion{Source: "cookie", Operator: "exists"}, true},
+		{"header without name", model.Assertion{Source: "header", Operator: "exists"}, true},
+		{"bad json path", model.Assertion{Source: "json", Property: "a.b", Operator: "exists"}, true},
+		{"unknown phase", model.Assertion{Source: "timing", Property: "render", Operator: "less_than", Value: "1"}, true},
+		{"bad regex", model.Assertion{Source: "body", Operator: "matches", Value: "("}, true},
+		{"exists on body", model.Assertion{Source: "body", Operator: "exists"}, true},
+		{"non-numeric bound", model.Assertion{Source: "body", Operator: "less_than", Value: "x"}, true},
+		{"unknown operator", model.Assertion{Source: "body", Operator: "near"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAssertions([]model.Assertion{tt.a}); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateAssertions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKeywordCheckAssertions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "v2")
+		w.Write([]byte(`{"status":"ok","checks":[{"name":"db","healthy":true}]}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		keyword    string
+		assertions []model.Assertion
+		wantStatus model.CheckStatus
+		wantFailed int
+	}{
+		{"keyword found", `"ok"`, nil, model.StatusUp, 0},
+		{"keyword missing", "degraded", nil, model.StatusDown, 0},
+		{"assertions hold", "", []model.Assertion{
+			{Source: "json", Property: "$.checks[0].healthy", Operator: "equals", Value: "true"},
+			{Source: "header", Property: "X-Version", Operator: "matches", Value: `^v\d$`},
+			{Source: "body", Operator: "not_contains", Value: "error"},
+		}, model.StatusUp, 0},
+		{"failing assertions", "", []model.Assertion{
+			{Source: "json", Property: "/status", Operator: "equals", Value: "ok"},
+			{Source: "json", Property: "$.checks[1]", Operator: "exists"},
+			{Source: "header", Property: "X-Version", Operator: "equals", Value: "v3"},
+		}, model.StatusDown, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: srv.URL, TimeoutMS: 5000, ExpectedKeyword: tt.keyword,
+				Options: &model.MonitorOptions{Assertions: tt.assertions}}
+			result, err := NewKeywordChecker(nil).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", result.Status, result.Error, tt.wantStatus)
+			}
+			failed, _ := result.Details["assertions_failed"].([]assertionFailure)
+			if len(failed) != tt.wantFailed {
+				t.Errorf("failed assertions = %+v, want %d", failed, tt.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultMaxAssets = 50
+	maxAssetsLimit   = 200
+
+	// assetFetchers is the number of assets fetched at once, as browsers
+	// open about six connections per host.
+	assetFetchers = 6
+
+	// maxAssetBytes bounds the bytes read, and counted towards the page
+	// weight, for one asset.
+	maxAssetBytes = 20 << 20
+)
+
+// pageAsset is a subresource linked from a page.
+type pageAsset struct {
+	url   *url.URL
+	kind  string // "script", "stylesheet" or "image"
+	mixed bool   // plain HTTP on an HTTPS page
+}
+
+// assetResult is the outcome of fetching one asset. An asset is skipped
+// when the check ran out of time before it could be fetched, which says
+// nothing about whether it would load.
+type assetResult struct {
+	bytes   int64
+	err     string
+	skipped bool
+}
+
+// checkAssets fetches the subresources linked from an HTML page and records
+// broken assets, mixed content and the page weight in result. pageBytes is
+// the size of the page itself. A result that is up becomes degraded when an
+// asset is broken or the page outweighs opts.MaxWeightKB. Assets the check
+// had no time left for are reported as skipped rather than broken.
+func checkAssets(ctx context.Context, result *Result, opts *model.AssetOptions, page *url.URL, body []byte, pageBytes int64, timeout time.Duration) {
+	assets, total := parseAssets(page, body, opts)
+
+	start := time.Now()
+	results := make([]assetResult, len(assets))
+	client := assetClient(ctx, timeout)
+	defer client.CloseIdleConnections()
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range min(assetFetchers, len(assets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					results[i] = assetResult{skipped: true}
+					continue
+				}
+				results[i] = fetchAsset(ctx, client, assets[i], page)
+			}
+		}()
+	}
+	for i := range assets {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	weight := pageBytes
+	var broken []map[string]any
+	var mixed, skipped []string
+	for i, a := range assets {
+		r := results[i]
+		weight += r.bytes
+		if a.mixed {
+			mixed = append(mixed, a.url.String())
+		}
+		if r.skipped {
+			skipped = append(skipped, a.url.String())
+		} else if r.err != "" {
+			broken = append(broken, map[string]any{"url": a.url.String(), "type": a.kind, "error": r.err})
+		}
+	}
+
+	details := map[string]any{
+		"found":        total,
+		"checked":      len(assets),
+		"broken":       len(broken),
+		"skipped":      len(skipped),
+		"page_bytes":   pageBytes,
+		"weight_bytes": weight,
+		"fetch_ms":     durationMS(time.Since(start)),
+	}
+	if len(broken) > 0 {
+		details["broken_assets"] = broken
+	}
+	if len(mixed) > 0 {
+		details["mixed_content"] = mixed
+	}
+	if len(skipped) > 0 {
+		details["skipped_assets"] = skipped
+	}
+	result.Details["assets"] = details
+
+	if result.Status != model.StatusUp {
+		return
+	}
+	switch {
+	case len(broken) > 0:
+		result.Status = model.StatusDegraded
+		first := broken[0]
+		result.Error = fmt.Sprintf("%d of %d assets broken: %s (%s)", len(broken), len(assets), first["url"], first["error"])
+	case opts.MaxWeightKB > 0 && weight > int64(opts.MaxWeightKB)<<10:
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("page weight %d KB exceeds %d KB", weight>>10, opts.MaxWeightKB)
+	}
+}
+
+// parseAssets returns the scripts, stylesheets and images linked from an
+// HTML page, in document order and without duplicates, up to the configured
+// limit, and the number found in total. Links are resolved against page,
+// or the document's <base>.
+func parseAssets(page *url.URL, body []byte, opts *model.AssetOptions) ([]pageAsset, int) {
+	limit := opts.MaxAssets
+	if limit == 0 {
+		limit = defaultMaxAssets
+	}
+
+	base, baseSet := page, false
+	seen := map[string]bool{}
+	var assets []pageAsset
+	total := 0
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return assets, total
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		attrs := map[string]string{}
+		for hasAttr {
+			var k, v []byte
+			k, v, hasAttr = z.TagAttr()
+			attrs[string(k)] = string(v)
+		}
+
+		var kind, ref string
+		switch string(name) {
+		case "base":
+			// Only the first <base> with an href counts.
+			if href := strings.TrimSpace(attrs["href"]); href != "" && !baseSet {
+				if u, err := page.Parse(href); err == nil {
+					base, baseSet = u, true
+				}
+			}
+		case "script":
+			kind, ref = "script", attrs["src"]
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+				if rel == "stylesheet" {
+					kind, ref = "stylesheet", attrs["href"]
+				}
+			}
+		case "img":
+			if !opts.SkipImages {
+				kind, ref = "image", attrs["src"]
+			}
+		}
+		if ref = strings.TrimSpace(ref); ref == "" {
+			continue
+		}
+
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue // data: and blob: URLs, or unparseable links
+		}
+		u.Fragment = ""
+		if seen[u.String()] {
+			continue
+		}
+		seen[u.String()] = true
+		total++
+		if len(assets) < limit {
+			assets = append(assets, pageAsset{url: u, kind: kind, mixed: page.Scheme == "https" && u.Scheme == "http"})
+		}
+	}
+}
+
+// assetClient returns the client assets are fetched with. It keeps
+// connections open between assets, like a browser, and dials over the
+// check's address family without recording the address, so resolved_ip
+// stays that of the page.
+func assetClient(ctx context.Context, timeout time.Duration) *http.Client {
+	info := dialInfoFrom(ctx)
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, info.network(network), address)
+			},
+			MaxIdleConnsPerHost: assetFetchers,
+		},
+	}
+}
+
+// fetchAsset downloads an asset and reports why it would fail to load in a
+// browser, if it would.
+func fetchAsset(ctx context.Context, client *http.Client, a pageAsset, page *url.URL) assetResult {
+	// Browsers block scripts and stylesheets loaded over plain HTTP from an
+	// HTTPS page, so there is nothing to fetch.
+	if a.mixed && a.kind != "image" {
+		return assetResult{err: "blocked as mixed content"}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url.String(), nil)
+	if err != nil {
+		return assetResult{err: err.Error()}
+	}
+	req.Header.Set("User-Agent", "PingMesh/1.0")
+	req.Header.Set("Referer", page.String())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return assetResult{skipped: true}
+		}
+		// The URL is reported alongside, so drop it from the error.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return assetResult{err: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxAssetBytes))
+	r := assetResult{bytes: n}
+	switch {
+	case resp.StatusCode >= 400:
+		r.err = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	case err != nil && ctx.Err() != nil:
+		r.skipped = true
+	case err != nil:
+		r.err = fmt.Sprintf("reading body: %v", err)
+	case a.kind == "stylesheet":
+		// Stylesheets served with another type are ignored by browsers
+		// in standards mode.
+		if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct != "" && ct != "text/css" {
+			r.err = fmt.Sprintf("stylesheet served as %s", ct)
+		}
+	}
+	return r
+}
+
+// isHTML reports whether a response has an HTML content type.
+func isHTML(contentType string) bool {
+	ct, _, _ := mime.ParseMediaType(contentType)
+	return ct == "text/html" || ct == "application/xhtml+xml"
+}
+
+// ValidateAssetOptions checks page asset settings.
+func ValidateAssetOptions(checkType model.CheckType, opts *model.AssetOptions) error {
+	if checkType != model.CheckHTTP && checkType != model.CheckHTTPS {
+		return fmt.Errorf("asset checks apply to http and https checks")
+	}
+	if opts.MaxAssets < 0 || opts.MaxAssets > maxAssetsLimit {
+		return fmt.Errorf("max_assets must be between 0 and %d", maxAssetsLimit)
+	}
+	if opts.MaxWeightKB < 0 {
+		return fmt.Errorf("max_weight_kb must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestParseAssets(t *testing.T) {
+	page, _ := url.Parse("https://example.com/shop/index.html")
+	tests := []struct {
+		name      string
+		body      string
+		opts      model.AssetOptions
+		want      []string
+		wantTotal int
+	}{
+		{"kinds and resolution", `<link rel="stylesheet" href="/a.css"><script src="app.js"></script><img src="//cdn.example.com/logo.png">`,
+			model.AssetOptions{}, []string{"https://example.com/a.css", "https://example.com/shop/app.js", "https://cdn.example.com/logo.png"}, 3},
+		{"duplicates and fragments", `<script src="a.js"></script><script src="a.js#x"></script>`,
+			model.AssetOptions{}, []string{"https://example.com/shop/a.js"}, 1},
+		{"base href", `<base href="https://static.example.com/v2/"><script src="a.js"></script>`,
+			model.AssetOptions{}, []string{"https://static.example.com/v2/a.js"}, 1},
+		{"data urls and inline scripts", `<img src="data:image/png;base64,AA=="><script>alert(1)</script><link rel="icon" href="/f.ico">`,
+			model.AssetOptions{}, nil, 0},
+		{"skip images", `<img src="/a.png"><script src="/a.js"></script>`,
+			model.AssetOptions{SkipImages: true}, []string{"https://example.com/a.js"}, 1},
+		{"limit", `<script src="/1.js"></script><script src="/2.js"></script><script src="/3.js"></script>`,
+			model.AssetOptions{MaxAssets: 2}, []string{"https://example.com/1.js", "https://example.com/2.js"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets, total := parseAssets(page, []byte(tt.body), &tt.opts)
+			var got []string
+			for _, a := range assets {
+				got = append(got, a.url.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("parseAssets() = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+
+	assets, _ := parseAssets(page, []byte(`<script src="http://example.com/a.js"></script>`), &model.AssetOptions{})
+	if len(assets) != 1 || !assets[0].mixed {
+		t.Errorf("plain HTTP script on an HTTPS page not marked mixed: %+v", assets)
+	}
+}
+
+func TestCheckAssets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			w.Write([]byte("console.log(1)"))
+		case "/style.css":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("body{}"))
+		case "/slow.png":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	page, _ := url.Parse(srv.URL + "/")
+
+	tests := []struct {
+		name        string
+		body        string
+		deadline    time.Duration
+		wantStatus  model.CheckStatus
+		wantBroken  int
+		wantSkipped int
+	}{
+		{"all assets load", `<script src="/app.js"></script>`, time.Second, model.StatusUp, 0, 0},
+		{"missing image and mistyped stylesheet", `<img src="/missing.png"><link rel="stylesheet" href="/style.css">`, time.Second, model.StatusDegraded, 2, 0},
+		{"out of time is skipped, not broken", `<script src="/app.js"></script><img src="/slow.png">`, 200 * time.Millisecond, model.StatusUp, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+			result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+			checkAssets(ctx, result, &model.AssetOptions{}, page, []byte(tt.body), int64(len(tt.body)), 5*time.Second)
+
+			details := result.Details["assets"].(map[string]any)
+			if result.Status != tt.wantStatus || details["broken"] != tt.wantBroken || details["skipped"] != tt.wantSkipped {
+				t.Errorf("status %s (%s), broken %v, skipped %v; want %s, %d, %d",
+					result.Status, result.Error, details["broken"], details["skipped"], tt.wantStatus, tt.wantBroken, tt.wantSkipped)
+			}
+		})
+	}
+}
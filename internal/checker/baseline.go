@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// BaselineStore persists the baselines that stateful checks compare their
+// results against, so a restart does not quietly accept whatever the target
+// serves next.
+type BaselineStore interface {
+	GetBaseline(monitorID, key string) (*model.Baseline, error)
+	SaveBaseline(baseline *model.Baseline) error
+}
+
+// baselineSet holds a checker's baselines, cached in memory and backed by a
+// BaselineStore when the checker has one.
+type baselineSet struct {
+	store BaselineStore
+
+	mu    sync.Mutex
+	cache map[string]*model.Baseline // by monitor ID and key
+}
+
+// baselineKey returns the key of a monitor's baseline of kind, qualified
+// with the address family of the check running in ctx. The IPv4 and IPv6
+// checks of an address family "both" monitor run side by side, and each
+// keeps its own baseline instead of comparing against the other.
+func baselineKey(ctx context.Context, kind string) string {
+	if info := dialInfoFrom(ctx); info != nil && info.family != "" {
+		return kind + "/" + info.family
+	}
+	return kind
+}
+
+// get decodes the monitor's baseline under key into v. It reports false
+// when there is none to compare against: none was learned yet, or it was
+// learned from other settings than source, or before the monitor's
+// baseline was last accepted.
+func (b *baselineSet) get(monitor *model.Monitor, key, source string, v any) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cache == nil {
+		b.cache = map[string]*model.Baseline{}
+	}
+	base, ok := b.cache[monitor.ID+"/"+key]
+	if !ok && b.store != nil {
+		stored, err := b.store.GetBaseline(monitor.ID, key)
+		if err != nil {
+			log.Printf("[checker] loading %s baseline of monitor %s: %v", key, monitor.ID, err)
+			return false
+		}
+		if stored != nil {
+			b.cache[monitor.ID+"/"+key] = stored
+		}
+		base = stored
+	}
+	if base == nil || base.Source != source || base.AcceptedAt != monitor.BaselineAcceptedAt {
+		return false
+	}
+	return json.Unmarshal(base.Data, v) == nil
+}
+
+// set makes v the monitor's baseline under key.
+func (b *baselineSet) set(monitor *model.Monitor, key, source string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	base := &model.Baseline{
+		MonitorID:  monitor.ID,
+		Key:        key,
+		Source:     source,
+		AcceptedAt: monitor.BaselineAcceptedAt,
+		Data:       data,
+		LearnedAt:  time.Now().UnixMilli(),
+	}
+
+	b.mu.Lock()
+	if b.cache == nil {
+		b.cache = map[string]*model.Baseline{}
+	}
+	b.cache[monitor.ID+"/"+key] = base
+	b.mu.Unlock()
+
+	if b.store != nil {
+		if err := b.store.SaveBaseline(base); err != nil {
+			log.Printf("[checker] saving %s baseline of monitor %s: %v", key, monitor.ID, err)
+		}
+	}
+}
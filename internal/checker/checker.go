@@ -39,6 +39,50 @@ func Get(checkType model.CheckType) (Checker, error) {
 	return c, nil
 }
 
+// ApplyLatencyThresholds marks a result degraded or down when it exceeded
+// the monitor's warning or critical latency, overall or in one of the HTTP
+// timing phases. Results that are already down are left alone.
+func ApplyLatencyThresholds(result *Result, monitor *model.Monitor) {
+	if result.Status == model.StatusDown || monitor.Options == nil || monitor.Options.Latency == nil {
+		return
+	}
+
+	l := monitor.Options.Latency
+	timings := resultTimings(result)
+
+	if l.CriticalMS > 0 && result.LatencyMS > l.CriticalMS {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("latency %.1fms exceeds critical threshold %.0fms", result.LatencyMS, l.CriticalMS)
+		return
+	}
+	for _, phase := range model.HTTPPhases {
+		ms, ok := timings[phase+"_ms"]
+		if t := l.Phases[phase]; ok && t.CriticalMS > 0 && ms > t.CriticalMS {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("%s time %.1fms exceeds critical threshold %.0fms", phase, ms, t.CriticalMS)
+			return
+		}
+	}
+
+	if l.WarnMS > 0 && result.LatencyMS > l.WarnMS {
+		result.Status = model.StatusDegraded
+		if result.Error == "" {
+			result.Error = fmt.Sprintf("latency %.1fms exceeds warning threshold %.0fms", result.LatencyMS, l.WarnMS)
+		}
+		return
+	}
+	for _, phase := range model.HTTPPhases {
+		ms, ok := timings[phase+"_ms"]
+		if t := l.Phases[phase]; ok && t.WarnMS > 0 && ms > t.WarnMS {
+			result.Status = model.StatusDegraded
+			if result.Error == "" {
+				result.Error = fmt.Sprintf("%s time %.1fms exceeds warning threshold %.0fms", phase, ms, t.WarnMS)
+			}
+			return
+		}
+	}
+}
+
 // RegisterAll registers all built-in checker implementations.
 func RegisterAll() {
 	Register(&ICMPChecker{})
@@ -47,4 +91,21 @@ func RegisterAll() {
 	Register(&HTTPChecker{checkType: model.CheckHTTPS})
 	Register(&DNSChecker{})
 	Register(&KeywordChecker{})
+	Register(&HTTPFlowChecker{})
+	Register(&TLSChecker{})
+	Register(&TracerouteChecker{})
+	Register(&GRPCChecker{})
+	Register(&PostgresChecker{})
+	Register(&MySQLChecker{})
+	Register(&RedisChecker{})
+	Register(&SMTPChecker{})
+	Register(&IMAPChecker{})
+	Register(&POP3Checker{})
+	Register(&DomainChecker{})
+	Register(&UDPChecker{})
+	Register(&NTPChecker{})
+	Register(&SNMPChecker{})
+	Register(&RADIUSChecker{})
+	Register(&WebSocketChecker{})
+	Register(&SSEChecker{})
 }
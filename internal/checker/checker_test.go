@@ -0,0 +1,37 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestApplyLatencyThresholds(t *testing.T) {
+	thresholds := &model.MonitorOptions{Latency: &model.LatencyOptions{WarnMS: 100, CriticalMS: 500}}
+	tests := []struct {
+		name    string
+		status  model.CheckStatus
+		latency float64
+		opts    *model.MonitorOptions
+		want    model.CheckStatus
+	}{
+		{"no thresholds", model.StatusUp, 900, nil, model.StatusUp},
+		{"under warning", model.StatusUp, 99, thresholds, model.StatusUp},
+		{"over warning", model.StatusUp, 101, thresholds, model.StatusDegraded},
+		{"over critical", model.StatusUp, 501, thresholds, model.StatusDown},
+		{"already down", model.StatusDown, 10, thresholds, model.StatusDown},
+		{"critical only", model.StatusUp, 300, &model.MonitorOptions{Latency: &model.LatencyOptions{CriticalMS: 200}}, model.StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Status: tt.status, LatencyMS: tt.latency, Details: map[string]any{}}
+			ApplyLatencyThresholds(result, &model.Monitor{Options: tt.opts})
+			if result.Status != tt.want {
+				t.Errorf("status = %s, want %s", result.Status, tt.want)
+			}
+			if tt.want != tt.status && result.Error == "" {
+				t.Error("threshold breach left no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const (
+	defaultMinSimilarity = 90
+
+	// maxDiffLines caps the removed and added lines kept in a result.
+	maxDiffLines = 50
+)
+
+var (
+	htmlHidden = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>|<!--.*?-->`)
+	htmlTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// contentBaseline is the normalized text first seen for a monitor, and the
+// hash seen by the latest check.
+type contentBaseline struct {
+	Hash     string   `json:"hash"`
+	Lines    []string `json:"lines"`
+	LastHash string   `json:"last_hash"`
+}
+
+// compareContent fingerprints body and records the outcome in result: the hash,
+// whether it differs from the previous check, and the similarity to the
+// baseline. Below the monitor's threshold the result is marked down and the
+// diff is kept as content_diff.
+func (c *KeywordChecker) compareContent(ctx context.Context, result *Result, monitor *model.Monitor, opts *model.ContentOptions, body []byte, contentType string) {
+	lines, err := normalizeContent(string(body), contentType, opts.Ignore)
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = err.Error()
+		return
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	hash := hex.EncodeToString(sum[:])
+	result.Details["content_hash"] = hash
+
+	// The baseline is kept until the target or ignore patterns change or
+	// the monitor's baseline is accepted again, so a changed page stays
+	// down across restarts until someone accepts it.
+	source := monitor.Target + "\n" + strings.Join(opts.Ignore, "\n")
+	key := baselineKey(ctx, "content")
+	var base contentBaseline
+	if !c.baselines.get(monitor, key, source, &base) {
+		c.baselines.set(monitor, key, source, contentBaseline{Hash: hash, Lines: lines, LastHash: hash})
+		result.Details["content_baseline"] = true
+		result.Details["similarity"] = 100.0
+		return
+	}
+	changed := base.LastHash != hash
+	if changed {
+		base.LastHash = hash
+		c.baselines.set(monitor, key, source, base)
+	}
+
+	result.Details["content_changed"] = changed
+	if hash == base.Hash {
+		result.Details["similarity"] = 100.0
+		return
+	}
+
+	removed, added := diffLines(base.Lines, lines)
+	similarity := contentSimilarity(base.Lines, lines, removed, added)
+	result.Details["similarity"] = similarity
+
+	threshold := opts.MinSimilarity
+	if threshold == 0 {
+		threshold = defaultMinSimilarity
+	}
+	if similarity < threshold {
+		result.Details["baseline_hash"] = base.Hash
+		result.Details["content_diff"] = formatDiff(removed, added)
+		if result.Status != model.StatusDown {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("content changed: %.1f%% similar to baseline, below %g%%", similarity, threshold)
+		}
+	}
+}
+
+// normalizeContent reduces a page to its visible text: for HTML, scripts,
+// styles, comments and tags are dropped and entities decoded. Whitespace is
+// collapsed, ignore patterns are removed, and the non-empty lines are
+// returned.
+func normalizeContent(body, contentType string, ignore []string) ([]string, error) {
+	text := body
+	if strings.Contains(contentType, "html") || (contentType == "" && strings.Contains(strings.ToLower(body), "<html")) {
+		text = htmlHidden.ReplaceAllString(text, "")
+		text = htmlTag.ReplaceAllString(text, "\n")
+		text = html.UnescapeString(text)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(ignore) == 0 {
+		return lines, nil
+	}
+
+	// Ignore patterns see the normalized text, as shown in diffs, so they
+	// may span lines with (?s).
+	text = strings.Join(lines, "\n")
+	for _, pattern := range ignore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
+		}
+		text = re.ReplaceAllString(text, "")
+	}
+	lines = lines[:0]
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// diffLines returns the lines of before missing from after and the lines
+// of after missing from before, counting repeated lines, each in document
+// order.
+func diffLines(before, after []string) (removed, added []string) {
+	counts := map[string]int{}
+	for _, l := range after {
+		counts[l]++
+	}
+	for _, l := range before {
+		if counts[l] > 0 {
+			counts[l]--
+		} else {
+			removed = append(removed, l)
+		}
+	}
+
+	counts = map[string]int{}
+	for _, l := range before {
+		counts[l]++
+	}
+	for _, l := range after {
+		if counts[l] > 0 {
+			counts[l]--
+		} else {
+			added = append(added, l)
+		}
+	}
+	return removed, added
+}
+
+// contentSimilarity is the percentage of text, by length, that both
+// versions share.
+func contentSimilarity(before, after, removed, added []string) float64 {
+	size := func(lines []string) int {
+		n := 0
+		for _, l := range lines {
+			n += len(l) + 1
+		}
+		return n
+	}
+	total := size(before) + size(after)
+	if total == 0 {
+		return 100
+	}
+	changed := size(removed) + size(added)
+	return math.Round(1000*(1-float64(changed)/float64(total))) / 10
+}
+
+// formatDiff renders removed and added lines as "- " and "+ " lines,
+// keeping at most maxDiffLines of each.
+func formatDiff(removed, added []string) string {
+	var b strings.Builder
+	for _, part := range []struct {
+		prefix string
+		lines  []string
+	}{{"- ", removed}, {"+ ", added}} {
+		for i, l := range part.lines {
+			if i == maxDiffLines {
+				fmt.Fprintf(&b, "%s... %d more lines\n", part.prefix, len(part.lines)-maxDiffLines)
+				break
+			}
+			b.WriteString(part.prefix + truncateActual(l) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// ValidateContentOptions checks content-change detection settings.
+func ValidateContentOptions(checkType model.CheckType, opts *model.ContentOptions) error {
+	if checkType != model.CheckHTTPKeyword {
+		return fmt.Errorf("content change detection applies to http_keyword checks")
+	}
+	if opts.MinSimilarity < 0 || opts.MinSimilarity > 100 {
+		return fmt.Errorf("min_similarity must be between 0 and 100")
+	}
+	for _, pattern := range opts.Ignore {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
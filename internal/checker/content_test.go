@@ -0,0 +1,219 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		ignore      []string
+		want        []string
+		wantErr     bool
+	}{
+		{"html", `<html><head><style>p{}</style><script>var x = "<p>";</script></head>
+			<body><!-- build 42 --><h1>Welcome  to   Example</h1><p>Fish &amp; chips</p></body></html>`,
+			"text/html; charset=utf-8", nil, []string{"Welcome to Example", "Fish & chips"}, false},
+		{"html sniffed", "<HTML><p>a</p><p>b</p></HTML>", "", nil, []string{"a", "b"}, false},
+		{"plain text keeps tags", "x <b>y</b>\n\n  z  ", "text/plain", nil, []string{"x <b>y</b>", "z"}, false},
+		{"ignore pattern", "<p>Updated 2026-10-18 09:12</p><p>News</p>", "text/html",
+			[]string{`Updated \d{4}-\d{2}-\d{2} \d{2}:\d{2}`}, []string{"News"}, false},
+		{"ignore across lines", "<p>Ad</p><p>Buy now</p><p>Body</p>", "text/html",
+			[]string{`(?s)Ad\nBuy now`}, []string{"Body"}, false},
+		{"bad ignore pattern", "text", "text/plain", []string{"("}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeContent(tt.body, tt.contentType, tt.ignore)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: normalizeContent() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: normalizeContent() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		before      []string
+		after       []string
+		wantRemoved []string
+		wantAdded   []string
+	}{
+		{"same", []string{"a", "b"}, []string{"a", "b"}, nil, nil},
+		{"reordered", []string{"a", "b"}, []string{"b", "a"}, nil, nil},
+		{"changed line", []string{"a", "b", "c"}, []string{"a", "B", "c"}, []string{"b"}, []string{"B"}},
+		{"repeated lines counted", []string{"x", "x", "y"}, []string{"x", "y"}, []string{"x"}, nil},
+		{"all new", nil, []string{"a", "b"}, nil, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		removed, added := diffLines(tt.before, tt.after)
+		if !reflect.DeepEqual(removed, tt.wantRemoved) || !reflect.DeepEqual(added, tt.wantAdded) {
+			t.Errorf("%s: diffLines() = %q, %q, want %q, %q", tt.name, removed, added, tt.wantRemoved, tt.wantAdded)
+		}
+	}
+}
+
+func TestContentSimilarity(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+		want   float64
+	}{
+		{"identical", []string{"hello", "world"}, []string{"hello", "world"}, 100},
+		{"both empty", nil, nil, 100},
+		// 12 bytes each side; "b"/"B" (2 bytes each) changed: 1 - 4/24.
+		{"one short line changed", []string{"aaaa", "b", "cccc"}, []string{"aaaa", "B", "cccc"}, 83.3},
+		{"replaced", []string{"old page"}, []string{"hacked"}, 0},
+		{"line added", []string{"aaa"}, []string{"aaa", "bbb"}, 66.7},
+	}
+	for _, tt := range tests {
+		removed, added := diffLines(tt.before, tt.after)
+		if got := contentSimilarity(tt.before, tt.after, removed, added); got != tt.want {
+			t.Errorf("%s: contentSimilarity() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	if got, want := formatDiff([]string{"old"}, []string{"new", "more"}), "- old\n+ new\n+ more\n"; got != want {
+		t.Errorf("formatDiff() = %q, want %q", got, want)
+	}
+
+	many := make([]string, maxDiffLines+5)
+	for i := range many {
+		many[i] = "line"
+	}
+	got := formatDiff(nil, many)
+	if n := strings.Count(got, "+ line\n"); n != maxDiffLines {
+		t.Errorf("formatDiff() kept %d lines, want %d", n, maxDiffLines)
+	}
+	if !strings.HasSuffix(got, "+ ... 5 more lines\n") {
+		t.Errorf("formatDiff() = ...%q, want a count of the lines left out", got[len(got)-30:])
+	}
+}
+
+func TestValidateContentOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType model.CheckType
+		opts      model.ContentOptions
+		wantErr   bool
+	}{
+		{"defaults", model.CheckHTTPKeyword, model.ContentOptions{}, false},
+		{"threshold and ignores", model.CheckHTTPKeyword, model.ContentOptions{MinSimilarity: 75, Ignore: []string{`\d+ visitors`}}, false},
+		{"not a keyword check", model.CheckHTTP, model.ContentOptions{}, true},
+		{"threshold above 100", model.CheckHTTPKeyword, model.ContentOptions{MinSimilarity: 101}, true},
+		{"negative threshold", model.CheckHTTPKeyword, model.ContentOptions{MinSimilarity: -1}, true},
+		{"bad ignore pattern", model.CheckHTTPKeyword, model.ContentOptions{Ignore: []string{"[a-"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateContentOptions(tt.checkType, &tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateContentOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKeywordCheckContent(t *testing.T) {
+	var mu sync.Mutex
+	page, code := "", http.StatusOK
+	serve := func(p string, c int) {
+		mu.Lock()
+		defer mu.Unlock()
+		page, code = p, c
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(code)
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	original := "<html><h1>Example Shop</h1><p>Fresh fish daily</p><p>Open 9 to 5</p><p>Call 555-0100</p>" +
+		"<p>Free delivery</p><p>Since 1998</p><p>Visits: 1041</p></html>"
+	store := &memBaselines{}
+	m := &model.Monitor{ID: "m1", Target: srv.URL, TimeoutMS: 5000,
+		Options: &model.MonitorOptions{Content: &model.ContentOptions{MinSimilarity: 80, Ignore: []string{`Visits: \d+`}}}}
+
+	steps := []struct {
+		name         string
+		page         string
+		code         int
+		restart      bool
+		accept       int64
+		want         model.CheckStatus
+		wantChanged  any
+		wantBaseline bool
+		wantDiff     string
+	}{
+		{"learns the baseline", original, 200, false, 0, model.StatusUp, nil, true, ""},
+		{"ignored counter", strings.Replace(original, "1041", "1042", 1), 200, false, 0, model.StatusUp, false, false, ""},
+		{"small edit", strings.Replace(original, "9 to 5", "9 to 6", 1), 200, false, 0, model.StatusUp, true, false, ""},
+		{"same edit again", strings.Replace(original, "9 to 5", "9 to 6", 1), 200, false, 0, model.StatusUp, false, false, ""},
+		{"defaced", "<html><h1>hacked by nobody</h1></html>", 200, false, 0, model.StatusDown, true, false,
+			"- Example Shop\n- Fresh fish daily\n"},
+		{"error page not compared", "<html>maintenance</html>", 503, false, 0, model.StatusDown, nil, false, ""},
+		{"still down after a restart", "<html><h1>hacked by nobody</h1></html>", 200, true, 0, model.StatusDown, false, false,
+			"+ hacked by nobody\n"},
+		{"accepted as the new baseline", "<html><h1>hacked by nobody</h1></html>", 200, false, 1760000000000, model.StatusUp, nil, true, ""},
+	}
+	c := NewKeywordChecker(store)
+	for _, step := range steps {
+		if step.restart {
+			c = NewKeywordChecker(store)
+		}
+		if step.accept != 0 {
+			m.BaselineAcceptedAt = step.accept
+		}
+		serve(step.page, step.code)
+		result, err := c.Check(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != step.want {
+			t.Errorf("%s: status = %s (%s), want %s", step.name, result.Status, result.Error, step.want)
+		}
+		if got := result.Details["content_changed"]; got != step.wantChanged {
+			t.Errorf("%s: content_changed = %v, want %v", step.name, got, step.wantChanged)
+		}
+		if got, _ := result.Details["content_baseline"].(bool); got != step.wantBaseline {
+			t.Errorf("%s: content_baseline = %v, want %v", step.name, got, step.wantBaseline)
+		}
+		diff, _ := result.Details["content_diff"].(string)
+		if !strings.Contains(diff, step.wantDiff) || (step.wantDiff == "") != (diff == "") {
+			t.Errorf("%s: content_diff = %q, want it to contain %q", step.name, diff, step.wantDiff)
+		}
+	}
+}
+
+func TestKeywordCheckContentPerFamily(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same page"))
+	}))
+	defer srv.Close()
+
+	store := &memBaselines{}
+	m := &model.Monitor{ID: "m1", Target: srv.URL, TimeoutMS: 5000,
+		Options: &model.MonitorOptions{Content: &model.ContentOptions{}, AddressFamily: model.AddressFamilyIPv4}}
+	if _, err := Run(context.Background(), NewKeywordChecker(store), m); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := store.GetBaseline("m1", "content/v4"); b == nil {
+		t.Errorf("no baseline saved under content/v4; saved %v", store.saved)
+	}
+}
@@ -0,0 +1,112 @@
+package checker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// databaseOptions returns the monitor's database options, or defaults.
+func databaseOptions(monitor *model.Monitor) *model.DatabaseOptions {
+	if monitor.Options != nil && monitor.Options.Database != nil {
+		return monitor.Options.Database
+	}
+	return &model.DatabaseOptions{}
+}
+
+// ResolvePassword returns the password for a set of credentials, reading it
+// from the environment or a file when the monitor references one. Only the
+// variables and files listed in the node's secrets config can be read.
+func ResolvePassword(c model.Credentials) (string, error) {
+	switch {
+	case c.PasswordEnv != "":
+		if err := secretEnvAllowed(c.PasswordEnv); err != nil {
+			return "", err
+		}
+		password, ok := os.LookupEnv(c.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %s is not set", c.PasswordEnv)
+		}
+		return password, nil
+	case c.PasswordFile != "":
+		path, err := secretFileAllowed(c.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading password file: %v", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return c.Password, nil
+}
+
+// databaseTLSConfig returns the client TLS config for a DBTLS* mode, or nil
+// for plaintext.
+func databaseTLSConfig(mode, host string) *tls.Config {
+	switch mode {
+	case model.DBTLSRequire:
+		return &tls.Config{ServerName: host, InsecureSkipVerify: true}
+	case model.DBTLSVerify:
+		return &tls.Config{ServerName: host}
+	}
+	return nil
+}
+
+// applyReplicationLag marks a replica degraded when it lags further than
+// the monitor allows.
+func applyReplicationLag(result *Result, opts *model.DatabaseOptions, lagSec float64) {
+	result.Details["replication_lag_seconds"] = lagSec
+	if opts.MaxLagSec > 0 && lagSec > opts.MaxLagSec && result.Status == model.StatusUp {
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("replication lag %.1fs exceeds %.0fs", lagSec, opts.MaxLagSec)
+	}
+}
+
+// ValidateDatabaseOptions checks settings shared by the postgres, mysql and
+// redis checkers.
+func ValidateDatabaseOptions(checkType model.CheckType, opts *model.DatabaseOptions) error {
+	switch opts.TLS {
+	case "", model.DBTLSDisable, model.DBTLSRequire, model.DBTLSVerify:
+	default:
+		return fmt.Errorf("tls must be disable, require or verify")
+	}
+	if err := validateCredentials(opts.Credentials); err != nil {
+		return err
+	}
+	if opts.MaxLagSec < 0 {
+		return fmt.Errorf("max_lag_seconds must not be negative")
+	}
+	if opts.MaxLagSec > 0 && !opts.Replication {
+		return fmt.Errorf("max_lag_seconds needs replication enabled")
+	}
+	if checkType == model.CheckRedis && opts.Database != "" {
+		if n, err := strconv.Atoi(opts.Database); err != nil || n < 0 {
+			return fmt.Errorf("database must be a DB index for redis")
+		}
+	}
+	return nil
+}
+
+// validateCredentials rejects ambiguous password sources.
+func validateCredentials(c model.Credentials) error {
+	set := 0
+	for _, v := range []string{c.Password, c.PasswordEnv, c.PasswordFile} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("set only one of password, password_env and password_file")
+	}
+	if c.PasswordFile != "" && !filepath.IsAbs(c.PasswordFile) {
+		return fmt.Errorf("password_file must be an absolute path")
+	}
+	return nil
+}
@@ -1,17 +1,70 @@
 package checker
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/miekg/dns"
 	"github.com/pingmesh/pingmesh/internal/model"
 )
 
+const (
+	defaultResolver = "8.8.8.8"
+	systemResolver  = "system"
+	resolvConfPath  = "/etc/resolv.conf"
+
+	// maxDoHResponse bounds the body read from a DoH server.
+	maxDoHResponse = 64 * 1024
+)
+
+// dnsRecordTypes are the record types DNS checks can query.
+var dnsRecordTypes = map[string]uint16{
+	"A":     dns.TypeA,
+	"AAAA":  dns.TypeAAAA,
+	"CNAME": dns.TypeCNAME,
+	"MX":    dns.TypeMX,
+	"TXT":   dns.TypeTXT,
+	"NS":    dns.TypeNS,
+	"SOA":   dns.TypeSOA,
+	"SRV":   dns.TypeSRV,
+	"CAA":   dns.TypeCAA,
+	"PTR":   dns.TypePTR,
+}
+
 // DNSChecker performs DNS resolution checks.
-type DNSChecker struct{}
+type DNSChecker struct {
+	baselines baselineSet // answer sets, for alert_on_change
+}
+
+// NewDNSChecker returns a DNS checker that keeps the answer sets of
+// alert_on_change monitors in bs.
+func NewDNSChecker(bs BaselineStore) *DNSChecker {
+	return &DNSChecker{baselines: baselineSet{store: bs}}
+}
+
+// resolverResult is the outcome of querying one resolver.
+type resolverResult struct {
+	Resolver      string   `json:"resolver"`
+	LatencyMS     float64  `json:"latency_ms"`
+	Rcode         string   `json:"rcode,omitempty"`
+	Authoritative bool     `json:"authoritative"`
+	DNSSEC        string   `json:"dnssec,omitempty"` // "secure", "signed" or "insecure"
+	Serial        uint32   `json:"soa_serial,omitempty"`
+	Answers       []string `json:"answers"`
+	Error         string   `json:"error,omitempty"`
+}
 
 func (c *DNSChecker) Type() model.CheckType {
 	return model.CheckDNS
@@ -20,100 +73,486 @@ func (c *DNSChecker) Type() model.CheckType {
 func (c *DNSChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
 	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
 
-	recordType := dns.TypeA
-	switch strings.ToUpper(monitor.DNSRecordType) {
-	case "AAAA":
-		recordType = dns.TypeAAAA
-	case "CNAME":
-		recordType = dns.TypeCNAME
-	case "MX":
-		recordType = dns.TypeMX
-	case "TXT":
-		recordType = dns.TypeTXT
+	opts := &model.DNSOptions{}
+	if monitor.Options != nil && monitor.Options.DNS != nil {
+		opts = monitor.Options.DNS
+	}
+	transport := opts.Transport
+	if transport == "" {
+		transport = model.DNSTransportUDP
 	}
 
-	target := monitor.Target
-	if !strings.HasSuffix(target, ".") {
-		target += "."
+	resolvers := opts.Resolvers
+	if len(resolvers) == 0 {
+		resolvers = []string{defaultResolver}
+		// Monitors created before resolvers were configurable used Port to
+		// mean "query the target itself"; keep them working.
+		if monitor.Port > 0 {
+			resolvers = []string{monitor.Target}
+		}
+	}
+
+	recordType, ok := dnsRecordTypes[strings.ToUpper(monitor.DNSRecordType)]
+	if !ok {
+		recordType = dns.TypeA
+	}
+
+	name := dns.Fqdn(monitor.Target)
+	if recordType == dns.TypePTR && net.ParseIP(monitor.Target) != nil {
+		name, _ = dns.ReverseAddr(monitor.Target)
 	}
 
 	m := new(dns.Msg)
-	m.SetQuestion(target, recordType)
-	m.RecursionDesired = true
+	m.SetQuestion(name, recordType)
+	m.RecursionDesired = !opts.Authoritative
+	if opts.DNSSEC {
+		m.SetEdns0(4096, true)
+		m.AuthenticatedData = true
+	}
 
-	server := "8.8.8.8:53"
-	if monitor.Port > 0 {
-		server = fmt.Sprintf("%s:%d", monitor.Target, monitor.Port)
-		// If port is set, the target is the DNS server, so we need the actual query target
-		// For simplicity in MVP, we use the target as both server and query
+	results := make([]resolverResult, len(resolvers))
+	var wg sync.WaitGroup
+	for i, resolver := range resolvers {
+		wg.Add(1)
+		go func(i int, resolver string) {
+			defer wg.Done()
+			results[i] = queryResolver(ctx, m, resolver, transport, monitor.Port, timeout)
+		}(i, resolver)
 	}
+	wg.Wait()
 
-	client := &dns.Client{
-		Timeout: timeout,
+	result := &Result{
+		Status:  model.StatusUp,
+		Details: map[string]any{"transport": transport},
 	}
 
-	start := time.Now()
-	resp, _, err := client.ExchangeContext(ctx, m, server)
-	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	var answers []string
+	seen := map[string]bool{}
+	var failures []string
+	for i := range results {
+		r := &results[i]
+		result.LatencyMS = max(result.LatencyMS, r.LatencyMS)
+
+		if r.Error == "" {
+			r.Error = evaluateResolver(r, recordType, monitor, opts)
+		}
+		if r.Error != "" {
+			failures = append(failures, r.Error)
+			if len(results) > 1 {
+				failures[len(failures)-1] = fmt.Sprintf("%s: %s", r.Resolver, r.Error)
+			}
+		}
+
+		for _, a := range r.Answers {
+			if !seen[a] {
+				seen[a] = true
+				answers = append(answers, a)
+			}
+		}
+	}
+
+	if opts.ConsistentSerial && recordType == dns.TypeSOA && len(failures) == 0 {
+		if msg := checkSerials(results); msg != "" {
+			failures = append(failures, msg)
+		}
+	}
+
+	result.Details["answers"] = answers
+	result.Details["answer_count"] = len(answers)
+	if len(results) == 1 {
+		result.Details["resolver"] = results[0].Resolver
+		result.Details["authoritative"] = results[0].Authoritative
+		if results[0].Rcode != "" {
+			result.Details["rcode"] = results[0].Rcode
+		}
+		if results[0].DNSSEC != "" {
+			result.Details["dnssec"] = results[0].DNSSEC
+		}
+		if results[0].Serial != 0 {
+			result.Details["soa_serial"] = results[0].Serial
+		}
+	} else {
+		result.Details["resolvers"] = results
+	}
+
+	// Only compare complete answer sets; a failed resolver would look like
+	// a change.
+	if opts.AlertOnChange && len(failures) == 0 {
+		if previous, changed := c.compareBaseline(monitor, answers); changed {
+			result.Details["answers_changed"] = true
+			result.Details["previous_answers"] = previous
+			failures = append(failures, fmt.Sprintf("answers changed from %v to %v", previous, answers))
+		}
+	}
+
+	if len(failures) > 0 {
+		result.Status = model.StatusDown
+		result.Error = strings.Join(failures, "; ")
+	}
+
+	return result, nil
+}
+
+// evaluateResolver checks a successful exchange against the monitor's
+// expectations and returns a failure message, or "" if it passed.
+func evaluateResolver(r *resolverResult, recordType uint16, monitor *model.Monitor, opts *model.DNSOptions) string {
+	if r.Rcode != dns.RcodeToString[dns.RcodeSuccess] {
+		return fmt.Sprintf("dns error: %s", r.Rcode)
+	}
+	if opts.Authoritative && !r.Authoritative {
+		return "answer is not authoritative"
+	}
+	if opts.DNSSEC {
+		// Authoritative servers sign but do not validate, so only recursive
+		// answers can carry the AD bit.
+		if opts.Authoritative && r.DNSSEC == "insecure" {
+			return "answer is not DNSSEC signed"
+		}
+		if !opts.Authoritative && r.DNSSEC != "secure" {
+			return "answer is not DNSSEC validated"
+		}
+	}
+
+	expected := opts.Expected
+	if len(expected) == 0 && monitor.DNSExpected != "" {
+		expected = []string{monitor.DNSExpected}
+	}
+	if len(expected) == 0 {
+		return ""
+	}
+	return matchAnswers(opts.Match, expected, r.Answers, recordType)
+}
+
+// matchAnswers compares answers with the expected values and returns a
+// failure message, or "" if they match.
+func matchAnswers(mode string, expected, answers []string, recordType uint16) string {
+	if mode == model.DNSMatchRegex {
+		for _, pattern := range expected {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Sprintf("invalid regex %q: %v", pattern, err)
+			}
+			if !slices.ContainsFunc(answers, re.MatchString) {
+				return fmt.Sprintf("no answer matches /%s/ in %v", pattern, answers)
+			}
+		}
+		return ""
+	}
+
+	got := make(map[string]bool, len(answers))
+	for _, a := range answers {
+		got[normalizeAnswer(a, recordType)] = true
+	}
+	var missing []string
+	want := make(map[string]bool, len(expected))
+	for _, e := range expected {
+		n := normalizeAnswer(e, recordType)
+		want[n] = true
+		if !got[n] {
+			missing = append(missing, e)
+		}
+	}
+
+	switch mode {
+	case model.DNSMatchAll:
+		if len(missing) > 0 {
+			return fmt.Sprintf("expected answers %q not found in %v", missing, answers)
+		}
+	case model.DNSMatchExact:
+		var extra []string
+		for _, a := range answers {
+			if !want[normalizeAnswer(a, recordType)] {
+				extra = append(extra, a)
+			}
+		}
+		if len(missing) > 0 || len(extra) > 0 {
+			return fmt.Sprintf("answers %v do not equal expected set %q", answers, expected)
+		}
+	default:
+		if len(missing) == len(expected) {
+			if len(expected) == 1 {
+				return fmt.Sprintf("expected answer %q not found in %v", expected[0], answers)
+			}
+			return fmt.Sprintf("none of the expected answers %q found in %v", expected, answers)
+		}
+	}
+	return ""
+}
+
+// normalizeAnswer makes names compare case-insensitively and with or
+// without the trailing dot. TXT data is compared as-is.
+func normalizeAnswer(s string, recordType uint16) string {
+	if recordType == dns.TypeTXT {
+		return s
+	}
+	return strings.TrimSuffix(strings.ToLower(s), ".")
+}
 
+// checkSerials reports resolvers that returned a different SOA serial from
+// the first one.
+func checkSerials(results []resolverResult) string {
+	var mismatched []string
+	for _, r := range results[1:] {
+		if r.Serial != results[0].Serial {
+			mismatched = append(mismatched, fmt.Sprintf("%s=%d", r.Resolver, r.Serial))
+		}
+	}
+	if len(mismatched) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("SOA serial mismatch: %s=%d, %s", results[0].Resolver, results[0].Serial, strings.Join(mismatched, ", "))
+}
+
+// compareBaseline records the first answer set seen for a monitor and
+// reports whether answers differ from it. The baseline stays until the
+// monitor's name or record type changes or its baseline is accepted again,
+// so changed answers keep the monitor down until someone accepts them.
+func (c *DNSChecker) compareBaseline(monitor *model.Monitor, answers []string) ([]string, bool) {
+	current := slices.Clone(answers)
+	slices.Sort(current)
+
+	source := monitor.Target + " " + strings.ToUpper(monitor.DNSRecordType)
+	var base []string
+	if !c.baselines.get(monitor, "dns_answers", source, &base) {
+		c.baselines.set(monitor, "dns_answers", source, current)
+		return nil, false
+	}
+	if slices.Equal(base, current) {
+		return nil, false
+	}
+	return base, true
+}
+
+// queryResolver sends m to a single resolver over the given transport.
+func queryResolver(ctx context.Context, m *dns.Msg, resolver, transport string, port int, timeout time.Duration) resolverResult {
+	r := resolverResult{Resolver: resolver}
+
+	address, err := resolverAddress(resolver, transport, port)
 	if err != nil {
-		return &Result{
-			Status:    model.StatusDown,
-			LatencyMS: latency,
-			Error:     fmt.Sprintf("dns query failed: %v", err),
-		}, nil
+		r.Error = err.Error()
+		return r
 	}
+	r.Resolver = address
 
-	if resp.Rcode != dns.RcodeSuccess {
-		return &Result{
-			Status:    model.StatusDown,
-			LatencyMS: latency,
-			Error:     fmt.Sprintf("dns error: %s", dns.RcodeToString[resp.Rcode]),
-			Details: map[string]any{
-				"rcode": dns.RcodeToString[resp.Rcode],
-			},
-		}, nil
+	start := time.Now()
+	var resp *dns.Msg
+	if transport == model.DNSTransportHTTPS {
+		resp, err = exchangeDoH(ctx, m, address, timeout)
+	} else {
+		resp, err = exchangeDNS(ctx, m, address, transport, timeout)
 	}
+	r.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
 
-	var answers []string
+	if err != nil {
+		r.Error = fmt.Sprintf("dns query failed: %v", err)
+		return r
+	}
+
+	r.Rcode = dns.RcodeToString[resp.Rcode]
+	r.Authoritative = resp.Authoritative
+	r.DNSSEC = "insecure"
+	if resp.AuthenticatedData {
+		r.DNSSEC = "secure"
+	}
 	for _, rr := range resp.Answer {
 		switch v := rr.(type) {
-		case *dns.A:
-			answers = append(answers, v.A.String())
-		case *dns.AAAA:
-			answers = append(answers, v.AAAA.String())
-		case *dns.CNAME:
-			answers = append(answers, v.Target)
-		case *dns.MX:
-			answers = append(answers, fmt.Sprintf("%d %s", v.Preference, v.Mx))
-		case *dns.TXT:
-			answers = append(answers, strings.Join(v.Txt, " "))
+		case *dns.RRSIG:
+			if r.DNSSEC == "insecure" {
+				r.DNSSEC = "signed"
+			}
+		case *dns.SOA:
+			r.Serial = v.Serial
+		}
+		if answer, ok := formatAnswer(rr); ok {
+			r.Answers = append(r.Answers, answer)
 		}
 	}
+	return r
+}
 
-	result := &Result{
-		Status:    model.StatusUp,
-		LatencyMS: latency,
-		Details: map[string]any{
-			"answers":      answers,
-			"answer_count": len(answers),
-		},
-	}
-
-	// Check expected answer if configured
-	if monitor.DNSExpected != "" {
-		found := false
-		for _, answer := range answers {
-			if answer == monitor.DNSExpected {
-				found = true
-				break
+func formatAnswer(rr dns.RR) (string, bool) {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String(), true
+	case *dns.AAAA:
+		return v.AAAA.String(), true
+	case *dns.CNAME:
+		return v.Target, true
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", v.Preference, v.Mx), true
+	case *dns.TXT:
+		return strings.Join(v.Txt, " "), true
+	case *dns.NS:
+		return v.Ns, true
+	case *dns.SOA:
+		return fmt.Sprintf("%s %s %d", v.Ns, v.Mbox, v.Serial), true
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target), true
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %s", v.Flag, v.Tag, v.Value), true
+	case *dns.PTR:
+		return v.Ptr, true
+	}
+	return "", false
+}
+
+// exchangeDNS queries a resolver over UDP, TCP or DoT. Truncated UDP
+// responses are retried over TCP.
+func exchangeDNS(ctx context.Context, m *dns.Msg, address, transport string, timeout time.Duration) (*dns.Msg, error) {
+	client := &dns.Client{Timeout: timeout}
+	switch transport {
+	case model.DNSTransportTCP:
+		client.Net = "tcp"
+	case model.DNSTransportTLS:
+		host, _, _ := net.SplitHostPort(address)
+		client.Net = "tcp-tls"
+		client.TLSConfig = &tls.Config{ServerName: host}
+	}
+
+	resp, _, err := client.ExchangeContext(ctx, m, address)
+	if err == nil && resp.Truncated && client.Net == "" {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(ctx, m, address)
+	}
+	return resp, err
+}
+
+// exchangeDoH sends m as an RFC 8484 POST request.
+func exchangeDoH(ctx context.Context, m *dns.Msg, endpoint string, timeout time.Duration) (*dns.Msg, error) {
+	// RFC 8484 recommends ID 0 so responses are cache friendly.
+	q := m.Copy()
+	q.Id = 0
+	packed, err := q.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	req.Header.Set("User-Agent", "PingMesh/1.0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH server returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponse))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(body); err != nil {
+		return nil, fmt.Errorf("invalid DoH response: %v", err)
+	}
+	return msg, nil
+}
+
+// resolverAddress turns a configured resolver into a dialable host:port, or
+// a URL for DoH. port, if set, is used for resolvers without their own.
+func resolverAddress(resolver, transport string, port int) (string, error) {
+	if resolver == systemResolver {
+		if transport == model.DNSTransportHTTPS {
+			return "", fmt.Errorf("the system resolver cannot be used with DoH")
+		}
+		cfg, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return "", fmt.Errorf("reading system resolver: %v", err)
+		}
+		if len(cfg.Servers) == 0 {
+			return "", fmt.Errorf("no nameservers in %s", resolvConfPath)
+		}
+		return net.JoinHostPort(cfg.Servers[0], cfg.Port), nil
+	}
+
+	if transport == model.DNSTransportHTTPS {
+		if strings.HasPrefix(resolver, "https://") {
+			u, err := url.Parse(resolver)
+			if err != nil || u.Host == "" {
+				return "", fmt.Errorf("invalid DoH URL %q", resolver)
 			}
+			return resolver, nil
 		}
-		if !found {
-			result.Status = model.StatusDown
-			result.Error = fmt.Sprintf("expected answer %q not found in %v", monitor.DNSExpected, answers)
+		host := resolver
+		if port > 0 {
+			host = net.JoinHostPort(strings.Trim(resolver, "[]"), strconv.Itoa(port))
 		}
+		return "https://" + host + "/dns-query", nil
 	}
 
-	return result, nil
+	if strings.Contains(resolver, "://") {
+		return "", fmt.Errorf("resolver %q: URLs are only valid for the https transport", resolver)
+	}
+	if _, _, err := net.SplitHostPort(resolver); err == nil {
+		return resolver, nil
+	}
+
+	if port == 0 {
+		port = 53
+		if transport == model.DNSTransportTLS {
+			port = 853
+		}
+	}
+	return net.JoinHostPort(strings.Trim(resolver, "[]"), strconv.Itoa(port)), nil
+}
+
+// ValidateDNSRecordType reports whether DNS checks can query recordType.
+// An empty type means A.
+func ValidateDNSRecordType(recordType string) error {
+	if recordType == "" {
+		return nil
+	}
+	if _, ok := dnsRecordTypes[strings.ToUpper(recordType)]; !ok {
+		return fmt.Errorf("unsupported record type %q", recordType)
+	}
+	return nil
+}
+
+// ValidateDNSOptions checks the transport, resolver list and match settings
+// without contacting any resolver.
+func ValidateDNSOptions(opts *model.DNSOptions) error {
+	switch opts.Match {
+	case "", model.DNSMatchAny, model.DNSMatchAll, model.DNSMatchExact:
+	case model.DNSMatchRegex:
+		for _, pattern := range opts.Expected {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid regex %q: %w", pattern, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown match mode %q", opts.Match)
+	}
+
+	switch opts.Transport {
+	case "", model.DNSTransportUDP, model.DNSTransportTCP, model.DNSTransportTLS, model.DNSTransportHTTPS:
+	default:
+		return fmt.Errorf("unknown transport %q", opts.Transport)
+	}
+	for _, resolver := range opts.Resolvers {
+		if resolver == "" {
+			return fmt.Errorf("empty resolver")
+		}
+		if resolver == systemResolver {
+			if opts.Transport == model.DNSTransportHTTPS {
+				return fmt.Errorf("the system resolver cannot be used with DoH")
+			}
+			continue
+		}
+		if _, err := resolverAddress(resolver, opts.Transport, 0); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,395 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// serveDNS runs a DNS server on a local UDP or TCP port and returns its
+// address.
+func serveDNS(t *testing.T, network string, handler dns.HandlerFunc) string {
+	t.Helper()
+	srv := &dns.Server{Net: network, Handler: handler}
+	started := make(chan struct{})
+	srv.NotifyStartedFunc = func() { close(started) }
+
+	if network == "udp" {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.PacketConn = pc
+	} else {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Listener = ln
+	}
+	go srv.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { srv.Shutdown() })
+
+	if srv.PacketConn != nil {
+		return srv.PacketConn.LocalAddr().String()
+	}
+	return srv.Listener.Addr().String()
+}
+
+// zoneHandler answers A queries for example.com with 192.0.2.1, flagging
+// the answer authoritative when the query does not ask for recursion.
+func zoneHandler(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = !r.RecursionDesired
+	if r.Question[0].Name == "example.com." && r.Question[0].Qtype == dns.TypeA {
+		rr, _ := dns.NewRR("example.com. 300 IN A 192.0.2.1")
+		m.Answer = append(m.Answer, rr)
+	} else {
+		m.Rcode = dns.RcodeNameError
+	}
+	w.WriteMsg(m)
+}
+
+func TestResolverAddress(t *testing.T) {
+	tests := []struct {
+		resolver  string
+		transport string
+		port      int
+		want      string
+		wantErr   bool
+	}{
+		{"1.1.1.1", "", 0, "1.1.1.1:53", false},
+		{"1.1.1.1", model.DNSTransportTCP, 0, "1.1.1.1:53", false},
+		{"1.1.1.1", model.DNSTransportTLS, 0, "1.1.1.1:853", false},
+		{"1.1.1.1", "", 5353, "1.1.1.1:5353", false},
+		{"1.1.1.1:5300", "", 5353, "1.1.1.1:5300", false},
+		{"2606:4700::1111", "", 0, "[2606:4700::1111]:53", false},
+		{"ns1.example.com", "", 0, "ns1.example.com:53", false},
+		{"https://dns.example/dns-query", model.DNSTransportHTTPS, 0, "https://dns.example/dns-query", false},
+		{"dns.example", model.DNSTransportHTTPS, 0, "https://dns.example/dns-query", false},
+		{"dns.example", model.DNSTransportHTTPS, 8443, "https://dns.example:8443/dns-query", false},
+		{"https://dns.example/dns-query", model.DNSTransportUDP, 0, "", true},
+		{"https://", model.DNSTransportHTTPS, 0, "", true},
+		{systemResolver, model.DNSTransportHTTPS, 0, "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolverAddress(tt.resolver, tt.transport, tt.port)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolverAddress(%q, %q, %d) error = %v, wantErr %v", tt.resolver, tt.transport, tt.port, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolverAddress(%q, %q, %d) = %q, want %q", tt.resolver, tt.transport, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDNSOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    model.DNSOptions
+		wantErr bool
+	}{
+		{"defaults", model.DNSOptions{}, false},
+		{"resolvers", model.DNSOptions{Resolvers: []string{"system", "1.1.1.1", "ns1.example.com:5353"}, Transport: "tcp"}, false},
+		{"doh url", model.DNSOptions{Resolvers: []string{"https://dns.example/dns-query"}, Transport: "https"}, false},
+		{"unknown transport", model.DNSOptions{Transport: "quic"}, true},
+		{"empty resolver", model.DNSOptions{Resolvers: []string{""}}, true},
+		{"system resolver over doh", model.DNSOptions{Resolvers: []string{"system"}, Transport: "https"}, true},
+		{"url without doh", model.DNSOptions{Resolvers: []string{"https://dns.example/dns-query"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateDNSOptions(&tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateDNSOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDNSCheckResolvers(t *testing.T) {
+	udp := serveDNS(t, "udp", zoneHandler)
+	tcp := serveDNS(t, "tcp", zoneHandler)
+
+	// A resolver that answers nothing at all.
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	silent := pc.LocalAddr().String()
+
+	tests := []struct {
+		name   string
+		target string
+		opts   model.DNSOptions
+		wantUp bool
+	}{
+		{"udp", "example.com", model.DNSOptions{Resolvers: []string{udp}}, true},
+		{"tcp", "example.com", model.DNSOptions{Resolvers: []string{tcp}, Transport: "tcp"}, true},
+		{"every resolver", "example.com", model.DNSOptions{Resolvers: []string{udp, udp}}, true},
+		{"nxdomain", "missing.example.com", model.DNSOptions{Resolvers: []string{udp}}, false},
+		{"one resolver fails", "example.com", model.DNSOptions{Resolvers: []string{udp, silent}}, false},
+		{"authoritative", "example.com", model.DNSOptions{Resolvers: []string{udp}, Authoritative: true}, true},
+		{"expected answer", "example.com", model.DNSOptions{Resolvers: []string{udp}, Expected: []string{"192.0.2.1"}}, true},
+		{"unexpected answer", "example.com", model.DNSOptions{Resolvers: []string{udp}, Expected: []string{"192.0.2.2"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{ID: "m1", Target: tt.target, DNSRecordType: "A", TimeoutMS: 500,
+				Options: &model.MonitorOptions{DNS: &tt.opts}}
+			result, err := NewDNSChecker(nil).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (result.Status == model.StatusUp) != tt.wantUp {
+				t.Errorf("status = %s (%s), want up %v", result.Status, result.Error, tt.wantUp)
+			}
+		})
+	}
+
+	// A recursive query to a server that only answers authoritatively for
+	// RD=0 queries must not pass an authoritative check.
+	recursive := serveDNS(t, "udp", func(w dns.ResponseWriter, r *dns.Msg) {
+		r.RecursionDesired = true
+		zoneHandler(w, r)
+	})
+	m := &model.Monitor{ID: "m1", Target: "example.com", TimeoutMS: 500,
+		Options: &model.MonitorOptions{DNS: &model.DNSOptions{Resolvers: []string{recursive}, Authoritative: true}}}
+	result, _ := NewDNSChecker(nil).Check(context.Background(), m)
+	if result.Status != model.StatusDown || !strings.Contains(result.Error, "not authoritative") {
+		t.Errorf("non-authoritative answer: status = %s (%s), want down", result.Status, result.Error)
+	}
+}
+
+func TestDNSCheckDoH(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		q := new(dns.Msg)
+		if err := q.Unpack(body); err != nil || q.Id != 0 {
+			http.Error(w, "bad message", http.StatusBadRequest)
+			return
+		}
+		m := new(dns.Msg)
+		m.SetReply(q)
+		rr, _ := dns.NewRR("example.com. 300 IN A 192.0.2.1")
+		m.Answer = append(m.Answer, rr)
+		packed, _ := m.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	defer srv.Close()
+
+	// DoH queries go through the default client, which must trust the
+	// test server's certificate.
+	defaultClient := http.DefaultClient
+	http.DefaultClient = srv.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	m := &model.Monitor{ID: "m1", Target: "example.com", TimeoutMS: 2000,
+		Options: &model.MonitorOptions{DNS: &model.DNSOptions{Resolvers: []string{srv.URL + "/dns-query"}, Transport: "https"}}}
+	result, err := NewDNSChecker(nil).Check(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != model.StatusUp {
+		t.Fatalf("status = %s (%s), want up", result.Status, result.Error)
+	}
+	if answers, _ := result.Details["answers"].([]string); len(answers) != 1 || answers[0] != "192.0.2.1" {
+		t.Errorf("answers = %v, want [192.0.2.1]", result.Details["answers"])
+	}
+}
+
+// memBaselines is an in-memory BaselineStore.
+type memBaselines struct {
+	mu    sync.Mutex
+	saved map[string]*model.Baseline
+}
+
+func (s *memBaselines) GetBaseline(monitorID, key string) (*model.Baseline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saved[monitorID+"/"+key], nil
+}
+
+func (s *memBaselines) SaveBaseline(b *model.Baseline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saved == nil {
+		s.saved = map[string]*model.Baseline{}
+	}
+	s.saved[b.MonitorID+"/"+b.Key] = b
+	return nil
+}
+
+func TestMatchAnswers(t *testing.T) {
+	answers := []string{"192.0.2.1", "192.0.2.2"}
+	tests := []struct {
+		name     string
+		mode     string
+		expected []string
+		answers  []string
+		rtype    uint16
+		match    bool
+	}{
+		{"any", "", []string{"192.0.2.2", "192.0.2.9"}, answers, dns.TypeA, true},
+		{"any none found", model.DNSMatchAny, []string{"192.0.2.9"}, answers, dns.TypeA, false},
+		{"all", model.DNSMatchAll, []string{"192.0.2.1", "192.0.2.2"}, answers, dns.TypeA, true},
+		{"all missing one", model.DNSMatchAll, []string{"192.0.2.1", "192.0.2.9"}, answers, dns.TypeA, false},
+		{"exact", model.DNSMatchExact, []string{"192.0.2.2", "192.0.2.1"}, answers, dns.TypeA, true},
+		{"exact with extra answer", model.DNSMatchExact, []string{"192.0.2.1"}, answers, dns.TypeA, false},
+		{"regex", model.DNSMatchRegex, []string{`^192\.0\.2\.\d+$`}, answers, dns.TypeA, true},
+		{"regex no match", model.DNSMatchRegex, []string{`^10\.`}, answers, dns.TypeA, false},
+		{"invalid regex", model.DNSMatchRegex, []string{`(`}, answers, dns.TypeA, false},
+		{"names ignore case and trailing dot", model.DNSMatchExact, []string{"NS1.Example.com"}, []string{"ns1.example.com."}, dns.TypeNS, true},
+		{"txt is case sensitive", model.DNSMatchAny, []string{"V=SPF1"}, []string{"v=spf1"}, dns.TypeTXT, false},
+	}
+	for _, tt := range tests {
+		if msg := matchAnswers(tt.mode, tt.expected, tt.answers, tt.rtype); (msg == "") != tt.match {
+			t.Errorf("%s: matchAnswers() = %q, want match %v", tt.name, msg, tt.match)
+		}
+	}
+}
+
+func TestCheckSerials(t *testing.T) {
+	tests := []struct {
+		serials []uint32
+		match   bool
+	}{
+		{[]uint32{2024010101}, true},
+		{[]uint32{2024010101, 2024010101, 2024010101}, true},
+		{[]uint32{2024010101, 2024010102, 2024010101}, false},
+	}
+	for _, tt := range tests {
+		var results []resolverResult
+		for i, s := range tt.serials {
+			results = append(results, resolverResult{Resolver: fmt.Sprintf("ns%d", i), Serial: s})
+		}
+		if msg := checkSerials(results); (msg == "") != tt.match {
+			t.Errorf("checkSerials(%v) = %q, want match %v", tt.serials, msg, tt.match)
+		}
+	}
+}
+
+func TestDNSCheckRecordTypes(t *testing.T) {
+	zone := func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		var rrs []string
+		switch r.Question[0].Qtype {
+		case dns.TypeMX:
+			rrs = []string{"example.com. 300 IN MX 10 mx1.example.com.", "example.com. 300 IN MX 20 mx2.example.com."}
+		case dns.TypeSOA:
+			rrs = []string{"example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300"}
+		case dns.TypeCAA:
+			rrs = []string{`example.com. 300 IN CAA 0 issue "letsencrypt.org"`}
+		}
+		for _, s := range rrs {
+			rr, _ := dns.NewRR(s)
+			m.Answer = append(m.Answer, rr)
+		}
+		w.WriteMsg(m)
+	}
+	resolver := serveDNS(t, "udp", zone)
+
+	check := func(recordType string, opts model.DNSOptions) *Result {
+		t.Helper()
+		opts.Resolvers = append([]string{resolver}, opts.Resolvers...)
+		m := &model.Monitor{ID: "m1", Target: "example.com", DNSRecordType: recordType, TimeoutMS: 500,
+			Options: &model.MonitorOptions{DNS: &opts}}
+		result, err := NewDNSChecker(nil).Check(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := check("MX", model.DNSOptions{Expected: []string{"10 mx1.example.com", "20 mx2.example.com"}, Match: model.DNSMatchExact})
+	if result.Status != model.StatusUp {
+		t.Errorf("MX exact set: status = %s (%s), want up", result.Status, result.Error)
+	}
+	result = check("CAA", model.DNSOptions{Expected: []string{`issue "?letsencrypt\.org`}, Match: model.DNSMatchRegex})
+	if result.Status != model.StatusUp {
+		t.Errorf("CAA regex: status = %s (%s), want up", result.Status, result.Error)
+	}
+
+	result = check("SOA", model.DNSOptions{Resolvers: []string{resolver}, ConsistentSerial: true})
+	if result.Status != model.StatusUp {
+		t.Errorf("consistent SOA serials: status = %s (%s), want up", result.Status, result.Error)
+	}
+	// A secondary that missed the last zone transfer.
+	lagging := serveDNS(t, "udp", func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		rr, _ := dns.NewRR("example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2023120101 7200 3600 1209600 300")
+		m.Answer = append(m.Answer, rr)
+		w.WriteMsg(m)
+	})
+	result = check("SOA", model.DNSOptions{Resolvers: []string{lagging}, ConsistentSerial: true})
+	if result.Status != model.StatusDown || !strings.Contains(result.Error, "serial mismatch") {
+		t.Errorf("lagging SOA serial: status = %s (%s), want down", result.Status, result.Error)
+	}
+}
+
+func TestDNSCheckAlertOnChange(t *testing.T) {
+	var answer atomic.Value
+	answer.Store("192.0.2.1")
+	resolver := serveDNS(t, "udp", func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		rr, _ := dns.NewRR("example.com. 300 IN A " + answer.Load().(string))
+		m.Answer = append(m.Answer, rr)
+		w.WriteMsg(m)
+	})
+
+	store := &memBaselines{}
+	m := &model.Monitor{ID: "m1", Target: "example.com", DNSRecordType: "A", TimeoutMS: 500,
+		Options: &model.MonitorOptions{DNS: &model.DNSOptions{Resolvers: []string{resolver}, AlertOnChange: true}}}
+	check := func(c *DNSChecker) *Result {
+		t.Helper()
+		result, err := c.Check(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := check(NewDNSChecker(store)); result.Status != model.StatusUp {
+		t.Fatalf("first check: status = %s (%s), want up while learning the baseline", result.Status, result.Error)
+	}
+
+	// The baseline outlives the checker, so a restarted node still sees
+	// the change.
+	answer.Store("192.0.2.66")
+	restarted := NewDNSChecker(store)
+	result := check(restarted)
+	if result.Status != model.StatusDown || result.Details["answers_changed"] != true {
+		t.Fatalf("changed answers: status = %s (%s), want down", result.Status, result.Error)
+	}
+	if result := check(restarted); result.Status != model.StatusDown {
+		t.Errorf("changed answers are reported until accepted, got %s", result.Status)
+	}
+
+	// Accepting the baseline learns the new answers.
+	m.BaselineAcceptedAt = 1
+	if result := check(restarted); result.Status != model.StatusUp {
+		t.Errorf("after accepting: status = %s (%s), want up", result.Status, result.Error)
+	}
+	if result := check(restarted); result.Status != model.StatusUp {
+		t.Errorf("accepted answers: status = %s (%s), want up", result.Status, result.Error)
+	}
+}
@@ -0,0 +1,440 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// rdapBootstrapURL is IANA's registry of RDAP servers by TLD (RFC 9224).
+	rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+	// rdapBootstrapTTL is how long the bootstrap registry is cached.
+	rdapBootstrapTTL = 24 * time.Hour
+
+	defaultDomainWarnDays = 30
+)
+
+// troubleStatuses are RDAP status flags, normalized, that mean the domain
+// has stopped resolving or is about to be lost.
+var troubleStatuses = []string{"serverhold", "clienthold", "pendingdelete", "redemptionperiod"}
+
+// gracePeriodStatuses come and go with registrations and renewals, so they
+// are left out of the learned status baseline.
+var gracePeriodStatuses = []string{"addperiod", "autorenewperiod", "renewperiod", "transferperiod"}
+
+// DomainChecker looks up domain registrations over RDAP and reports expiry,
+// registrar, status flags and nameservers.
+type DomainChecker struct {
+	bootstrapURL string      // default rdapBootstrapURL
+	baselines    baselineSet // nameservers and status flags first seen
+
+	mu        sync.Mutex
+	bootstrap map[string]string // TLD to RDAP base URL
+	fetched   time.Time
+}
+
+// NewDomainChecker returns a domain checker that keeps the nameservers and
+// status flags it first sees for each monitor in bs.
+func NewDomainChecker(bs BaselineStore) *DomainChecker {
+	return &DomainChecker{baselines: baselineSet{store: bs}}
+}
+
+func (c *DomainChecker) Type() model.CheckType {
+	return model.CheckDomain
+}
+
+// rdapDomain is the part of an RDAP domain response (RFC 9083) the check
+// uses.
+type rdapDomain struct {
+	LDHName string   `json:"ldhName"`
+	Status  []string `json:"status"`
+	Events  []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+type rdapEntity struct {
+	Roles []string `json:"roles"`
+	VCard []any    `json:"vcardArray"`
+}
+
+func (c *DomainChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := &model.DomainOptions{}
+	if monitor.Options != nil && monitor.Options.Domain != nil {
+		opts = monitor.Options.Domain
+	}
+
+	domain, err := RegistrableDomain(monitor.Target)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	base := opts.RDAPServer
+	if base == "" {
+		if base, err = c.rdapServer(ctx, domain); err != nil {
+			return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+		}
+	}
+
+	start := time.Now()
+	reg, code, err := fetchRDAPDomain(ctx, base, domain)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return &Result{
+			Status:     model.StatusDown,
+			LatencyMS:  latency,
+			StatusCode: code,
+			Error:      err.Error(),
+		}, nil
+	}
+
+	result := &Result{
+		Status:     model.StatusUp,
+		LatencyMS:  latency,
+		StatusCode: code,
+		Details: map[string]any{
+			"domain":      domain,
+			"rdap_server": base,
+		},
+	}
+
+	status := make([]string, len(reg.Status))
+	for i, s := range reg.Status {
+		status[i] = strings.ToLower(strings.TrimSpace(s))
+	}
+	result.Details["status"] = status
+
+	var nameservers []string
+	for _, ns := range reg.Nameservers {
+		if name := normalizeNameserver(ns.LDHName); name != "" {
+			nameservers = append(nameservers, name)
+		}
+	}
+	slices.Sort(nameservers)
+	result.Details["nameservers"] = nameservers
+
+	if registrar := findRegistrar(reg.Entities); registrar != "" {
+		result.Details["registrar"] = registrar
+	}
+
+	var expires time.Time
+	for _, ev := range reg.Events {
+		t, err := time.Parse(time.RFC3339, ev.Date)
+		if err != nil {
+			continue
+		}
+		switch ev.Action {
+		case "expiration":
+			expires = t
+			result.Details["expires_at"] = t.UTC().Format(time.RFC3339)
+		case "registration":
+			result.Details["registered_at"] = t.UTC().Format(time.RFC3339)
+		case "last changed":
+			result.Details["last_changed_at"] = t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	// Problems with the registration itself outrank the expiry warning.
+	for _, s := range status {
+		if slices.Contains(troubleStatuses, normalizeStatus(s)) {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("domain status is %q", s)
+			return result, nil
+		}
+	}
+
+	// Changes are found against the explicit lists, or else against the
+	// values each node first saw.
+	expectedStatus, observedStatus := opts.Status, status
+	if len(expectedStatus) == 0 {
+		observedStatus = slices.DeleteFunc(slices.Clone(status), func(s string) bool {
+			return slices.Contains(gracePeriodStatuses, normalizeStatus(s))
+		})
+		expectedStatus = c.baseline(monitor, "domain_status", domain, observedStatus)
+	}
+	if missing, extra := diffSets(expectedStatus, observedStatus, normalizeStatus); len(missing)+len(extra) > 0 {
+		result.Status = model.StatusDown
+		result.Error = "status changed: " + describeDiff(missing, extra)
+		result.Details["previous_status"] = expectedStatus
+		return result, nil
+	}
+	expectedNameservers := opts.Nameservers
+	if len(expectedNameservers) == 0 {
+		expectedNameservers = c.baseline(monitor, "domain_nameservers", domain, nameservers)
+	}
+	if missing, extra := diffSets(expectedNameservers, nameservers, normalizeNameserver); len(missing)+len(extra) > 0 {
+		result.Status = model.StatusDown
+		result.Error = "nameservers changed: " + describeDiff(missing, extra)
+		result.Details["previous_nameservers"] = expectedNameservers
+		return result, nil
+	}
+
+	if expires.IsZero() {
+		result.Details["expiry_unknown"] = true
+		return result, nil
+	}
+	warnDays := opts.ExpiryWarnDays
+	if warnDays <= 0 {
+		warnDays = defaultDomainWarnDays
+	}
+	daysLeft := time.Until(expires).Hours() / 24
+	result.Details["days_left"] = int(daysLeft)
+	switch {
+	case daysLeft < 0:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("domain expired %d days ago", -int(daysLeft))
+	case daysLeft < float64(warnDays):
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("domain expires in %d days", int(daysLeft))
+	}
+	return result, nil
+}
+
+// baseline returns the monitor's values under key, learning current as the
+// baseline when there is none for domain yet.
+func (c *DomainChecker) baseline(monitor *model.Monitor, key, domain string, current []string) []string {
+	var base []string
+	if !c.baselines.get(monitor, key, domain, &base) {
+		c.baselines.set(monitor, key, domain, current)
+		return current
+	}
+	return base
+}
+
+// fetchRDAPDomain queries an RDAP server for a domain and returns the
+// response and its HTTP status code.
+func fetchRDAPDomain(ctx context.Context, base, domain string) (*rdapDomain, int, error) {
+	u := strings.TrimSuffix(base, "/") + "/domain/" + url.PathEscape(domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %v", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	req.Header.Set("User-Agent", "PingMesh/1.0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("RDAP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, resp.StatusCode, fmt.Errorf("%s is not registered (RDAP 404)", domain)
+	case resp.StatusCode != http.StatusOK:
+		return nil, resp.StatusCode, fmt.Errorf("RDAP server returned HTTP %d", resp.StatusCode)
+	}
+
+	var reg rdapDomain
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&reg); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("decoding RDAP response: %v", err)
+	}
+	return &reg, resp.StatusCode, nil
+}
+
+// rdapServer returns the RDAP base URL for a domain's TLD from the IANA
+// bootstrap registry, fetching it when the cached copy is stale. A stale
+// copy is used if the refresh fails.
+func (c *DomainChecker) rdapServer(ctx context.Context, domain string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.bootstrap == nil || time.Since(c.fetched) > rdapBootstrapTTL {
+		bootstrapURL := c.bootstrapURL
+		if bootstrapURL == "" {
+			bootstrapURL = rdapBootstrapURL
+		}
+		services, err := fetchRDAPBootstrap(ctx, bootstrapURL)
+		switch {
+		case err == nil:
+			c.bootstrap, c.fetched = services, time.Now()
+		case c.bootstrap == nil:
+			return "", fmt.Errorf("loading RDAP bootstrap registry: %v", err)
+		}
+	}
+
+	for name := domain; name != ""; {
+		if base, ok := c.bootstrap[name]; ok {
+			return base, nil
+		}
+		_, rest, found := strings.Cut(name, ".")
+		if !found {
+			break
+		}
+		name = rest
+	}
+	return "", fmt.Errorf("no RDAP server is registered for %s; set options.domain.rdap_server", domain)
+}
+
+// fetchRDAPBootstrap downloads a DNS bootstrap file in the IANA format and
+// maps each listed TLD to its first HTTPS server.
+func fetchRDAPBootstrap(ctx context.Context, bootstrapURL string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bootstrapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var file struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]string)
+	for _, svc := range file.Services {
+		if len(svc) != 2 {
+			continue
+		}
+		var base string
+		for _, u := range svc[1] {
+			if base == "" || strings.HasPrefix(u, "https://") && !strings.HasPrefix(base, "https://") {
+				base = u
+			}
+		}
+		if base == "" {
+			continue
+		}
+		for _, tld := range svc[0] {
+			services[strings.ToLower(tld)] = base
+		}
+	}
+	return services, nil
+}
+
+// findRegistrar returns the name on the registrar entity's vCard.
+func findRegistrar(entities []rdapEntity) string {
+	for _, e := range entities {
+		if !slices.Contains(e.Roles, "registrar") {
+			continue
+		}
+		// vcardArray is ["vcard", [[name, params, type, value], ...]].
+		if len(e.VCard) == 2 {
+			props, _ := e.VCard[1].([]any)
+			for _, p := range props {
+				prop, _ := p.([]any)
+				if len(prop) == 4 && prop[0] == "fn" {
+					if name, ok := prop[3].(string); ok && name != "" {
+						return name
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// RegistrableDomain returns the domain a registrar holds for a target, such
+// as example.co.uk for https://www.example.co.uk/path, in ASCII form.
+func RegistrableDomain(target string) (string, error) {
+	host := target
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid target URL: %w", err)
+		}
+		host = u.Hostname()
+	}
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %v", host, err)
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		return "", fmt.Errorf("%q has no registrable domain: %v", host, err)
+	}
+	return domain, nil
+}
+
+// normalizeNameserver lowercases a host name and drops the trailing dot.
+func normalizeNameserver(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// normalizeStatus makes RDAP ("client transfer prohibited") and EPP
+// ("clientTransferProhibited") spellings of a status compare equal.
+func normalizeStatus(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "")
+}
+
+// diffSets compares expected and observed values after normalizing both,
+// and returns the expected values that are missing and the observed values
+// that were not expected.
+func diffSets(expected, observed []string, normalize func(string) string) (missing, extra []string) {
+	want := make(map[string]bool, len(expected))
+	for _, v := range expected {
+		want[normalize(v)] = true
+	}
+	got := make(map[string]bool, len(observed))
+	for _, v := range observed {
+		got[normalize(v)] = true
+		if !want[normalize(v)] {
+			extra = append(extra, v)
+		}
+	}
+	for _, v := range expected {
+		if !got[normalize(v)] {
+			missing = append(missing, v)
+		}
+	}
+	return missing, extra
+}
+
+func describeDiff(missing, extra []string) string {
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(extra, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ValidateDomainOptions checks a domain monitor's target and options.
+func ValidateDomainOptions(target string, opts *model.DomainOptions) error {
+	if _, err := RegistrableDomain(target); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+	if opts == nil {
+		return nil
+	}
+	if opts.ExpiryWarnDays < 0 {
+		return fmt.Errorf("options.domain.expiry_warn_days: must not be negative")
+	}
+	if opts.RDAPServer != "" {
+		u, err := url.Parse(opts.RDAPServer)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("options.domain.rdap_server: must be an http or https URL")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// rdapRecord returns an RDAP domain response for domain, expiring after
+// expires.
+func rdapRecord(domain string, expires time.Duration, status ...string) map[string]any {
+	return map[string]any{
+		"ldhName": strings.ToUpper(domain),
+		"status":  status,
+		"events": []map[string]string{
+			{"eventAction": "registration", "eventDate": "2001-05-04T10:00:00Z"},
+			{"eventAction": "expiration", "eventDate": time.Now().Add(expires).UTC().Format(time.RFC3339)},
+		},
+		"nameservers": []map[string]string{{"ldhName": "NS2.EXAMPLE.NET."}, {"ldhName": "ns1.example.net"}},
+		"entities": []map[string]any{
+			{"roles": []string{"registrant"}, "vcardArray": []any{"vcard", []any{[]any{"fn", map[string]any{}, "text", "Example Inc."}}}},
+			{"roles": []string{"registrar"}, "vcardArray": []any{"vcard", []any{
+				[]any{"version", map[string]any{}, "text", "4.0"},
+				[]any{"fn", map[string]any{}, "text", "Example Registrar, LLC"},
+			}}},
+		},
+	}
+}
+
+// rdapStandIn serves domain records under /domain/ and counts requests to
+// /bootstrap.json, which lists the server itself for .com and .test.
+func rdapStandIn(t *testing.T, records map[string]map[string]any) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var bootstraps atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bootstrap.json" {
+			bootstraps.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{
+				"services": [][][]string{{{"com", "test"}, {srv.URL + "/rdap/"}}},
+			})
+			return
+		}
+		record, ok := records[strings.TrimPrefix(r.URL.Path, "/rdap/domain/")]
+		if !ok || r.Header.Get("Accept") != "application/rdap+json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(record)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bootstraps
+}
+
+func TestDomainCheck(t *testing.T) {
+	day := 24 * time.Hour
+	srv, bootstraps := rdapStandIn(t, map[string]map[string]any{
+		"example.com":  rdapRecord("example.com", 200*day, "client transfer prohibited", "active"),
+		"expiring.com": rdapRecord("expiring.com", 10*day, "active"),
+		"expired.com":  rdapRecord("expired.com", -3*day, "active"),
+		"held.com":     rdapRecord("held.com", 200*day, "client hold"),
+	})
+	c := &DomainChecker{bootstrapURL: srv.URL + "/bootstrap.json"}
+
+	tests := []struct {
+		name      string
+		target    string
+		opts      *model.DomainOptions
+		want      model.CheckStatus
+		wantError string
+	}{
+		{"registered", "https://www.example.com/login", nil, model.StatusUp, ""},
+		{"expected status and nameservers", "example.com", &model.DomainOptions{
+			Status:      []string{"clientTransferProhibited", "active"},
+			Nameservers: []string{"ns1.example.net.", "NS2.example.net"},
+		}, model.StatusUp, ""},
+		{"status changed", "example.com", &model.DomainOptions{Status: []string{"active"}}, model.StatusDown,
+			"status changed: unexpected client transfer prohibited"},
+		{"nameservers changed", "example.com", &model.DomainOptions{Nameservers: []string{"ns1.example.net", "ns3.example.net"}}, model.StatusDown,
+			"nameservers changed: missing ns3.example.net; unexpected ns2.example.net"},
+		{"expiring", "expiring.com", nil, model.StatusDegraded, "expires in"},
+		{"outside custom warning", "expiring.com", &model.DomainOptions{ExpiryWarnDays: 7}, model.StatusUp, ""},
+		{"expired", "expired.com", nil, model.StatusDown, "expired"},
+		{"on hold", "held.com", nil, model.StatusDown, `"client hold"`},
+		{"not registered", "unregistered.com", nil, model.StatusDown, "not registered"},
+		{"no rdap server for the tld", "example.org", nil, model.StatusDown, "no RDAP server"},
+		{"explicit rdap server", "example.org", &model.DomainOptions{RDAPServer: srv.URL + "/rdap"}, model.StatusDown, "not registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: tt.target, Options: &model.MonitorOptions{Domain: tt.opts}}
+			result, err := c.Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+		})
+	}
+	if n := bootstraps.Load(); n != 1 {
+		t.Errorf("bootstrap registry fetched %d times, want once", n)
+	}
+
+	result, _ := c.Check(context.Background(), &model.Monitor{Target: "example.com"})
+	want := map[string]any{
+		"domain":        "example.com",
+		"registrar":     "Example Registrar, LLC",
+		"nameservers":   []string{"ns1.example.net", "ns2.example.net"},
+		"status":        []string{"client transfer prohibited", "active"},
+		"registered_at": "2001-05-04T10:00:00Z",
+	}
+	for key, v := range want {
+		if !reflect.DeepEqual(result.Details[key], v) {
+			t.Errorf("details[%s] = %v, want %v", key, result.Details[key], v)
+		}
+	}
+}
+
+func TestDomainCheckBaseline(t *testing.T) {
+	var mu sync.Mutex
+	record := rdapRecord("example.com", 200*24*time.Hour, "client transfer prohibited")
+	serve := func(status []string, nameservers ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		record["status"] = status
+		ns := make([]map[string]string, len(nameservers))
+		for i, name := range nameservers {
+			ns[i] = map[string]string{"ldhName": name}
+		}
+		record["nameservers"] = ns
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(record)
+	}))
+	defer srv.Close()
+
+	store := &memBaselines{}
+	m := &model.Monitor{ID: "m1", Target: "example.com",
+		Options: &model.MonitorOptions{Domain: &model.DomainOptions{RDAPServer: srv.URL}}}
+	locked := []string{"client transfer prohibited"}
+
+	steps := []struct {
+		name        string
+		status      []string
+		nameservers []string
+		restart     bool
+		accept      int64
+		want        model.CheckStatus
+		wantError   string
+	}{
+		{"learns the baseline", locked, []string{"ns1.example.net", "ns2.example.net"}, false, 0, model.StatusUp, ""},
+		{"same values in another order", locked, []string{"NS2.example.net.", "ns1.example.net"}, false, 0, model.StatusUp, ""},
+		{"renewal grace period", append([]string{"auto renew period"}, locked...), []string{"ns1.example.net", "ns2.example.net"},
+			false, 0, model.StatusUp, ""},
+		{"nameserver replaced", locked, []string{"ns1.example.net", "ns1.attacker.example"}, false, 0, model.StatusDown,
+			"nameservers changed: missing ns2.example.net; unexpected ns1.attacker.example"},
+		{"still changed after a restart", locked, []string{"ns1.example.net", "ns1.attacker.example"}, true, 0, model.StatusDown,
+			"nameservers changed"},
+		{"transfer lock removed", nil, []string{"ns1.example.net", "ns2.example.net"}, false, 0, model.StatusDown,
+			"status changed: missing client transfer prohibited"},
+		{"accepted as the new baseline", nil, []string{"ns1.example.net", "ns1.attacker.example"}, false, 1760000000000, model.StatusUp, ""},
+	}
+	c := NewDomainChecker(store)
+	for _, step := range steps {
+		if step.restart {
+			c = NewDomainChecker(store)
+		}
+		if step.accept != 0 {
+			m.BaselineAcceptedAt = step.accept
+		}
+		serve(step.status, step.nameservers...)
+		result, err := c.Check(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != step.want || !strings.Contains(result.Error, step.wantError) {
+			t.Errorf("%s: result = %s (%s), want %s (%s)", step.name, result.Status, result.Error, step.want, step.wantError)
+		}
+	}
+
+	// Explicit lists override the baseline.
+	m.Options.Domain.Nameservers = []string{"ns1.example.net", "ns2.example.net"}
+	serve(nil, "ns1.example.net", "ns2.example.net")
+	if result, _ := c.Check(context.Background(), m); result.Status != model.StatusUp {
+		t.Errorf("with expected nameservers: result = %s (%s), want up", result.Status, result.Error)
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{"example.com", "example.com", false},
+		{"www.Example.COM.", "example.com", false},
+		{"https://shop.example.co.uk/cart", "example.co.uk", false},
+		{"bücher.example", "xn--bcher-kva.example", false},
+		{"co.uk", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := RegistrableDomain(tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RegistrableDomain(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDomainOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		opts    *model.DomainOptions
+		wantErr bool
+	}{
+		{"no options", "example.com", nil, false},
+		{"rdap server", "example.com", &model.DomainOptions{RDAPServer: "https://rdap.example.net/"}, false},
+		{"public suffix", "com", nil, true},
+		{"negative warning", "example.com", &model.DomainOptions{ExpiryWarnDays: -1}, true},
+		{"rdap server without scheme", "example.com", &model.DomainOptions{RDAPServer: "rdap.example.net"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateDomainOptions(tt.target, tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateDomainOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// defaultExecOutput caps the stdout and stderr kept from one exec check.
+const defaultExecOutput = 64 << 10
+
+// nagiosStates names the Nagios plugin exit codes 0-3.
+var nagiosStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// ExecChecker runs local executables, such as Nagios plugins, as checks.
+// Only commands matching the node's allow patterns are run.
+type ExecChecker struct {
+	allow     []string
+	maxOutput int
+}
+
+// NewExecChecker returns an exec checker that runs only commands matching
+// one of the allow glob patterns. maxOutput caps the bytes kept from each
+// of stdout and stderr; zero uses the default.
+func NewExecChecker(allow []string, maxOutput int) *ExecChecker {
+	if maxOutput <= 0 {
+		maxOutput = defaultExecOutput
+	}
+	return &ExecChecker{allow: allow, maxOutput: maxOutput}
+}
+
+func (c *ExecChecker) Type() model.CheckType {
+	return model.CheckExec
+}
+
+// execOutput is the JSON a command may print on stdout instead of Nagios
+// plugin output. The exit code still decides the status.
+type execOutput struct {
+	Message    string         `json:"message"`
+	LatencyMS  *float64       `json:"latency_ms"`
+	StatusCode int            `json:"status_code"`
+	Details    map[string]any `json:"details"`
+}
+
+// perfValue is one Nagios performance data item.
+type perfValue struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+	Warn  string  `json:"warn,omitempty"`
+	Crit  string  `json:"crit,omitempty"`
+	Min   string  `json:"min,omitempty"`
+	Max   string  `json:"max,omitempty"`
+}
+
+// Check runs the command with the monitor as JSON on stdin and its main
+// fields in PINGMESH_* environment variables. Exit codes follow Nagios:
+// 0 is up, 1 degraded, 2 and 3 down.
+func (c *ExecChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	if monitor.Options == nil || monitor.Options.Exec == nil || monitor.Options.Exec.Command == "" {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  "exec checks need options.exec.command",
+		}, nil
+	}
+	opts := monitor.Options.Exec
+
+	command, err := c.allowed(opts.Command)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	if err := validateExecEnv(opts.Env); err != nil {
+		return &Result{Status: model.StatusDown, Error: "env: " + err.Error()}, nil
+	}
+
+	stdin, err := json.Marshal(execStdinMonitor(monitor))
+	if err != nil {
+		return nil, fmt.Errorf("encoding monitor: %w", err)
+	}
+
+	stdout := &cappedBuffer{max: c.maxOutput}
+	stderr := &cappedBuffer{max: c.maxOutput}
+	cmd := exec.CommandContext(ctx, command, execArgs(opts.Args, monitor)...)
+	cmd.Env = execEnv(monitor, opts.Env)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	configureExecCmd(cmd)
+
+	start := time.Now()
+	err = cmd.Run()
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+
+	if ctx.Err() != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     fmt.Sprintf("%s did not finish within %dms", filepath.Base(command), monitor.TimeoutMS),
+		}, nil
+	}
+
+	code := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return &Result{
+				Status:    model.StatusDown,
+				LatencyMS: latency,
+				Error:     fmt.Sprintf("running %s: %v", command, err),
+			}, nil
+		}
+		code = exitErr.ExitCode()
+	}
+
+	result := &Result{
+		LatencyMS: latency,
+		Details:   map[string]any{"exit_code": code},
+	}
+	switch code {
+	case 0:
+		result.Status = model.StatusUp
+	case 1:
+		result.Status = model.StatusDegraded
+	default:
+		result.Status = model.StatusDown
+	}
+
+	message := applyExecOutput(result, stdout.buf.Bytes())
+	if stdout.truncated || stderr.truncated {
+		result.Details["output_truncated"] = true
+	}
+	if errText := strings.TrimSpace(stderr.buf.String()); errText != "" {
+		result.Details["stderr"] = truncateActual(errText)
+		if message == "" {
+			message, _, _ = strings.Cut(errText, "\n")
+		}
+	}
+
+	if result.Status != model.StatusUp {
+		state := fmt.Sprintf("exit status %d", code)
+		if code >= 0 && code < len(nagiosStates) {
+			state = nagiosStates[code]
+		}
+		switch {
+		case message == "":
+			result.Error = state
+		case strings.Contains(message, state):
+			// Plugins usually name their own state, as in "PGSQL CRITICAL - ...".
+			result.Error = truncateActual(message)
+		default:
+			result.Error = state + ": " + truncateActual(message)
+		}
+	}
+	return result, nil
+}
+
+// allowed returns the cleaned command path if it matches an allow pattern.
+func (c *ExecChecker) allowed(command string) (string, error) {
+	if !filepath.IsAbs(command) {
+		return "", fmt.Errorf("command %q must be an absolute path", command)
+	}
+	clean := filepath.Clean(command)
+	for _, pattern := range c.allow {
+		if ok, _ := filepath.Match(pattern, clean); ok {
+			return clean, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not allowed by this node's exec.allow config", clean)
+}
+
+// applyExecOutput records a command's stdout on result, either as JSON or
+// as Nagios plugin text ("SUMMARY | perfdata"), and returns the message
+// to use if the check failed.
+func applyExecOutput(result *Result, stdout []byte) string {
+	text := strings.TrimSpace(string(stdout))
+	if text == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(text, "{") {
+		var out execOutput
+		if err := json.Unmarshal([]byte(text), &out); err == nil {
+			for k, v := range out.Details {
+				if _, taken := result.Details[k]; !taken {
+					result.Details[k] = v
+				}
+			}
+			if out.LatencyMS != nil {
+				result.LatencyMS = *out.LatencyMS
+			}
+			result.StatusCode = out.StatusCode
+			if out.Message != "" {
+				result.Details["output"] = truncateActual(out.Message)
+			}
+			return out.Message
+		}
+	}
+
+	first, _, _ := strings.Cut(text, "\n")
+	summary, perf, _ := strings.Cut(first, "|")
+	summary = strings.TrimSpace(summary)
+	result.Details["output"] = truncateActual(summary)
+	if data := parsePerfData(perf); len(data) > 0 {
+		result.Details["perfdata"] = data
+	}
+	return summary
+}
+
+// parsePerfData parses Nagios performance data:
+// 'label'=value[unit];[warn];[crit];[min];[max] separated by spaces.
+// Malformed items are skipped.
+func parsePerfData(s string) map[string]perfValue {
+	data := map[string]perfValue{}
+	s = strings.TrimSpace(s)
+	for s != "" {
+		var label string
+		if s[0] == '\'' {
+			end := strings.Index(s[1:], "'=")
+			if end == -1 {
+				break
+			}
+			label, s = s[1:end+1], s[end+3:]
+		} else {
+			eq := strings.IndexByte(s, '=')
+			if eq == -1 {
+				break
+			}
+			label, s = s[:eq], s[eq+1:]
+		}
+
+		item, rest, _ := strings.Cut(s, " ")
+		s = strings.TrimSpace(rest)
+
+		fields := strings.Split(item, ";")
+		num := strings.TrimRightFunc(fields[0], func(r rune) bool {
+			return !(r >= '0' && r <= '9' || r == '.')
+		})
+		value, err := strconv.ParseFloat(num, 64)
+		if err != nil || label == "" {
+			continue
+		}
+		pv := perfValue{Value: value, Unit: fields[0][len(num):]}
+		for i, dst := range []*string{&pv.Warn, &pv.Crit, &pv.Min, &pv.Max} {
+			if i+1 < len(fields) {
+				*dst = fields[i+1]
+			}
+		}
+		data[strings.TrimSpace(label)] = pv
+	}
+	return data
+}
+
+// execArgs substitutes {{target}}, {{port}} and {{timeout}} in args.
+func execArgs(args []string, monitor *model.Monitor) []string {
+	timeout := monitor.TimeoutMS / 1000
+	if timeout < 1 {
+		timeout = 1
+	}
+	r := strings.NewReplacer(
+		"{{target}}", monitor.Target,
+		"{{port}}", strconv.Itoa(monitor.Port),
+		"{{timeout}}", strconv.FormatInt(timeout, 10),
+	)
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = r.Replace(a)
+	}
+	return out
+}
+
+// execStdinMonitor returns the monitor as written to a command's stdin. Of
+// its options only the exec command and arguments are kept, so a plugin is
+// not handed credentials meant for other check types; its env values are
+// already in the environment.
+func execStdinMonitor(monitor *model.Monitor) model.Monitor {
+	m := *monitor
+	m.Options = &model.MonitorOptions{Exec: &model.ExecOptions{
+		Command: monitor.Options.Exec.Command,
+		Args:    monitor.Options.Exec.Args,
+	}}
+	return m
+}
+
+// execEnv returns the node's environment plus the monitor's fields and the
+// monitor's own variables.
+func execEnv(monitor *model.Monitor, extra map[string]string) []string {
+	env := append(os.Environ(),
+		"PINGMESH_MONITOR_ID="+monitor.ID,
+		"PINGMESH_MONITOR_NAME="+monitor.Name,
+		"PINGMESH_TARGET="+monitor.Target,
+		"PINGMESH_PORT="+strconv.Itoa(monitor.Port),
+		"PINGMESH_TIMEOUT_MS="+strconv.FormatInt(monitor.TimeoutMS, 10),
+	)
+	for k, v := range extra {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the
+// rest, so a chatty command can't exhaust memory.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// ValidateExecOptions checks an exec monitor's command. Whether a node may
+// run it depends on that node's config and is only known at check time.
+func ValidateExecOptions(opts *model.ExecOptions) error {
+	if opts.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if !filepath.IsAbs(opts.Command) {
+		return fmt.Errorf("command %q must be an absolute path", opts.Command)
+	}
+	if err := validateExecEnv(opts.Env); err != nil {
+		return fmt.Errorf("env: %w", err)
+	}
+	return nil
+}
+
+// execReservedEnv are variables a monitor may not set, as they change which
+// code the command loads or runs rather than configure the check.
+var execReservedEnv = []string{
+	"PATH", "IFS", "ENV", "BASH_ENV", "SHELLOPTS", "GCONV_PATH",
+	"PYTHONPATH", "PYTHONSTARTUP", "PERL5LIB", "PERL5OPT", "RUBYLIB", "RUBYOPT", "NODE_OPTIONS",
+}
+
+// execReservedEnvPrefixes are the dynamic loader's variables and the ones
+// PingMesh sets itself.
+var execReservedEnvPrefixes = []string{"LD_", "DYLD_", "PINGMESH_"}
+
+// validateExecEnv rejects malformed variable names and those that could
+// make a node run other code than the allowed command.
+func validateExecEnv(env map[string]string) error {
+	for k := range env {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return fmt.Errorf("invalid variable name %q", k)
+		}
+		name := strings.ToUpper(k)
+		if slices.Contains(execReservedEnv, name) ||
+			slices.ContainsFunc(execReservedEnvPrefixes, func(p string) bool { return strings.HasPrefix(name, p) }) {
+			return fmt.Errorf("%s may not be set by a monitor", k)
+		}
+	}
+	return nil
+}
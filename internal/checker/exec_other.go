@@ -0,0 +1,9 @@
+//go:build !unix
+
+package checker
+
+import "os/exec"
+
+// configureExecCmd leaves the default behaviour of killing only the
+// command itself on timeout.
+func configureExecCmd(cmd *exec.Cmd) {}
@@ -0,0 +1,140 @@
+package checker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestParsePerfData(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]perfValue
+	}{
+		{"", map[string]perfValue{}},
+		{"time=0.012s;1;5;0", map[string]perfValue{
+			"time": {Value: 0.012, Unit: "s", Warn: "1", Crit: "5", Min: "0"},
+		}},
+		{"'db size'=1024MB;;;0;2048 conns=12", map[string]perfValue{
+			"db size": {Value: 1024, Unit: "MB", Min: "0", Max: "2048"},
+			"conns":   {Value: 12},
+		}},
+		{"load=-1.5 used=87%;80;90", map[string]perfValue{
+			"load": {Value: -1.5},
+			"used": {Value: 87, Unit: "%", Warn: "80", Crit: "90"},
+		}},
+		{"bad=U ok=1", map[string]perfValue{"ok": {Value: 1}}},
+		{"no equals sign", map[string]perfValue{}},
+	}
+	for _, tt := range tests {
+		if got := parsePerfData(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePerfData(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateExecOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    model.ExecOptions
+		wantErr bool
+	}{
+		{"plain", model.ExecOptions{Command: "/usr/lib/nagios/plugins/check_pgsql", Env: map[string]string{"PGUSER": "monitor"}}, false},
+		{"no command", model.ExecOptions{}, true},
+		{"relative command", model.ExecOptions{Command: "check_pgsql"}, true},
+		{"invalid name", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"A=B": "c"}}, true},
+		{"LD_PRELOAD", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"LD_PRELOAD": "/tmp/x.so"}}, true},
+		{"LD_LIBRARY_PATH", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"LD_LIBRARY_PATH": "/tmp"}}, true},
+		{"PATH", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"PATH": "/tmp"}}, true},
+		{"lower-case path", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"Path": "/tmp"}}, true},
+		{"interpreter path", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"PYTHONPATH": "/tmp"}}, true},
+		{"PingMesh variable", model.ExecOptions{Command: "/bin/true", Env: map[string]string{"PINGMESH_TARGET": "x"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExecOptions(&tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExecOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	monitor := &model.Monitor{Target: "db.internal", Port: 5432, TimeoutMS: 2500}
+	got := execArgs([]string{"-H", "{{target}}", "-p", "{{port}}", "-t", "{{timeout}}"}, monitor)
+	want := []string{"-H", "db.internal", "-p", "5432", "-t", "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("execArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestExecCheck(t *testing.T) {
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin.json")
+	script := filepath.Join(dir, "check.sh")
+	os.WriteFile(script, []byte(`#!/bin/sh
+cat > `+stdinFile+`
+echo "CHECK WARNING - $PINGMESH_TARGET slow as $CHECK_USER | time=1.5s;1;2"
+exit 1
+`), 0o755)
+
+	monitor := &model.Monitor{
+		ID:        "exec",
+		CheckType: model.CheckExec,
+		Target:    "db.internal",
+		TimeoutMS: 5000,
+		Options: &model.MonitorOptions{
+			Exec:     &model.ExecOptions{Command: script, Env: map[string]string{"CHECK_USER": "monitor"}},
+			Database: &model.DatabaseOptions{Credentials: model.Credentials{Password: "db-pass"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		allow     []string
+		env       map[string]string
+		want      model.CheckStatus
+		wantError string
+	}{
+		{"not allowed", []string{"/usr/lib/nagios/plugins/*"}, nil, model.StatusDown, "not allowed"},
+		{"reserved variable", []string{filepath.Join(dir, "*")}, map[string]string{"LD_PRELOAD": "/tmp/x.so"}, model.StatusDown, "LD_PRELOAD"},
+		{"warning exit", []string{filepath.Join(dir, "*")}, nil, model.StatusDegraded, "CHECK WARNING - db.internal slow as monitor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := *monitor
+			opts := *monitor.Options
+			exec := *opts.Exec
+			if tt.env != nil {
+				exec.Env = tt.env
+			}
+			opts.Exec = &exec
+			m.Options = &opts
+
+			result, err := NewExecChecker(tt.allow, 0).Check(context.Background(), &m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Fatalf("result = %s %q, want %s containing %q", result.Status, result.Error, tt.want, tt.wantError)
+			}
+		})
+	}
+
+	stdin, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(stdin), "db-pass") || strings.Contains(string(stdin), "database") {
+		t.Errorf("stdin carries other check types' options: %s", stdin)
+	}
+	if !strings.Contains(string(stdin), `"command":"`+script+`"`) {
+		t.Errorf("stdin lacks the exec command: %s", stdin)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package checker
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureExecCmd runs the command in its own process group and kills the
+// whole group on timeout, so plugins that fork can't outlive the check.
+func configureExecCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
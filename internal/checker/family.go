@@ -0,0 +1,219 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// familyCheckTypes lists the check types that honour an address family:
+// those that connect to the target themselves.
+var familyCheckTypes = map[model.CheckType]bool{
+	model.CheckICMP: true, model.CheckTCP: true, model.CheckHTTP: true, model.CheckHTTPS: true,
+	model.CheckHTTPKeyword: true, model.CheckHTTPFlow: true, model.CheckTLS: true,
+	model.CheckTraceroute: true, model.CheckGRPC: true, model.CheckPostgres: true,
+	model.CheckMySQL: true, model.CheckRedis: true, model.CheckSMTP: true, model.CheckIMAP: true,
+	model.CheckPOP3: true, model.CheckUDP: true, model.CheckNTP: true, model.CheckSNMP: true,
+	model.CheckRADIUS: true, model.CheckWebSocket: true, model.CheckSSE: true,
+}
+
+// Run performs one check of monitor with c over the monitor's address
+// family and records the remote IP used in the result details as
+// resolved_ip. With model.AddressFamilyBoth the IPv4 and IPv6 checks run
+// side by side; the worse result is returned, with each family's outcome
+// under "families".
+func Run(ctx context.Context, c Checker, monitor *model.Monitor) (*Result, error) {
+	family := ""
+	if monitor.Options != nil {
+		family = monitor.Options.AddressFamily
+	}
+	if family != model.AddressFamilyBoth {
+		return runFamily(ctx, c, monitor, family)
+	}
+
+	families := []string{model.AddressFamilyIPv4, model.AddressFamilyIPv6}
+	results := make([]*Result, len(families))
+	var wg sync.WaitGroup
+	for i, f := range families {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := runFamily(ctx, c, monitor, f)
+			if err != nil {
+				result = &Result{Status: model.StatusDown, Error: err.Error()}
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	primary := results[0]
+	summary := map[string]any{}
+	var errs []string
+	for i, f := range families {
+		r := results[i]
+		if statusRank(r.Status) > statusRank(primary.Status) {
+			primary = r
+		}
+		sub := map[string]any{
+			"status":     r.Status,
+			"latency_ms": r.LatencyMS,
+		}
+		if ip, ok := r.Details["resolved_ip"]; ok {
+			sub["resolved_ip"] = ip
+		}
+		if r.Error != "" {
+			sub["error"] = r.Error
+			errs = append(errs, fmt.Sprintf("%s: %s", familyName(f), r.Error))
+		}
+		summary[familyName(f)] = sub
+	}
+
+	combined := *primary
+	combined.Details = map[string]any{}
+	for k, v := range primary.Details {
+		combined.Details[k] = v
+	}
+	combined.Details["families"] = summary
+	combined.Error = strings.Join(errs, "; ")
+	return &combined, nil
+}
+
+// runFamily runs the check restricted to family ("" for either).
+func runFamily(ctx context.Context, c Checker, monitor *model.Monitor, family string) (*Result, error) {
+	info := &dialInfo{family: family}
+	result, err := c.Check(context.WithValue(ctx, dialInfoKey{}, info), monitor)
+	if err != nil || result == nil {
+		return result, err
+	}
+	if ip := info.remoteIP(); ip != nil {
+		if result.Details == nil {
+			result.Details = map[string]any{}
+		}
+		result.Details["resolved_ip"] = ip.String()
+	}
+	return result, nil
+}
+
+// statusRank orders check statuses from best to worst.
+func statusRank(s model.CheckStatus) int {
+	switch s {
+	case model.StatusUp:
+		return 0
+	case model.StatusDegraded:
+		return 1
+	}
+	return 2
+}
+
+func familyName(family string) string {
+	if family == model.AddressFamilyIPv6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// dialInfo carries a check's address family to the code that connects to
+// the target, and records the address it connected to.
+type dialInfo struct {
+	family string
+
+	mu sync.Mutex
+	ip net.IP
+}
+
+type dialInfoKey struct{}
+
+// dialInfoFrom returns the dial settings of the check running in ctx; nil,
+// which allows either family, outside Run.
+func dialInfoFrom(ctx context.Context) *dialInfo {
+	info, _ := ctx.Value(dialInfoKey{}).(*dialInfo)
+	return info
+}
+
+// network narrows a network name such as "tcp", "udp" or "ip" to the
+// check's address family.
+func (d *dialInfo) network(network string) string {
+	if d != nil {
+		switch d.family {
+		case model.AddressFamilyIPv4:
+			return network + "4"
+		case model.AddressFamilyIPv6:
+			return network + "6"
+		}
+	}
+	return network
+}
+
+// record notes the remote address of a connection. With several
+// connections, such as redirects to another host, the last one is kept.
+func (d *dialInfo) record(ip net.IP) {
+	if d == nil || ip == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ip = ip
+}
+
+func (d *dialInfo) remoteIP() net.IP {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.ip
+}
+
+// dial connects like dialer.DialContext over the check's address family and
+// records the remote IP.
+func (d *dialInfo) dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, d.network(network), address)
+	if err != nil {
+		return nil, err
+	}
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		d.record(addr.IP)
+	case *net.UDPAddr:
+		d.record(addr.IP)
+	}
+	return conn, nil
+}
+
+// dialContext connects to address over the address family of the check
+// running in ctx.
+func dialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	return dialInfoFrom(ctx).dial(ctx, dialer, network, address)
+}
+
+// familyDialer returns a dial function for clients that dial with a context
+// of their own, such as http.Transport, pgx and gRPC, using the address
+// family of the check running in ctx.
+func familyDialer(ctx context.Context, dialer *net.Dialer) func(context.Context, string, string) (net.Conn, error) {
+	info := dialInfoFrom(ctx)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return info.dial(ctx, dialer, network, address)
+	}
+}
+
+// lookupIP resolves host to addresses of the check's address family.
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, dialInfoFrom(ctx).network("ip"), host)
+}
+
+// ValidateAddressFamily checks a monitor's address family setting.
+func ValidateAddressFamily(checkType model.CheckType, family string) error {
+	switch family {
+	case "":
+		return nil
+	case model.AddressFamilyIPv4, model.AddressFamilyIPv6, model.AddressFamilyBoth:
+	default:
+		return fmt.Errorf("unknown address family %q (want v4, v6 or both)", family)
+	}
+	if !familyCheckTypes[checkType] {
+		return fmt.Errorf("%s checks do not support an address family", checkType)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// familyChecker returns a fixed result per address family and records the
+// family each check ran over.
+type familyChecker struct {
+	results map[string]*Result
+}
+
+func (c *familyChecker) Type() model.CheckType { return model.CheckTCP }
+
+func (c *familyChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	info := dialInfoFrom(ctx)
+	result, ok := c.results[info.family]
+	if !ok {
+		return nil, errors.New("no result for " + info.family)
+	}
+	copied := *result
+	copied.Details = map[string]any{"family": info.family}
+	if info.family == model.AddressFamilyIPv6 {
+		info.record(net.ParseIP("2001:db8::1"))
+	} else {
+		info.record(net.ParseIP("192.0.2.1"))
+	}
+	return &copied, nil
+}
+
+// listenTCP accepts and closes connections on a local address and returns
+// its port, skipping the test when the address family is unavailable.
+func listenTCP(t *testing.T, network, address string) int {
+	t.Helper()
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		t.Skipf("cannot listen on %s: %v", address, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestRun(t *testing.T) {
+	up := &Result{Status: model.StatusUp, LatencyMS: 5}
+	degraded := &Result{Status: model.StatusDegraded, LatencyMS: 900, Error: "slow"}
+	down := &Result{Status: model.StatusDown, Error: "connection refused"}
+
+	tests := []struct {
+		name       string
+		family     string
+		results    map[string]*Result
+		want       model.CheckStatus
+		wantFamily string
+		wantIP     string
+		wantError  string
+	}{
+		{"either", "", map[string]*Result{"": up}, model.StatusUp, "", "192.0.2.1", ""},
+		{"ipv4 only", model.AddressFamilyIPv4, map[string]*Result{"v4": up}, model.StatusUp, "v4", "192.0.2.1", ""},
+		{"ipv6 only", model.AddressFamilyIPv6, map[string]*Result{"v6": down}, model.StatusDown, "v6", "2001:db8::1", "connection refused"},
+		{"both up", model.AddressFamilyBoth, map[string]*Result{"v4": up, "v6": up}, model.StatusUp, "v4", "192.0.2.1", ""},
+		{"ipv6 outage", model.AddressFamilyBoth, map[string]*Result{"v4": up, "v6": down}, model.StatusDown, "v6", "2001:db8::1",
+			"ipv6: connection refused"},
+		{"worst of both", model.AddressFamilyBoth, map[string]*Result{"v4": down, "v6": degraded}, model.StatusDown, "v4", "192.0.2.1",
+			"ipv4: connection refused; ipv6: slow"},
+		{"checker error", model.AddressFamilyBoth, map[string]*Result{"v4": up}, model.StatusDown, "", "",
+			"ipv6: no result for v6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Options: &model.MonitorOptions{AddressFamily: tt.family}}
+			result, err := Run(context.Background(), &familyChecker{results: tt.results}, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || result.Error != tt.wantError {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+			if got, _ := result.Details["family"].(string); got != tt.wantFamily {
+				t.Errorf("details of family %q returned, want %q", got, tt.wantFamily)
+			}
+			if got, _ := result.Details["resolved_ip"].(string); got != tt.wantIP {
+				t.Errorf("resolved_ip = %q, want %q", got, tt.wantIP)
+			}
+
+			families, _ := result.Details["families"].(map[string]any)
+			if (tt.family == model.AddressFamilyBoth) != (families != nil) {
+				t.Fatalf("families = %v, want them only for both", families)
+			}
+			for name, f := range tt.results {
+				if families == nil {
+					break
+				}
+				sub := families[familyName(name)].(map[string]any)
+				if sub["status"] != f.Status || sub["latency_ms"] != f.LatencyMS {
+					t.Errorf("families[%s] = %v, want %s", familyName(name), sub, f.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRunOverLoopback(t *testing.T) {
+	port := listenTCP(t, "tcp4", "127.0.0.1:0")
+
+	tests := []struct {
+		name   string
+		target string
+		family string
+		want   model.CheckStatus
+		wantIP string
+	}{
+		{"either", "127.0.0.1", "", model.StatusUp, "127.0.0.1"},
+		{"ipv4", "127.0.0.1", model.AddressFamilyIPv4, model.StatusUp, "127.0.0.1"},
+		{"ipv4 address over ipv6", "127.0.0.1", model.AddressFamilyIPv6, model.StatusDown, ""},
+		{"ipv6 outage", "localhost", model.AddressFamilyBoth, model.StatusDown, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: tt.target, Port: port, TimeoutMS: 1000,
+				Options: &model.MonitorOptions{AddressFamily: tt.family}}
+			result, err := Run(context.Background(), &TCPChecker{}, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("status = %s (%s), want %s", result.Status, result.Error, tt.want)
+			}
+			if got, _ := result.Details["resolved_ip"].(string); got != tt.wantIP {
+				t.Errorf("resolved_ip = %q, want %q", got, tt.wantIP)
+			}
+			if tt.family == model.AddressFamilyBoth {
+				families := result.Details["families"].(map[string]any)
+				if v4 := families["ipv4"].(map[string]any); v4["status"] != model.StatusUp || v4["resolved_ip"] != "127.0.0.1" {
+					t.Errorf("ipv4 = %v, want up over 127.0.0.1", v4)
+				}
+				if s := families["ipv6"].(map[string]any)["status"]; s != model.StatusDown {
+					t.Errorf("ipv6 status = %v, want down", s)
+				}
+				if !strings.HasPrefix(result.Error, "ipv6: ") {
+					t.Errorf("error = %q, want the IPv6 failure", result.Error)
+				}
+			}
+		})
+	}
+
+	port6 := listenTCP(t, "tcp6", "[::1]:0")
+	m := &model.Monitor{Target: "::1", Port: port6, TimeoutMS: 1000,
+		Options: &model.MonitorOptions{AddressFamily: model.AddressFamilyIPv6}}
+	result, _ := Run(context.Background(), &TCPChecker{}, m)
+	if result.Status != model.StatusUp || result.Details["resolved_ip"] != "::1" {
+		t.Errorf("ipv6 result = %s, resolved_ip %v, want up over ::1", result.Status, result.Details["resolved_ip"])
+	}
+}
+
+func TestDialInfoNetwork(t *testing.T) {
+	tests := []struct {
+		info    *dialInfo
+		network string
+		want    string
+	}{
+		{nil, "tcp", "tcp"},
+		{&dialInfo{}, "udp", "udp"},
+		{&dialInfo{family: model.AddressFamilyIPv4}, "tcp", "tcp4"},
+		{&dialInfo{family: model.AddressFamilyIPv6}, "ip", "ip6"},
+	}
+	for _, tt := range tests {
+		if got := tt.info.network(tt.network); got != tt.want {
+			t.Errorf("network(%q) with %+v = %q, want %q", tt.network, tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestBaselineKey(t *testing.T) {
+	tests := []struct {
+		info *dialInfo
+		want string
+	}{
+		{nil, "content"},
+		{&dialInfo{}, "content"},
+		{&dialInfo{family: model.AddressFamilyIPv4}, "content/v4"},
+		{&dialInfo{family: model.AddressFamilyIPv6}, "content/v6"},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.info != nil {
+			ctx = context.WithValue(ctx, dialInfoKey{}, tt.info)
+		}
+		if got := baselineKey(ctx, "content"); got != tt.want {
+			t.Errorf("baselineKey() with %+v = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAddressFamily(t *testing.T) {
+	tests := []struct {
+		checkType model.CheckType
+		family    string
+		wantErr   bool
+	}{
+		{model.CheckHTTP, "", false},
+		{model.CheckPush, "", false},
+		{model.CheckTCP, model.AddressFamilyIPv4, false},
+		{model.CheckICMP, model.AddressFamilyIPv6, false},
+		{model.CheckSSE, model.AddressFamilyBoth, false},
+		{model.CheckHTTP, "ipv4", true},
+		{model.CheckPush, model.AddressFamilyIPv6, true},
+		{model.CheckDomain, model.AddressFamilyBoth, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAddressFamily(tt.checkType, tt.family); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateAddressFamily(%s, %q) error = %v, wantErr %v", tt.checkType, tt.family, err, tt.wantErr)
+		}
+	}
+}
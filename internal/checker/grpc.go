@@ -0,0 +1,187 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultGRPCStatusMap maps health statuses to check statuses when the
+// monitor does not override them.
+var defaultGRPCStatusMap = map[string]model.CheckStatus{
+	healthpb.HealthCheckResponse_SERVING.String():         model.StatusUp,
+	healthpb.HealthCheckResponse_NOT_SERVING.String():     model.StatusDown,
+	healthpb.HealthCheckResponse_UNKNOWN.String():         model.StatusDegraded,
+	healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String(): model.StatusDown,
+}
+
+// GRPCChecker calls grpc.health.v1.Health/Check.
+type GRPCChecker struct{}
+
+func (c *GRPCChecker) Type() model.CheckType {
+	return model.CheckGRPC
+}
+
+func (c *GRPCChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+
+	opts := &model.GRPCOptions{}
+	if monitor.Options != nil && monitor.Options.GRPC != nil {
+		opts = monitor.Options.GRPC
+	}
+
+	host := strings.Trim(monitor.Target, "[]")
+	address := net.JoinHostPort(host, strconv.Itoa(monitor.Port))
+
+	creds, err := grpcCredentials(opts, host)
+	if err != nil {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  err.Error(),
+		}, nil
+	}
+
+	conn, err := grpc.NewClient("passthrough:///"+address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent("PingMesh/1.0"),
+		grpc.WithContextDialer(func(dctx context.Context, addr string) (net.Conn, error) {
+			return familyDialer(ctx, &net.Dialer{})(dctx, "tcp", addr)
+		}),
+	)
+	if err != nil {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  fmt.Sprintf("creating grpc client: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if len(opts.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(opts.Metadata))
+	}
+
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: opts.Service})
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     grpcError(err, opts.Service),
+		}, nil
+	}
+
+	health := resp.GetStatus().String()
+	result := &Result{
+		Status:    grpcStatus(health, opts.StatusMap),
+		LatencyMS: latency,
+		Details:   map[string]any{"health_status": health},
+	}
+	if opts.Service != "" {
+		result.Details["service"] = opts.Service
+	}
+	if result.Status != model.StatusUp {
+		result.Error = fmt.Sprintf("health status %s", health)
+	}
+	return result, nil
+}
+
+// grpcCredentials builds plaintext, TLS or mTLS transport credentials.
+func grpcCredentials(opts *model.GRPCOptions, host string) (credentials.TransportCredentials, error) {
+	if !opts.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.SkipVerify,
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_cert: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert %s contains no certificates", opts.CACert)
+		}
+	}
+	if opts.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// grpcStatus maps a health status name through the monitor's overrides,
+// falling back to the defaults.
+func grpcStatus(health string, overrides map[string]string) model.CheckStatus {
+	if s, ok := overrides[health]; ok {
+		return model.CheckStatus(s)
+	}
+	if s, ok := defaultGRPCStatusMap[health]; ok {
+		return s
+	}
+	return model.StatusDown
+}
+
+// grpcError explains RPC failures that mean the health service itself is
+// missing rather than the server being unreachable.
+func grpcError(err error, service string) string {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Unimplemented:
+		return "server does not implement grpc.health.v1.Health"
+	case codes.NotFound:
+		return fmt.Sprintf("health service does not know service %q", service)
+	case codes.DeadlineExceeded:
+		return "health check timed out"
+	}
+	return fmt.Sprintf("health check failed: %s: %s", st.Code(), st.Message())
+}
+
+// ValidateGRPCOptions checks the status map and that mTLS settings are
+// complete.
+func ValidateGRPCOptions(opts *model.GRPCOptions) error {
+	for health, s := range opts.StatusMap {
+		if _, ok := defaultGRPCStatusMap[health]; !ok {
+			return fmt.Errorf("status_map: unknown health status %q", health)
+		}
+		switch model.CheckStatus(s) {
+		case model.StatusUp, model.StatusDown, model.StatusDegraded:
+		default:
+			return fmt.Errorf("status_map: %s must map to up, down or degraded", health)
+		}
+	}
+	if (opts.ClientCert == "") != (opts.ClientKey == "") {
+		return fmt.Errorf("client_cert and client_key must be set together")
+	}
+	if !opts.TLS && (opts.ClientCert != "" || opts.CACert != "" || opts.SkipVerify || opts.ServerName != "") {
+		return fmt.Errorf("certificate settings need tls enabled")
+	}
+	return nil
+}
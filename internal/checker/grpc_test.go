@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Calls without the token are refused, to check metadata is sent.
+	auth := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if v := md.Get("authorization"); len(v) == 0 || v[0] != "Bearer t0k" {
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+		return handler(ctx, req)
+	}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(auth))
+	hs := health.NewServer()
+	hs.SetServingStatus("shop.Cart", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("shop.Search", healthpb.HealthCheckResponse_NOT_SERVING)
+	hs.SetServingStatus("shop.Index", healthpb.HealthCheckResponse_UNKNOWN)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	token := map[string]string{"authorization": "Bearer t0k"}
+	tests := []struct {
+		name      string
+		opts      model.GRPCOptions
+		want      model.CheckStatus
+		wantError string
+	}{
+		{"server health", model.GRPCOptions{Metadata: token}, model.StatusUp, ""},
+		{"serving service", model.GRPCOptions{Service: "shop.Cart", Metadata: token}, model.StatusUp, ""},
+		{"not serving", model.GRPCOptions{Service: "shop.Search", Metadata: token}, model.StatusDown, "NOT_SERVING"},
+		{"unknown is degraded", model.GRPCOptions{Service: "shop.Index", Metadata: token}, model.StatusDegraded, "UNKNOWN"},
+		{"status map override", model.GRPCOptions{Service: "shop.Search", Metadata: token,
+			StatusMap: map[string]string{"NOT_SERVING": "degraded"}}, model.StatusDegraded, "NOT_SERVING"},
+		{"unregistered service", model.GRPCOptions{Service: "shop.Missing", Metadata: token}, model.StatusDown, `does not know service "shop.Missing"`},
+		{"missing metadata", model.GRPCOptions{}, model.StatusDown, "Unauthenticated"},
+		{"tls to a plaintext server", model.GRPCOptions{TLS: true, Metadata: token}, model.StatusDown, "health check failed"},
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: "127.0.0.1", Port: port, TimeoutMS: 2000, Options: &model.MonitorOptions{GRPC: &tt.opts}}
+			result, err := (&GRPCChecker{}).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{status.Error(codes.Unimplemented, "unknown service"), "does not implement grpc.health.v1.Health"},
+		{status.Error(codes.NotFound, "unknown service"), `does not know service "api"`},
+		{status.Error(codes.DeadlineExceeded, "deadline"), "timed out"},
+		{status.Error(codes.Unavailable, "connection refused"), "health check failed: Unavailable: connection refused"},
+	}
+	for _, tt := range tests {
+		if got := grpcError(tt.err, "api"); !strings.Contains(got, tt.want) {
+			t.Errorf("grpcError(%v) = %q, want it to contain %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestValidateGRPCOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    model.GRPCOptions
+		wantErr bool
+	}{
+		{"plaintext", model.GRPCOptions{Service: "api"}, false},
+		{"mtls", model.GRPCOptions{TLS: true, CACert: "/etc/ca.pem", ClientCert: "/etc/c.pem", ClientKey: "/etc/c.key"}, false},
+		{"status map", model.GRPCOptions{StatusMap: map[string]string{"UNKNOWN": "down"}}, false},
+		{"unknown health status", model.GRPCOptions{StatusMap: map[string]string{"BROKEN": "down"}}, true},
+		{"bad check status", model.GRPCOptions{StatusMap: map[string]string{"UNKNOWN": "paused"}}, true},
+		{"cert without key", model.GRPCOptions{TLS: true, ClientCert: "/etc/c.pem"}, true},
+		{"certificates without tls", model.GRPCOptions{CACert: "/etc/ca.pem"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateGRPCOptions(&tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateGRPCOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,22 +32,19 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Resul
 		scheme = "https"
 	}
 
-	// Strip scheme if user included it in the target (e.g. "https://example.com")
-	target := monitor.Target
-	target = strings.TrimPrefix(target, "https://")
-	target = strings.TrimPrefix(target, "http://")
-	target = strings.TrimRight(target, "/")
-
-	port := monitor.Port
-	if port == 0 {
-		if scheme == "https" {
-			port = 443
-		} else {
-			port = 80
-		}
+	targetURL, err := TargetURL(monitor.Target, scheme, monitor.Port)
+	if err != nil {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  err.Error(),
+		}, nil
 	}
 
-	url := fmt.Sprintf("%s://%s:%d", scheme, target, port)
+	opts := &model.HTTPOptions{}
+	if monitor.Options != nil && monitor.Options.HTTP != nil {
+		opts = monitor.Options.HTTP
+	}
+	followRedirects := opts.FollowRedirects == nil || *opts.FollowRedirects
 
 	client := &http.Client{
 		Timeout: timeout,
@@ -52,9 +52,13 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Resul
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
 			},
+			DialContext:       familyDialer(ctx, &net.Dialer{}),
 			DisableKeepAlives: true,
 		},
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
 			if len(via) >= 10 {
 				return fmt.Errorf("too many redirects")
 			}
@@ -62,28 +66,53 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Resul
 		},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	var phases httpPhases
+	req, err := newHTTPRequest(phases.withTrace(ctx), targetURL.String(), opts)
 	if err != nil {
 		return &Result{
 			Status: model.StatusDown,
 			Error:  fmt.Sprintf("creating request: %v", err),
 		}, nil
 	}
-	req.Header.Set("User-Agent", "PingMesh/1.0")
 
 	start := time.Now()
 	resp, err := client.Do(req)
 	latency := float64(time.Since(start).Microseconds()) / 1000.0
 
 	if err != nil {
-		return &Result{
+		result := &Result{
 			Status:    model.StatusDown,
 			LatencyMS: latency,
 			Error:     fmt.Sprintf("request failed: %v", err),
-		}, nil
+		}
+		phases.addTimings(result)
+		return result, nil
 	}
 	defer resp.Body.Close()
-	io.Copy(io.Discard, resp.Body)
+
+	var assertions []model.Assertion
+	var assets *model.AssetOptions
+	if monitor.Options != nil {
+		assertions = monitor.Options.Assertions
+		assets = monitor.Options.Assets
+	}
+
+	// The body is only buffered when assertions or asset checks need it
+	// (limit 1MB).
+	var body []byte
+	if len(assertions) > 0 || assets != nil {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return &Result{
+				Status:     model.StatusDown,
+				LatencyMS:  latency,
+				StatusCode: resp.StatusCode,
+				Error:      fmt.Sprintf("reading body: %v", err),
+			}, nil
+		}
+	}
+	rest, _ := io.Copy(io.Discard, resp.Body)
+	phases.bodyRead()
 
 	result := &Result{
 		Status:     model.StatusUp,
@@ -94,29 +123,194 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Resul
 			"protocol":    resp.Proto,
 		},
 	}
+	phases.addTimings(result)
 
 	// Check expected status code
-	if monitor.ExpectedStatus > 0 && resp.StatusCode != monitor.ExpectedStatus {
-		result.Status = model.StatusDown
-		result.Error = fmt.Sprintf("expected status %d, got %d", monitor.ExpectedStatus, resp.StatusCode)
-	} else if monitor.ExpectedStatus == 0 && resp.StatusCode >= 400 {
+	if msg := statusError(resp.StatusCode, opts.AcceptedStatus, monitor.ExpectedStatus); msg != "" {
 		result.Status = model.StatusDown
-		result.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		result.Error = msg
 	}
 
-	// Check TLS certificate expiry for HTTPS
-	if c.checkType == model.CheckHTTPS && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
-		cert := resp.TLS.PeerCertificates[0]
-		daysUntilExpiry := time.Until(cert.NotAfter).Hours() / 24
-		result.Details["tls_expiry_days"] = int(daysUntilExpiry)
-		result.Details["tls_issuer"] = cert.Issuer.CommonName
-		result.Details["tls_subject"] = cert.Subject.CommonName
+	if len(assertions) > 0 {
+		applyAssertions(result, assertions, resp, body)
+	}
 
-		if daysUntilExpiry < 7 {
-			result.Status = model.StatusDegraded
-			result.Error = fmt.Sprintf("TLS certificate expires in %d days", int(daysUntilExpiry))
+	if assets != nil && result.Status == model.StatusUp && isHTML(resp.Header.Get("Content-Type")) {
+		checkAssets(ctx, result, assets, resp.Request.URL, body, int64(len(body))+rest, timeout)
+	}
+
+	// Record the certificate chain and check expiry for HTTPS
+	if c.checkType == model.CheckHTTPS && resp.TLS != nil {
+		addCertDetails(result, resp.TLS)
+		if result.Status == model.StatusUp {
+			warnDays := 0
+			if monitor.Options != nil && monitor.Options.TLS != nil {
+				warnDays = monitor.Options.TLS.ExpiryWarnDays
+			}
+			applyExpiry(result, resp.TLS, warnDays)
 		}
 	}
 
 	return result, nil
 }
+
+// TargetURL parses a monitor target into a request URL. Targets may be bare
+// hosts ("example.com"), host:port pairs, IPv6 literals or full URLs with a
+// path and query string. The scheme always comes from the check type, and
+// port is only applied when the target doesn't carry an explicit port.
+func TargetURL(target, scheme string, port int) (*url.URL, error) {
+	raw := strings.TrimSpace(target)
+	if raw == "" {
+		return nil, fmt.Errorf("target is empty")
+	}
+
+	// Bare IPv6 literals need brackets before they can be parsed as a host.
+	if ip := net.ParseIP(strings.Trim(raw, "[]")); ip != nil && ip.To4() == nil {
+		raw = "[" + ip.String() + "]"
+	}
+	if !strings.Contains(raw, "://") {
+		raw = scheme + "://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("target URL %q has no host", target)
+	}
+
+	if p := u.Port(); p != "" {
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("target URL %q has an invalid port", target)
+		}
+	} else if port > 0 {
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	}
+
+	u.Scheme = scheme
+	return u, nil
+}
+
+// targetScheme returns the scheme written in target, or fallback if the
+// target is a bare host.
+func targetScheme(target, fallback string) string {
+	lower := strings.ToLower(strings.TrimSpace(target))
+	switch {
+	case strings.HasPrefix(lower, "https://"):
+		return "https"
+	case strings.HasPrefix(lower, "http://"):
+		return "http"
+	}
+	return fallback
+}
+
+// newHTTPRequest builds the outgoing request from the monitor's HTTP options.
+func newHTTPRequest(ctx context.Context, url string, opts *model.HTTPOptions) (*http.Request, error) {
+	method := http.MethodGet
+	if opts.Method != "" {
+		method = strings.ToUpper(opts.Method)
+	}
+
+	var body io.Reader
+	if opts.Body != "" {
+		body = strings.NewReader(opts.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "PingMesh/1.0")
+
+	switch {
+	case opts.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	case opts.BasicAuthUser != "":
+		req.SetBasicAuth(opts.BasicAuthUser, opts.BasicAuthPassword)
+	}
+
+	// Custom headers are applied last so they can override the defaults above.
+	for k, v := range opts.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	return req, nil
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+// ParseStatusRanges parses a comma-separated list of status codes and
+// inclusive ranges, e.g. "200-299,301".
+func ParseStatusRanges(spec string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		loStr, hiStr, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(strings.TrimSpace(loStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		hi := lo
+		if isRange {
+			hi, err = strconv.Atoi(strings.TrimSpace(hiStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+		}
+		if lo < 100 || hi > 599 || lo > hi {
+			return nil, fmt.Errorf("status range %q out of bounds", part)
+		}
+		ranges = append(ranges, StatusRange{Min: lo, Max: hi})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no status codes given")
+	}
+	return ranges, nil
+}
+
+// statusError returns why a response status fails a check, or "" if it is
+// accepted. accepted takes precedence over expected; with neither, any 4xx
+// or 5xx status fails.
+func statusError(code int, accepted string, expected int) string {
+	switch {
+	case accepted != "":
+		ranges, err := ParseStatusRanges(accepted)
+		if err != nil {
+			return fmt.Sprintf("invalid accepted_status: %v", err)
+		}
+		if !statusInRanges(code, ranges) {
+			return fmt.Sprintf("status %d not in accepted range %s", code, accepted)
+		}
+	case expected > 0:
+		if code != expected {
+			return fmt.Sprintf("expected status %d, got %d", expected, code)
+		}
+	case code >= 400:
+		return fmt.Sprintf("HTTP %d", code)
+	}
+	return ""
+}
+
+func statusInRanges(code int, ranges []StatusRange) bool {
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
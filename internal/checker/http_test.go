@@ -0,0 +1,186 @@
+package checker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []StatusRange
+		wantErr bool
+	}{
+		{"200", []StatusRange{{200, 200}}, false},
+		{"200-299,301", []StatusRange{{200, 299}, {301, 301}}, false},
+		{" 200 - 204 , 404 ", []StatusRange{{200, 204}, {404, 404}}, false},
+		{"200,,", []StatusRange{{200, 200}}, false},
+		{"", nil, true},
+		{"abc", nil, true},
+		{"200-x", nil, true},
+		{"299-200", nil, true},
+		{"99", nil, true},
+		{"200-600", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStatusRanges(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStatusRanges(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseStatusRanges(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		accepted string
+		expected int
+		fails    bool
+	}{
+		{"default accepts redirects", 302, "", 0, false},
+		{"default rejects 4xx", 404, "", 0, true},
+		{"expected status matches", 204, "", 204, false},
+		{"expected status differs", 200, "", 204, true},
+		{"accepted range", 404, "200-299,404", 0, false},
+		{"outside accepted range", 500, "200-299,404", 0, true},
+		{"accepted takes precedence", 404, "404", 200, false},
+		{"invalid accepted range", 200, "x", 0, true},
+	}
+	for _, tt := range tests {
+		if got := statusError(tt.code, tt.accepted, tt.expected); (got != "") != tt.fails {
+			t.Errorf("%s: statusError(%d) = %q, want failure %v", tt.name, tt.code, got, tt.fails)
+		}
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	type request struct {
+		method, body, auth, header, host string
+	}
+	var got request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = request{r.Method, string(body), r.Header.Get("Authorization"), r.Header.Get("X-Env"), r.Host}
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/", http.StatusFound)
+		case "/missing":
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	noFollow := false
+	tests := []struct {
+		name       string
+		path       string
+		opts       *model.HTTPOptions
+		want       request
+		wantStatus model.CheckStatus
+		wantCode   int
+	}{
+		{"defaults", "/", nil,
+			request{method: "GET"}, model.StatusUp, 200},
+		{"method, body and header", "/", &model.HTTPOptions{Method: "post", Body: `{"a":1}`, Headers: map[string]string{"X-Env": "prod"}},
+			request{method: "POST", body: `{"a":1}`, header: "prod"}, model.StatusUp, 200},
+		{"basic auth", "/", &model.HTTPOptions{BasicAuthUser: "admin", BasicAuthPassword: "secret"},
+			request{method: "GET", auth: "Basic YWRtaW46c2VjcmV0"}, model.StatusUp, 200},
+		{"bearer token wins over basic auth", "/", &model.HTTPOptions{BasicAuthUser: "admin", BearerToken: "t0k"},
+			request{method: "GET", auth: "Bearer t0k"}, model.StatusUp, 200},
+		{"header overrides auth", "/", &model.HTTPOptions{BearerToken: "t0k", Headers: map[string]string{"Authorization": "Custom x"}},
+			request{method: "GET", auth: "Custom x"}, model.StatusUp, 200},
+		{"host header", "/", &model.HTTPOptions{Headers: map[string]string{"host": "example.com"}},
+			request{method: "GET", host: "example.com"}, model.StatusUp, 200},
+		{"redirects are followed", "/redirect", nil,
+			request{method: "GET"}, model.StatusUp, 200},
+		{"redirects not followed", "/redirect", &model.HTTPOptions{FollowRedirects: &noFollow},
+			request{method: "GET"}, model.StatusUp, 302},
+		{"accepted status", "/missing", &model.HTTPOptions{AcceptedStatus: "200-299,404"},
+			request{method: "GET"}, model.StatusUp, 404},
+		{"not found", "/missing", nil,
+			request{method: "GET"}, model.StatusDown, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = request{}
+			m := &model.Monitor{Target: srv.URL + tt.path, TimeoutMS: 5000}
+			if tt.opts != nil {
+				m.Options = &model.MonitorOptions{HTTP: tt.opts}
+			}
+			result, err := (&HTTPChecker{checkType: model.CheckHTTP}).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus || result.StatusCode != tt.wantCode {
+				t.Errorf("result = %s %d (%s), want %s %d", result.Status, result.StatusCode, result.Error, tt.wantStatus, tt.wantCode)
+			}
+			if tt.want.host == "" {
+				got.host = ""
+			}
+			if got != tt.want {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetURL(t *testing.T) {
+	tests := []struct {
+		target  string
+		scheme  string
+		port    int
+		want    string
+		wantErr bool
+	}{
+		{"example.com", "https", 0, "https://example.com", false},
+		{"example.com", "http", 8080, "http://example.com:8080", false},
+		{"example.com:9000", "http", 8080, "http://example.com:9000", false},
+		{"https://example.com/health?full=1", "https", 0, "https://example.com/health?full=1", false},
+		{"http://example.com/a", "https", 0, "https://example.com/a", false},
+		{"https://example.com:8443/a", "https", 443, "https://example.com:8443/a", false},
+		{"2001:db8::1", "http", 0, "http://[2001:db8::1]", false},
+		{"2001:db8::1", "http", 8080, "http://[2001:db8::1]:8080", false},
+		{"[2001:db8::1]:9000", "http", 8080, "http://[2001:db8::1]:9000", false},
+		{"  example.com  ", "http", 0, "http://example.com", false},
+		{"", "http", 0, "", true},
+		{"ftp://example.com", "http", 0, "", true},
+		{"http:///path", "http", 0, "", true},
+		{"example.com:99999", "http", 0, "", true},
+	}
+	for _, tt := range tests {
+		got, err := TargetURL(tt.target, tt.scheme, tt.port)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TargetURL(%q, %q, %d) error = %v, wantErr %v", tt.target, tt.scheme, tt.port, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("TargetURL(%q, %q, %d) = %s, want %s", tt.target, tt.scheme, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestTargetScheme(t *testing.T) {
+	tests := []struct {
+		target, want string
+	}{
+		{"HTTPS://example.com", "https"},
+		{"http://example.com", "http"},
+		{"example.com", "tcp"},
+	}
+	for _, tt := range tests {
+		if got := targetScheme(tt.target, "tcp"); got != tt.want {
+			t.Errorf("targetScheme(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// HTTPFlowChecker runs multi-step HTTP transactions, such as logging in and
+// then calling an API with the session cookie or a token from the login.
+type HTTPFlowChecker struct{}
+
+func (c *HTTPFlowChecker) Type() model.CheckType {
+	return model.CheckHTTPFlow
+}
+
+// flowStepResult records one step of an http_flow check in result details.
+// URLs are recorded as configured, before expansion, and only the names of
+// extracted variables are kept, since their values are often credentials.
+type flowStepResult struct {
+	Name       string             `json:"name"`
+	Method     string             `json:"method"`
+	URL        string             `json:"url"`
+	StatusCode int                `json:"status_code,omitempty"`
+	LatencyMS  float64            `json:"latency_ms"`
+	Timings    map[string]float64 `json:"timings,omitempty"`
+	Extracted  []string           `json:"extracted,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Assertions []assertionFailure `json:"assertions_failed,omitempty"`
+}
+
+func (c *HTTPFlowChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	if monitor.Options == nil || monitor.Options.HTTPFlow == nil || len(monitor.Options.HTTPFlow.Steps) == 0 {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  "http_flow checks need options.http_flow.steps",
+		}, nil
+	}
+	flow := monitor.Options.HTTPFlow
+
+	var base *url.URL
+	if monitor.Target != "" {
+		u, err := TargetURL(monitor.Target, targetScheme(monitor.Target, "https"), monitor.Port)
+		if err != nil {
+			return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+		}
+		base = u
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{DialContext: familyDialer(ctx, &net.Dialer{})}
+	defer transport.CloseIdleConnections()
+
+	// Redirects are followed per step, so the policy is swapped before each
+	// request.
+	followRedirects := true
+	client := &http.Client{
+		Jar:       jar,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+
+	vars := make(map[string]string, len(flow.Variables))
+	maps.Copy(vars, flow.Variables)
+
+	result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+	steps := make([]flowStepResult, 0, len(flow.Steps))
+	start := time.Now()
+
+	for i, step := range flow.Steps {
+		followRedirects = step.FollowRedirects == nil || *step.FollowRedirects
+		sr := runFlowStep(ctx, client, base, step, vars)
+		if sr.Name == "" {
+			sr.Name = fmt.Sprintf("step %d", i+1)
+		}
+		steps = append(steps, sr)
+		result.StatusCode = sr.StatusCode
+
+		if sr.Error != "" {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("step %d (%s): %s", i+1, sr.Name, sr.Error)
+			result.Details["failed_step"] = i + 1
+			result.Details["failed_step_name"] = sr.Name
+			break
+		}
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	result.Details["steps"] = steps
+	result.Details["steps_total"] = len(flow.Steps)
+
+	// The flow's timings are the sum over its steps, so phase thresholds
+	// apply to the whole transaction.
+	timings := map[string]float64{}
+	for _, sr := range steps {
+		for k, v := range sr.Timings {
+			timings[k] = math.Round((timings[k]+v)*1000) / 1000
+		}
+	}
+	if len(timings) > 0 {
+		result.Details["timings"] = timings
+	}
+	return result, nil
+}
+
+// runFlowStep sends one step's request, checks its status and assertions,
+// and on success stores the step's extracted values in vars.
+func runFlowStep(ctx context.Context, client *http.Client, base *url.URL, step model.HTTPFlowStep, vars map[string]string) flowStepResult {
+	sr := flowStepResult{Name: step.Name, Method: http.MethodGet, URL: step.URL}
+	if step.Method != "" {
+		sr.Method = strings.ToUpper(step.Method)
+	}
+
+	opts, rawURL, err := expandFlowStep(step, vars)
+	if err != nil {
+		sr.Error = err.Error()
+		return sr
+	}
+	target, err := flowURL(base, rawURL)
+	if err != nil {
+		sr.Error = err.Error()
+		return sr
+	}
+
+	var phases httpPhases
+	req, err := newHTTPRequest(phases.withTrace(ctx), target.String(), opts)
+	if err != nil {
+		sr.Error = fmt.Sprintf("creating request: %v", err)
+		return sr
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		sr.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+		sr.Timings = phases.timings()
+		sr.Error = fmt.Sprintf("request failed: %v", err)
+		return sr
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	io.Copy(io.Discard, resp.Body)
+	phases.bodyRead()
+	sr.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	sr.Timings = phases.timings()
+	sr.StatusCode = resp.StatusCode
+	if err != nil {
+		sr.Error = fmt.Sprintf("reading body: %v", err)
+		return sr
+	}
+
+	if msg := statusError(resp.StatusCode, opts.AcceptedStatus, 0); msg != "" {
+		sr.Error = msg
+		return sr
+	}
+
+	if len(step.Assertions) > 0 {
+		assertions := make([]model.Assertion, len(step.Assertions))
+		for i, a := range step.Assertions {
+			if a.Value, err = expandFlowVars(a.Value, vars); err != nil {
+				sr.Error = fmt.Sprintf("assertion %d: %v", i, err)
+				return sr
+			}
+			assertions[i] = a
+		}
+		if failures := evaluateAssertions(assertions, resp, body, sr.Timings); len(failures) > 0 {
+			sr.Assertions = failures
+			sr.Error = fmt.Sprintf("assertion failed: %s", failures[0].Message)
+			if len(failures) > 1 {
+				sr.Error += fmt.Sprintf(" (and %d more)", len(failures)-1)
+			}
+			return sr
+		}
+	}
+
+	for _, ex := range step.Extract {
+		value, err := extractFlowValue(ex, resp, body)
+		if err != nil {
+			sr.Error = fmt.Sprintf("extracting %s: %v", ex.Variable, err)
+			return sr
+		}
+		vars[ex.Variable] = value
+		sr.Extracted = append(sr.Extracted, ex.Variable)
+	}
+	return sr
+}
+
+// expandFlowStep returns the step's request options and URL with variables
+// substituted.
+func expandFlowStep(step model.HTTPFlowStep, vars map[string]string) (*model.HTTPOptions, string, error) {
+	opts := step.HTTPOptions
+	fields := []*string{&opts.Body, &opts.BearerToken, &opts.BasicAuthUser, &opts.BasicAuthPassword}
+	for _, f := range fields {
+		v, err := expandFlowVars(*f, vars)
+		if err != nil {
+			return nil, "", err
+		}
+		*f = v
+	}
+
+	if len(step.Headers) > 0 {
+		opts.Headers = make(map[string]string, len(step.Headers))
+		for k, v := range step.Headers {
+			expanded, err := expandFlowVars(v, vars)
+			if err != nil {
+				return nil, "", fmt.Errorf("header %s: %w", k, err)
+			}
+			opts.Headers[k] = expanded
+		}
+	}
+
+	rawURL, err := expandFlowVars(step.URL, vars)
+	if err != nil {
+		return nil, "", fmt.Errorf("url: %w", err)
+	}
+	return &opts, rawURL, nil
+}
+
+// flowURL resolves a step URL against the monitor target the way a browser
+// resolves a link. An empty URL requests the target itself.
+func flowURL(base *url.URL, raw string) (*url.URL, error) {
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+		}
+		return u, nil
+	}
+	if base == nil {
+		return nil, fmt.Errorf("relative url %q needs a monitor target", raw)
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	return base.ResolveReference(ref), nil
+}
+
+// extractFlowValue reads the value an extractor names from a response.
+func extractFlowValue(ex model.FlowExtractor, resp *http.Response, body []byte) (string, error) {
+	switch ex.Source {
+	case model.AssertSourceJSON:
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("response is not valid JSON: %v", err)
+		}
+		v, ok, err := lookupJSON(doc, ex.Property)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("json %q is missing", ex.Property)
+		}
+		return jsonString(v), nil
+	case model.AssertSourceHeader:
+		v := resp.Header.Get(ex.Property)
+		if v == "" {
+			return "", fmt.Errorf("header %q is missing", ex.Property)
+		}
+		return v, nil
+	case model.AssertSourceBody:
+		re, err := regexp.Compile(ex.Property)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex: %v", err)
+		}
+		m := re.FindSubmatch(body)
+		if m == nil {
+			return "", fmt.Errorf("no match for /%s/", ex.Property)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	}
+	return "", fmt.Errorf("unknown source %q", ex.Source)
+}
+
+// flowVarPattern matches {{name}} and {{env.NAME}} references.
+var flowVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?)\s*\}\}`)
+
+// flowVarName is the form of variable names defined by a flow.
+var flowVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// expandFlowVars substitutes variable references in s. Environment
+// references are read on the checking node, so secrets can stay out of the
+// monitor definition, but only for the variables the node's secrets.env
+// config lists.
+func expandFlowVars(s string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	var failure error
+	out := flowVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := flowVarPattern.FindStringSubmatch(ref)[1]
+		if env, ok := strings.CutPrefix(name, "env."); ok {
+			err := secretEnvAllowed(env)
+			if err == nil {
+				if v, ok := os.LookupEnv(env); ok {
+					return v
+				}
+			}
+			if failure == nil {
+				failure = err
+			}
+		} else if v, ok := vars[name]; ok {
+			return v
+		}
+		if failure == nil {
+			failure = fmt.Errorf("undefined variable %q", name)
+		}
+		return ref
+	})
+	if failure != nil {
+		return "", failure
+	}
+	return out, nil
+}
+
+// ValidateHTTPFlowOptions checks a flow's steps, and that every variable a
+// step references is defined up front or extracted by an earlier step.
+func ValidateHTTPFlowOptions(target string, opts *model.HTTPFlowOptions) error {
+	if len(opts.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+
+	defined := make(map[string]bool, len(opts.Variables))
+	for name := range opts.Variables {
+		if !flowVarName.MatchString(name) {
+			return fmt.Errorf("variables: invalid name %q", name)
+		}
+		defined[name] = true
+	}
+
+	for i, step := range opts.Steps {
+		if err := validateFlowStep(target, step, defined); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		for _, ex := range step.Extract {
+			defined[ex.Variable] = true
+		}
+	}
+	return nil
+}
+
+func validateFlowStep(target string, step model.HTTPFlowStep, defined map[string]bool) error {
+	switch strings.ToUpper(step.Method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+	default:
+		return fmt.Errorf("unsupported method %q", step.Method)
+	}
+	if target == "" && !strings.Contains(step.URL, "://") && !strings.HasPrefix(step.URL, "{{") {
+		return fmt.Errorf("url %q is relative but the monitor has no target", step.URL)
+	}
+	if step.AcceptedStatus != "" {
+		if _, err := ParseStatusRanges(step.AcceptedStatus); err != nil {
+			return fmt.Errorf("accepted_status: %w", err)
+		}
+	}
+	if err := ValidateAssertions(step.Assertions); err != nil {
+		return err
+	}
+
+	refs := []string{step.URL, step.Body, step.BearerToken, step.BasicAuthUser, step.BasicAuthPassword}
+	for _, v := range step.Headers {
+		refs = append(refs, v)
+	}
+	for _, a := range step.Assertions {
+		refs = append(refs, a.Value)
+	}
+	for _, s := range refs {
+		for _, m := range flowVarPattern.FindAllStringSubmatch(s, -1) {
+			if name := m[1]; !strings.HasPrefix(name, "env.") && !defined[name] {
+				return fmt.Errorf("undefined variable %q", name)
+			}
+		}
+	}
+
+	for _, ex := range step.Extract {
+		if !flowVarName.MatchString(ex.Variable) {
+			return fmt.Errorf("extract: invalid variable name %q", ex.Variable)
+		}
+		switch ex.Source {
+		case model.AssertSourceJSON:
+			if _, err := parseJSONPath(ex.Property); err != nil {
+				return fmt.Errorf("extract %s: %w", ex.Variable, err)
+			}
+		case model.AssertSourceHeader:
+			if ex.Property == "" {
+				return fmt.Errorf("extract %s: header extraction needs a header name in property", ex.Variable)
+			}
+		case model.AssertSourceBody:
+			if _, err := regexp.Compile(ex.Property); err != nil {
+				return fmt.Errorf("extract %s: invalid regex: %w", ex.Variable, err)
+			}
+		default:
+			return fmt.Errorf("extract %s: unknown source %q", ex.Variable, ex.Source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestExpandFlowVars(t *testing.T) {
+	t.Setenv("PINGMESH_TEST_KEY", "k3y")
+	t.Setenv("PINGMESH_TEST_OTHER", "other")
+	AllowSecrets([]string{"PINGMESH_TEST_KEY", "PINGMESH_TEST_UNSET"}, nil)
+	t.Cleanup(func() { AllowSecrets(nil, nil) })
+
+	vars := map[string]string{"token": "abc", "id": "42"}
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{"/items/{{id}}", "/items/42", ""},
+		{"Bearer {{ token }}", "Bearer abc", ""},
+		{"no references", "no references", ""},
+		{"{{token}}:{{env.PINGMESH_TEST_KEY}}", "abc:k3y", ""},
+		{"{{missing}}", "", `undefined variable "missing"`},
+		{"{{env.PINGMESH_TEST_OTHER}}", "", "not allowed by this node's secrets.env"},
+		{"{{env.PINGMESH_TEST_UNSET}}", "", `undefined variable "env.PINGMESH_TEST_UNSET"`},
+	}
+	for _, tt := range tests {
+		got, err := expandFlowVars(tt.in, vars)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expandFlowVars(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("expandFlowVars(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestHTTPFlowCheck(t *testing.T) {
+	t.Setenv("PINGMESH_TEST_KEY", "k3y")
+	AllowSecrets([]string{"PINGMESH_TEST_KEY"}, nil)
+	t.Cleanup(func() { AllowSecrets(nil, nil) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if u, p, ok := r.BasicAuth(); !ok || u != "monitor" || p != "k3y" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"session":{"token":"t-123"}}`))
+		case "/api/status":
+			if r.Header.Get("Authorization") != "Bearer t-123" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	login := model.HTTPFlowStep{
+		Name:        "login",
+		URL:         "/login",
+		HTTPOptions: model.HTTPOptions{Method: "POST", BasicAuthUser: "monitor", BasicAuthPassword: "{{env.PINGMESH_TEST_KEY}}"},
+		Extract:     []model.FlowExtractor{{Variable: "token", Source: model.AssertSourceJSON, Property: "$.session.token"}},
+	}
+	tests := []struct {
+		name       string
+		steps      []model.HTTPFlowStep
+		wantStatus model.CheckStatus
+		wantFailed int
+	}{
+		{"login then authenticated call", []model.HTTPFlowStep{
+			login,
+			{Name: "status", URL: "/api/status", HTTPOptions: model.HTTPOptions{BearerToken: "{{token}}"}},
+		}, model.StatusUp, 0},
+		{"wrong token fails the second step", []model.HTTPFlowStep{
+			login,
+			{Name: "status", URL: "/api/status", HTTPOptions: model.HTTPOptions{BearerToken: "other"}},
+		}, model.StatusDown, 2},
+		{"missing page fails the first step", []model.HTTPFlowStep{
+			{Name: "missing", URL: "/missing"},
+		}, model.StatusDown, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := &model.Monitor{
+				CheckType: model.CheckHTTPFlow,
+				Target:    srv.URL,
+				TimeoutMS: 2000,
+				Options:   &model.MonitorOptions{HTTPFlow: &model.HTTPFlowOptions{Steps: tt.steps}},
+			}
+			if err := ValidateHTTPFlowOptions(monitor.Target, monitor.Options.HTTPFlow); err != nil {
+				t.Fatal(err)
+			}
+			result, err := (&HTTPFlowChecker{}).Check(context.Background(), monitor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", result.Status, result.Error, tt.wantStatus)
+			}
+			if tt.wantFailed != 0 && result.Details["failed_step"] != tt.wantFailed {
+				t.Errorf("failed_step = %v, want %d", result.Details["failed_step"], tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPFlowOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		opts    model.HTTPFlowOptions
+		wantErr bool
+	}{
+		{"no steps", "https://example.com", model.HTTPFlowOptions{}, true},
+		{"relative url without target", "", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{{URL: "/login"}}}, true},
+		{"undefined variable", "https://example.com", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{{URL: "/items/{{id}}"}}}, true},
+		{"variable used before extraction", "https://example.com", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{
+			{URL: "/a", HTTPOptions: model.HTTPOptions{BearerToken: "{{token}}"}},
+			{URL: "/b", Extract: []model.FlowExtractor{{Variable: "token", Source: model.AssertSourceJSON, Property: "$.token"}}},
+		}}, true},
+		{"extracted variable", "https://example.com", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{
+			{URL: "/a", Extract: []model.FlowExtractor{{Variable: "token", Source: model.AssertSourceJSON, Property: "$.token"}}},
+			{URL: "/b", HTTPOptions: model.HTTPOptions{BearerToken: "{{token}}"}},
+		}}, false},
+		{"env reference", "https://example.com", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{
+			{URL: "/a", HTTPOptions: model.HTTPOptions{Headers: map[string]string{"X-Key": "{{env.API_KEY}}"}}},
+		}}, false},
+		{"unsupported method", "https://example.com", model.HTTPFlowOptions{Steps: []model.HTTPFlowStep{
+			{URL: "/a", HTTPOptions: model.HTTPOptions{Method: "TRACE"}},
+		}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHTTPFlowOptions(tt.target, &tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHTTPFlowOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// httpPhases records where the time of an HTTP request went, using
+// net/http/httptrace. Redirect hops add to the same totals, and phases a
+// request skipped, such as DNS for an IP target or connecting on a reused
+// connection, are left out.
+type httpPhases struct {
+	mu sync.Mutex
+
+	dnsStart, connStart, tlsStart time.Time
+	wroteAt, firstByte            time.Time
+
+	durations map[string]time.Duration
+}
+
+// withTrace returns ctx with a client trace that records into p.
+func (p *httpPhases) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.start(&p.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.done(&p.dnsStart, model.HTTPPhaseDNS)
+		},
+		// With several addresses the dialer may race connections; the
+		// phase runs from the first attempt to the first success.
+		ConnectStart: func(_, _ string) {
+			p.start(&p.connStart)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				p.done(&p.connStart, model.HTTPPhaseConnect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.start(&p.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.done(&p.tlsStart, model.HTTPPhaseTLS)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			p.mu.Lock()
+			p.wroteAt = time.Now()
+			p.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			p.firstByte = time.Now()
+			p.mu.Unlock()
+			p.done(&p.wroteAt, model.HTTPPhaseTTFB)
+		},
+	})
+}
+
+func (p *httpPhases) start(at *time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+// done adds the time since *at to phase and clears *at for the next hop.
+func (p *httpPhases) done(at *time.Time, phase string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if at.IsZero() {
+		return
+	}
+	if p.durations == nil {
+		p.durations = map[string]time.Duration{}
+	}
+	p.durations[phase] += time.Since(*at)
+	*at = time.Time{}
+}
+
+// bodyRead ends the transfer phase; call it once the final response's body
+// has been read.
+func (p *httpPhases) bodyRead() {
+	p.done(&p.firstByte, model.HTTPPhaseTransfer)
+}
+
+// timings returns the recorded phases in milliseconds, keyed "<phase>_ms",
+// or nil if the request never got as far as a DNS lookup or dial.
+func (p *httpPhases) timings() map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.durations) == 0 {
+		return nil
+	}
+	out := make(map[string]float64, len(p.durations))
+	for phase, d := range p.durations {
+		out[phase+"_ms"] = float64(d.Microseconds()) / 1000.0
+	}
+	return out
+}
+
+// addTimings records the phases in result details.
+func (p *httpPhases) addTimings(result *Result) {
+	t := p.timings()
+	if t == nil {
+		return
+	}
+	if result.Details == nil {
+		result.Details = map[string]any{}
+	}
+	result.Details["timings"] = t
+}
+
+// resultTimings returns the timings recorded on a result, if any.
+func resultTimings(result *Result) map[string]float64 {
+	t, _ := result.Details["timings"].(map[string]float64)
+	return t
+}
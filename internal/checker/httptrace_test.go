@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestHTTPPhases(t *testing.T) {
+	// The server takes 50ms to respond and another 50ms to send the body.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("<html>"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("</html>"))
+	}))
+	defer srv.Close()
+
+	m := &model.Monitor{Target: srv.URL, TimeoutMS: 5000}
+	result, err := (&HTTPChecker{checkType: model.CheckHTTP}).Check(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timings := resultTimings(result)
+	if timings == nil {
+		t.Fatalf("no timings in %v", result.Details)
+	}
+
+	// An IP target needs no DNS lookup and plain HTTP no TLS handshake.
+	for _, phase := range []string{model.HTTPPhaseDNS, model.HTTPPhaseTLS} {
+		if _, ok := timings[phase+"_ms"]; ok {
+			t.Errorf("%s phase recorded for a plain HTTP request to an IP", phase)
+		}
+	}
+	if _, ok := timings[model.HTTPPhaseConnect+"_ms"]; !ok {
+		t.Error("connect phase missing")
+	}
+	for _, phase := range []string{model.HTTPPhaseTTFB, model.HTTPPhaseTransfer} {
+		if ms := timings[phase+"_ms"]; ms < 45 {
+			t.Errorf("%s = %.1fms, want about 50ms", phase, ms)
+		}
+	}
+}
+
+func TestHTTPPhasesRedirects(t *testing.T) {
+	// Each hop waits 30ms before responding; the TTFB phase adds them up.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		}
+	}))
+	defer srv.Close()
+
+	m := &model.Monitor{Target: srv.URL + "/old", TimeoutMS: 5000}
+	result, _ := (&HTTPChecker{checkType: model.CheckHTTP}).Check(context.Background(), m)
+	if ms := resultTimings(result)[model.HTTPPhaseTTFB+"_ms"]; ms < 55 {
+		t.Errorf("ttfb over two hops = %.1fms, want about 60ms", ms)
+	}
+}
+
+func TestHTTPPhasesFailedRequest(t *testing.T) {
+	m := &model.Monitor{Target: "http://127.0.0.1:1", TimeoutMS: 1000}
+	result, _ := (&HTTPChecker{checkType: model.CheckHTTP}).Check(context.Background(), m)
+	if result.Status != model.StatusDown {
+		t.Fatalf("status = %s, want down", result.Status)
+	}
+	if _, ok := resultTimings(result)[model.HTTPPhaseTTFB+"_ms"]; ok {
+		t.Error("ttfb recorded for a request that never connected")
+	}
+}
+
+func TestApplyLatencyThresholdsPhases(t *testing.T) {
+	opts := &model.MonitorOptions{Latency: &model.LatencyOptions{
+		WarnMS: 1000,
+		Phases: map[string]model.PhaseThreshold{
+			model.HTTPPhaseTTFB:    {WarnMS: 200, CriticalMS: 800},
+			model.HTTPPhaseConnect: {CriticalMS: 100},
+		},
+	}}
+	tests := []struct {
+		name    string
+		timings map[string]float64
+		want    model.CheckStatus
+	}{
+		{"within thresholds", map[string]float64{"connect_ms": 5, "ttfb_ms": 150}, model.StatusUp},
+		{"slow ttfb", map[string]float64{"connect_ms": 5, "ttfb_ms": 300}, model.StatusDegraded},
+		{"very slow ttfb", map[string]float64{"connect_ms": 5, "ttfb_ms": 900}, model.StatusDown},
+		{"slow connect", map[string]float64{"connect_ms": 150, "ttfb_ms": 10}, model.StatusDown},
+		{"phase without a threshold", map[string]float64{"transfer_ms": 900}, model.StatusUp},
+		{"no timings", nil, model.StatusUp},
+	}
+	for _, tt := range tests {
+		result := &Result{Status: model.StatusUp, LatencyMS: 500, Details: map[string]any{}}
+		if tt.timings != nil {
+			result.Details["timings"] = tt.timings
+		}
+		ApplyLatencyThresholds(result, &model.Monitor{Options: opts})
+		if result.Status != tt.want {
+			t.Errorf("%s: status = %s (%s), want %s", tt.name, result.Status, result.Error, tt.want)
+		}
+	}
+}
@@ -2,13 +2,24 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"syscall"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/model"
 	probing "github.com/prometheus-community/pro-bing"
 )
 
+const (
+	// minICMPSize is the smallest payload pro-bing can send; it carries the
+	// timestamp and tracker used to match replies.
+	minICMPSize = 24
+
+	// defaultICMPIntervalMS is pro-bing's wait between echo requests.
+	defaultICMPIntervalMS = 1000
+)
+
 // ICMPChecker performs ICMP ping checks.
 type ICMPChecker struct{}
 
@@ -19,23 +30,41 @@ func (c *ICMPChecker) Type() model.CheckType {
 func (c *ICMPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
 	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
 
-	pinger, err := probing.NewPinger(monitor.Target)
+	opts := &model.ICMPOptions{}
+	if monitor.Options != nil && monitor.Options.ICMP != nil {
+		opts = monitor.Options.ICMP
+	}
+
+	info := dialInfoFrom(ctx)
+	pinger := probing.New(monitor.Target)
+	pinger.SetNetwork(info.network("ip"))
+	err := pinger.Resolve()
 	if err != nil {
 		return &Result{
 			Status: model.StatusDown,
 			Error:  fmt.Sprintf("creating pinger: %v", err),
 		}, nil
 	}
+	info.record(pinger.IPAddr().IP)
 
-	pinger.Count = 1
+	pinger.Count = max(opts.Count, 1)
+	if opts.IntervalMS > 0 {
+		pinger.Interval = time.Duration(opts.IntervalMS) * time.Millisecond
+	}
+	if opts.Size > 0 {
+		pinger.Size = opts.Size
+	}
 	pinger.Timeout = timeout
-	pinger.SetPrivileged(false) // Use UDP sockets (unprivileged)
+	pinger.RecordRtts = true
+	pinger.SetDoNotFragment(opts.DontFragment)
+	// Unprivileged mode uses UDP ICMP sockets, which need no capabilities.
+	pinger.SetPrivileged(opts.Privileged)
 
 	err = pinger.RunWithContext(ctx)
 	if err != nil {
 		return &Result{
 			Status: model.StatusDown,
-			Error:  fmt.Sprintf("ping failed: %v", err),
+			Error:  pingError(err, opts, pinger.Size),
 		}, nil
 	}
 
@@ -48,20 +77,96 @@ func (c *ICMPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Resul
 			Details: map[string]any{
 				"packets_sent": stats.PacketsSent,
 				"packets_recv": stats.PacketsRecv,
+				"packet_loss":  stats.PacketLoss,
 			},
 		}, nil
 	}
 
-	return &Result{
+	result := &Result{
 		Status:    model.StatusUp,
-		LatencyMS: float64(stats.AvgRtt.Microseconds()) / 1000.0,
+		LatencyMS: durationMS(stats.AvgRtt),
 		Details: map[string]any{
-			"packets_sent": stats.PacketsSent,
-			"packets_recv": stats.PacketsRecv,
-			"packet_loss":  stats.PacketLoss,
-			"min_rtt_ms":   float64(stats.MinRtt.Microseconds()) / 1000.0,
-			"max_rtt_ms":   float64(stats.MaxRtt.Microseconds()) / 1000.0,
-			"avg_rtt_ms":   float64(stats.AvgRtt.Microseconds()) / 1000.0,
+			"packets_sent":  stats.PacketsSent,
+			"packets_recv":  stats.PacketsRecv,
+			"packet_loss":   stats.PacketLoss,
+			"min_rtt_ms":    durationMS(stats.MinRtt),
+			"max_rtt_ms":    durationMS(stats.MaxRtt),
+			"avg_rtt_ms":    durationMS(stats.AvgRtt),
+			"stddev_rtt_ms": durationMS(stats.StdDevRtt),
+			"jitter_ms":     jitterMS(stats.Rtts),
+			"size":          pinger.Size,
 		},
-	}, nil
+	}
+
+	switch {
+	case opts.LossCriticalPercent > 0 && stats.PacketLoss >= opts.LossCriticalPercent:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("packet loss %.1f%% reached critical threshold %.1f%%", stats.PacketLoss, opts.LossCriticalPercent)
+	case opts.LossWarnPercent > 0 && stats.PacketLoss >= opts.LossWarnPercent:
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("packet loss %.1f%% reached warning threshold %.1f%%", stats.PacketLoss, opts.LossWarnPercent)
+	}
+
+	return result, nil
+}
+
+// pingError explains the common setup failures.
+func pingError(err error, opts *model.ICMPOptions, size int) string {
+	switch {
+	case errors.Is(err, syscall.EMSGSIZE) && opts.DontFragment:
+		return fmt.Sprintf("ping failed: %d byte payload exceeds the MTU with DF set", size)
+	case errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES):
+		if opts.Privileged {
+			return fmt.Sprintf("ping failed: %v (privileged mode needs root or CAP_NET_RAW)", err)
+		}
+		return fmt.Sprintf("ping failed: %v (unprivileged mode needs net.ipv4.ping_group_range to include the agent's group)", err)
+	}
+	return fmt.Sprintf("ping failed: %v", err)
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
+// jitterMS is the mean absolute difference between consecutive RTTs.
+func jitterMS(rtts []time.Duration) float64 {
+	if len(rtts) < 2 {
+		return 0
+	}
+	var total time.Duration
+	for i := 1; i < len(rtts); i++ {
+		total += (rtts[i] - rtts[i-1]).Abs()
+	}
+	return durationMS(total / time.Duration(len(rtts)-1))
+}
+
+// ValidateICMPOptions checks packet settings and loss thresholds against a
+// monitor with the given timeout. All echo requests must go out with an
+// interval left for the last reply before the timeout, as echoes still in
+// flight when the check ends count as lost.
+func ValidateICMPOptions(opts *model.ICMPOptions, timeoutMS int64) error {
+	if opts.Count < 0 || opts.IntervalMS < 0 {
+		return fmt.Errorf("count and interval_ms must not be negative")
+	}
+	if opts.Count > 1 {
+		interval := opts.IntervalMS
+		if interval == 0 {
+			interval = defaultICMPIntervalMS
+		}
+		if needed := int64(opts.Count) * interval; needed >= timeoutMS {
+			return fmt.Errorf("%d packets at interval_ms %d take %dms, which must be below the monitor timeout of %dms",
+				opts.Count, interval, needed, timeoutMS)
+		}
+	}
+	if opts.Size != 0 && opts.Size < minICMPSize {
+		return fmt.Errorf("size must be at least %d bytes", minICMPSize)
+	}
+	if opts.LossWarnPercent < 0 || opts.LossWarnPercent > 100 ||
+		opts.LossCriticalPercent < 0 || opts.LossCriticalPercent > 100 {
+		return fmt.Errorf("loss thresholds must be between 0 and 100")
+	}
+	if opts.LossWarnPercent > 0 && opts.LossCriticalPercent > 0 && opts.LossWarnPercent >= opts.LossCriticalPercent {
+		return fmt.Errorf("loss_warn_percent must be below loss_critical_percent")
+	}
+	return nil
 }
@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestValidateICMPOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    model.ICMPOptions
+		timeout int64
+		wantErr bool
+	}{
+		{"defaults", model.ICMPOptions{}, 5000, false},
+		{"packets fit the timeout", model.ICMPOptions{Count: 4, IntervalMS: 200}, 1000, false},
+		{"default interval too slow", model.ICMPOptions{Count: 5}, 5000, true},
+		{"packets exceed the timeout", model.ICMPOptions{Count: 10, IntervalMS: 500}, 5000, true},
+		{"negative count", model.ICMPOptions{Count: -1}, 5000, true},
+		{"size below minimum", model.ICMPOptions{Size: 8}, 5000, true},
+		{"large size", model.ICMPOptions{Size: 1472, DontFragment: true}, 5000, false},
+		{"loss thresholds", model.ICMPOptions{Count: 4, IntervalMS: 100, LossWarnPercent: 10, LossCriticalPercent: 50}, 5000, false},
+		{"loss over 100", model.ICMPOptions{LossCriticalPercent: 150}, 5000, true},
+		{"warn above critical", model.ICMPOptions{LossWarnPercent: 50, LossCriticalPercent: 25}, 5000, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateICMPOptions(&tt.opts, tt.timeout); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateICMPOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestJitterMS(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		rtts []time.Duration
+		want float64
+	}{
+		{nil, 0},
+		{[]time.Duration{10 * ms}, 0},
+		{[]time.Duration{10 * ms, 10 * ms, 10 * ms}, 0},
+		{[]time.Duration{10 * ms, 20 * ms, 10 * ms}, 10},
+		{[]time.Duration{10 * ms, 12 * ms, 18 * ms, 14 * ms}, 4},
+	}
+	for _, tt := range tests {
+		if got := jitterMS(tt.rtts); got != tt.want {
+			t.Errorf("jitterMS(%v) = %v, want %v", tt.rtts, got, tt.want)
+		}
+	}
+}
+
+func TestPingError(t *testing.T) {
+	tests := []struct {
+		err  error
+		opts model.ICMPOptions
+		want string
+	}{
+		{fmt.Errorf("write: %w", syscall.EMSGSIZE), model.ICMPOptions{DontFragment: true}, "exceeds the MTU with DF set"},
+		{fmt.Errorf("socket: %w", syscall.EPERM), model.ICMPOptions{}, "ping_group_range"},
+		{fmt.Errorf("socket: %w", syscall.EACCES), model.ICMPOptions{Privileged: true}, "CAP_NET_RAW"},
+		{fmt.Errorf("network unreachable"), model.ICMPOptions{}, "ping failed: network unreachable"},
+	}
+	for _, tt := range tests {
+		if got := pingError(tt.err, &tt.opts, 1500); !strings.Contains(got, tt.want) {
+			t.Errorf("pingError(%v) = %q, want it to mention %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestICMPCheckLoopback(t *testing.T) {
+	m := &model.Monitor{Target: "127.0.0.1", TimeoutMS: 2000,
+		Options: &model.MonitorOptions{ICMP: &model.ICMPOptions{Count: 3, IntervalMS: 50}}}
+	result, err := (&ICMPChecker{}).Check(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(result.Error, "ping_group_range") || strings.Contains(result.Error, "not permitted") {
+		t.Skipf("unprivileged ICMP sockets are not available: %s", result.Error)
+	}
+	if result.Status != model.StatusUp {
+		t.Fatalf("status = %s (%s), want up", result.Status, result.Error)
+	}
+	if result.Details["packets_recv"] != 3 {
+		t.Errorf("packets_recv = %v, want 3", result.Details["packets_recv"])
+	}
+}
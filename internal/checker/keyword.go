@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -12,8 +13,18 @@ import (
 	"github.com/pingmesh/pingmesh/internal/model"
 )
 
-// KeywordChecker performs HTTP requests and checks for a keyword in the response body.
-type KeywordChecker struct{}
+// KeywordChecker performs HTTP(S) requests and checks the response body for a
+// keyword and any configured assertions, and optionally for changes to the
+// page content.
+type KeywordChecker struct {
+	baselines baselineSet // page content, for content options
+}
+
+// NewKeywordChecker returns a keyword checker that keeps the content
+// baselines of content-change monitors in bs.
+func NewKeywordChecker(bs BaselineStore) *KeywordChecker {
+	return &KeywordChecker{baselines: baselineSet{store: bs}}
+}
 
 func (c *KeywordChecker) Type() model.CheckType {
 	return model.CheckHTTPKeyword
@@ -22,30 +33,29 @@ func (c *KeywordChecker) Type() model.CheckType {
 func (c *KeywordChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
 	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
 
-	// Strip scheme if user included it in the target
-	target := monitor.Target
-	target = strings.TrimPrefix(target, "https://")
-	target = strings.TrimPrefix(target, "http://")
-	target = strings.TrimRight(target, "/")
-
-	port := monitor.Port
-	if port == 0 {
-		port = 80
+	// Keyword monitors have no separate HTTPS type, so the target's own
+	// scheme decides; bare hosts default to plain HTTP.
+	targetURL, err := TargetURL(monitor.Target, targetScheme(monitor.Target, "http"), monitor.Port)
+	if err != nil {
+		return &Result{
+			Status: model.StatusDown,
+			Error:  err.Error(),
+		}, nil
 	}
 
-	url := fmt.Sprintf("http://%s:%d", target, port)
-
 	client := &http.Client{
 		Timeout: timeout,
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
 			},
+			DialContext:       familyDialer(ctx, &net.Dialer{}),
 			DisableKeepAlives: true,
 		},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	var phases httpPhases
+	req, err := http.NewRequestWithContext(phases.withTrace(ctx), http.MethodGet, targetURL.String(), nil)
 	if err != nil {
 		return &Result{
 			Status: model.StatusDown,
@@ -59,16 +69,19 @@ func (c *KeywordChecker) Check(ctx context.Context, monitor *model.Monitor) (*Re
 	latency := float64(time.Since(start).Microseconds()) / 1000.0
 
 	if err != nil {
-		return &Result{
+		result := &Result{
 			Status:    model.StatusDown,
 			LatencyMS: latency,
 			Error:     fmt.Sprintf("request failed: %v", err),
-		}, nil
+		}
+		phases.addTimings(result)
+		return result, nil
 	}
 	defer resp.Body.Close()
 
 	// Read body (limit to 1MB to prevent memory issues)
 	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	phases.bodyRead()
 	if err != nil {
 		return &Result{
 			Status:     model.StatusDown,
@@ -78,23 +91,34 @@ func (c *KeywordChecker) Check(ctx context.Context, monitor *model.Monitor) (*Re
 		}, nil
 	}
 
-	body := string(bodyBytes)
-	keywordFound := strings.Contains(body, monitor.ExpectedKeyword)
-
 	result := &Result{
 		Status:     model.StatusUp,
 		LatencyMS:  latency,
 		StatusCode: resp.StatusCode,
 		Details: map[string]any{
-			"status_code":   resp.StatusCode,
-			"keyword_found": keywordFound,
-			"body_length":   len(bodyBytes),
+			"status_code": resp.StatusCode,
+			"body_length": len(bodyBytes),
 		},
 	}
+	phases.addTimings(result)
+
+	if monitor.ExpectedKeyword != "" {
+		keywordFound := strings.Contains(string(bodyBytes), monitor.ExpectedKeyword)
+		result.Details["keyword_found"] = keywordFound
+		if !keywordFound {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("keyword %q not found in response", monitor.ExpectedKeyword)
+		}
+	}
 
-	if !keywordFound {
-		result.Status = model.StatusDown
-		result.Error = fmt.Sprintf("keyword %q not found in response", monitor.ExpectedKeyword)
+	if monitor.Options != nil && len(monitor.Options.Assertions) > 0 {
+		applyAssertions(result, monitor.Options.Assertions, resp, bodyBytes)
+	}
+
+	// Error pages are not compared, so an outage is neither learned as the
+	// baseline nor reported as a content change.
+	if monitor.Options != nil && monitor.Options.Content != nil && resp.StatusCode < 400 {
+		c.compareContent(ctx, result, monitor, monitor.Options.Content, bodyBytes, resp.Header.Get("Content-Type"))
 	}
 
 	if resp.StatusCode >= 400 {
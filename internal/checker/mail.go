@@ -0,0 +1,342 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// mailPorts holds the plaintext/STARTTLS and implicit TLS ports per protocol.
+var mailPorts = map[model.CheckType][2]int{
+	model.CheckSMTP: {25, 465},
+	model.CheckIMAP: {143, 993},
+	model.CheckPOP3: {110, 995},
+}
+
+// mailOptions returns the monitor's mail options, or defaults.
+func mailOptions(monitor *model.Monitor) *model.MailOptions {
+	if monitor.Options != nil && monitor.Options.Mail != nil {
+		return monitor.Options.Mail
+	}
+	return &model.MailOptions{}
+}
+
+// mailEndpoint resolves the port and TLS mode for a mail check. An empty
+// mode means implicit TLS on the well-known TLS ports and STARTTLS when the
+// server offers it elsewhere.
+func mailEndpoint(checkType model.CheckType, port int, mode string) (int, string) {
+	ports := mailPorts[checkType]
+	if port == 0 {
+		port = ports[0]
+		if mode == model.MailTLSImplicit {
+			port = ports[1]
+		}
+	}
+	if mode == "" && (port == 465 || port == 993 || port == 995) {
+		mode = model.MailTLSImplicit
+	}
+	return port, mode
+}
+
+// dialMail connects to a mail server, wrapping the connection in TLS for
+// implicit mode.
+func dialMail(ctx context.Context, address, mode string, cfg *tls.Config, timeout time.Duration) (net.Conn, error) {
+	conn, err := dialContext(ctx, &net.Dialer{Timeout: timeout}, "tcp", address)
+	if err != nil || mode != model.MailTLSImplicit {
+		return conn, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func mailTLSConfig(opts *model.MailOptions, host string) *tls.Config {
+	serverName := opts.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	return &tls.Config{ServerName: serverName, InsecureSkipVerify: opts.SkipVerify}
+}
+
+// recordTLS adds the negotiated TLS version to the result details.
+func recordTLS(result *Result, conn net.Conn) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		result.Details["tls_version"] = tls.VersionName(tlsConn.ConnectionState().Version)
+	}
+}
+
+// mailAuthAllowed returns an error instead of letting a session log in
+// over a connection without TLS, unless the monitor's tls mode is
+// explicitly none, or with credentials that would break out of the
+// command they are sent in.
+func mailAuthAllowed(conn net.Conn, mode, protocol, username, password string) error {
+	if strings.ContainsAny(username+password, "\r\n") {
+		return fmt.Errorf("%s credentials must not contain CR or LF", protocol)
+	}
+	if _, ok := conn.(*tls.Conn); !ok && mode != model.MailTLSNone {
+		return fmt.Errorf("%s server did not negotiate TLS; refusing to send credentials in cleartext (set tls to none to allow it)", protocol)
+	}
+	return nil
+}
+
+func sinceMS(t time.Time) float64 {
+	return float64(time.Since(t).Microseconds()) / 1000.0
+}
+
+// IMAPChecker logs in to an IMAP server and examines a mailbox.
+type IMAPChecker struct{}
+
+func (c *IMAPChecker) Type() model.CheckType {
+	return model.CheckIMAP
+}
+
+func (c *IMAPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := mailOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	host := strings.Trim(monitor.Target, "[]")
+	port, mode := mailEndpoint(model.CheckIMAP, monitor.Port, opts.TLS)
+
+	start := time.Now()
+	result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+	err = imapSession(ctx, net.JoinHostPort(host, strconv.Itoa(port)), mode, mailTLSConfig(opts, host),
+		opts.Username, password, timeout, result, func(ic *imapConn) error {
+			mailbox := opts.Mailbox
+			if mailbox == "" {
+				mailbox = "INBOX"
+			}
+			lines, err := ic.cmd("EXAMINE " + imapQuote(mailbox))
+			if err != nil {
+				return fmt.Errorf("EXAMINE %s: %v", mailbox, err)
+			}
+			if n, ok := imapExists(lines); ok {
+				result.Details["messages"] = n
+			}
+			return nil
+		})
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = err.Error()
+	}
+	result.LatencyMS = sinceMS(start)
+	return result, nil
+}
+
+// imapSession connects, negotiates TLS and, given a username, logs in and
+// runs fn. Stage timings are recorded in result details.
+func imapSession(ctx context.Context, address, mode string, cfg *tls.Config, username, password string,
+	timeout time.Duration, result *Result, fn func(*imapConn) error) error {
+	stage := time.Now()
+	conn, err := dialMail(ctx, address, mode, cfg, timeout)
+	if err != nil {
+		return fmt.Errorf("imap connect failed: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	ic := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := ic.readLine()
+	if err != nil {
+		return fmt.Errorf("imap greeting: %v", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		return fmt.Errorf("imap greeting: %s", truncateActual(greeting))
+	}
+	result.Details["connect_ms"] = sinceMS(stage)
+
+	if mode != model.MailTLSImplicit && mode != model.MailTLSNone {
+		stage = time.Now()
+		lines, err := ic.cmd("CAPABILITY")
+		if err != nil {
+			return fmt.Errorf("CAPABILITY: %v", err)
+		}
+		offered := strings.Contains(strings.ToUpper(strings.Join(lines, " ")), "STARTTLS")
+		if !offered && mode == model.MailTLSStartTLS {
+			return fmt.Errorf("imap server does not advertise STARTTLS")
+		}
+		if offered {
+			if _, err := ic.cmd("STARTTLS"); err != nil {
+				return fmt.Errorf("STARTTLS: %v", err)
+			}
+			tlsConn := tls.Client(conn, cfg)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				return fmt.Errorf("tls handshake failed: %v", err)
+			}
+			ic.conn, ic.r = tlsConn, bufio.NewReader(tlsConn)
+			result.Details["tls_ms"] = sinceMS(stage)
+		}
+	}
+	recordTLS(result, ic.conn)
+
+	if username == "" {
+		ic.cmd("LOGOUT")
+		return nil
+	}
+
+	if err := mailAuthAllowed(ic.conn, mode, "imap", username, password); err != nil {
+		return err
+	}
+
+	stage = time.Now()
+	if _, err := ic.cmd("LOGIN " + imapQuote(username) + " " + imapQuote(password)); err != nil {
+		return fmt.Errorf("imap login failed: %v", err)
+	}
+	result.Details["auth_ms"] = sinceMS(stage)
+
+	err = fn(ic)
+	ic.cmd("LOGOUT")
+	return err
+}
+
+// imapConn is a minimal IMAP4rev1 client issuing one command at a time.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// cmd sends a tagged command and returns the untagged response lines. A
+// NO or BAD completion is returned as an error.
+func (ic *imapConn) cmd(command string) ([]string, error) {
+	ic.tag++
+	tag := fmt.Sprintf("a%d", ic.tag)
+	if _, err := io.WriteString(ic.conn, tag+" "+command+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for {
+		line, err := ic.readLine()
+		if err != nil {
+			return lines, err
+		}
+		if !strings.HasPrefix(line, tag+" ") {
+			lines = append(lines, line)
+			continue
+		}
+		status := strings.TrimPrefix(line, tag+" ")
+		if strings.HasPrefix(status, "OK") {
+			return lines, nil
+		}
+		return lines, fmt.Errorf("%s", truncateActual(status))
+	}
+}
+
+// readLine reads one response line, inlining any literals ({n} followed by
+// n bytes) it contains.
+func (ic *imapConn) readLine() (string, error) {
+	var b strings.Builder
+	for {
+		line, err := ic.r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		b.WriteString(line)
+
+		open := strings.LastIndexByte(line, '{')
+		if open < 0 || !strings.HasSuffix(line, "}") {
+			return b.String(), nil
+		}
+		n, err := strconv.Atoi(line[open+1 : len(line)-1])
+		if err != nil || n > maxTCPRead {
+			return b.String(), nil
+		}
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(ic.r, literal); err != nil {
+			return "", err
+		}
+		b.Write(literal)
+	}
+}
+
+// imapQuote returns s as an IMAP quoted string.
+func imapQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// imapExists finds the message count in SELECT/EXAMINE responses.
+func imapExists(lines []string) (int, bool) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "*" && strings.EqualFold(fields[2], "EXISTS") {
+			n, err := strconv.Atoi(fields[1])
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// ValidateMailOptions checks TLS modes, credentials and round-trip settings.
+func ValidateMailOptions(checkType model.CheckType, opts *model.MailOptions) error {
+	if err := validateMailTLS(opts.TLS); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	if err := validateMailCredentials(opts.Credentials); err != nil {
+		return err
+	}
+	rt := opts.RoundTrip
+	if rt == nil {
+		return nil
+	}
+	if checkType != model.CheckSMTP {
+		return fmt.Errorf("round_trip is only supported on smtp checks")
+	}
+	if rt.From == "" || rt.To == "" || rt.IMAPHost == "" {
+		return fmt.Errorf("round_trip needs from, to and imap_host")
+	}
+	if rt.IMAP.Username == "" {
+		return fmt.Errorf("round_trip.imap needs a username")
+	}
+	if err := validateMailTLS(rt.IMAPTLS); err != nil {
+		return fmt.Errorf("round_trip.imap_tls: %w", err)
+	}
+	if err := validateMailCredentials(rt.IMAP); err != nil {
+		return fmt.Errorf("round_trip.imap: %w", err)
+	}
+	if rt.PollIntervalMS < 0 {
+		return fmt.Errorf("round_trip.poll_interval_ms must not be negative")
+	}
+	return nil
+}
+
+// validateMailCredentials also rejects line breaks, which would end the
+// LOGIN, USER or PASS command they are sent in.
+func validateMailCredentials(c model.Credentials) error {
+	if err := validateCredentials(c); err != nil {
+		return err
+	}
+	if strings.ContainsAny(c.Username+c.Password, "\r\n") {
+		return fmt.Errorf("username and password must not contain CR or LF")
+	}
+	return nil
+}
+
+func validateMailTLS(mode string) error {
+	switch mode {
+	case "", model.MailTLSNone, model.MailTLSStartTLS, model.MailTLSImplicit:
+		return nil
+	}
+	return fmt.Errorf("must be none, starttls or implicit")
+}
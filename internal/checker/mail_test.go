@@ -0,0 +1,221 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// serveLines runs a line-based TCP server on a local port for one test. It
+// writes greeting to each connection, then answers every line with the
+// reply from respond until the reply is empty. It returns the port.
+func serveLines(t *testing.T, greeting string, respond func(line string) string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte(greeting))
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					reply := respond(strings.TrimRight(line, "\r\n"))
+					if reply == "" {
+						return
+					}
+					conn.Write([]byte(reply))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// commandLog records the lines a test server received.
+type commandLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *commandLog) add(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
+
+func (l *commandLog) contains(s string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pop3Server answers like a POP3 server without STLS, recording the
+// commands it received.
+func pop3Server(t *testing.T, received *commandLog) int {
+	return serveLines(t, "+OK ready\r\n", func(line string) string {
+		received.add(line)
+		switch strings.Fields(line + " ")[0] {
+		case "CAPA":
+			return "+OK\r\nUSER\r\n.\r\n"
+		case "STAT":
+			return "+OK 3 1200\r\n"
+		case "QUIT":
+			return ""
+		}
+		return "+OK\r\n"
+	})
+}
+
+// imapServer answers like an IMAP server without STARTTLS.
+func imapServer(t *testing.T, received *commandLog) int {
+	return serveLines(t, "* OK ready\r\n", func(line string) string {
+		received.add(line)
+		tag, command, _ := strings.Cut(line, " ")
+		switch strings.Fields(command + " ")[0] {
+		case "CAPABILITY":
+			return "* CAPABILITY IMAP4rev1\r\n" + tag + " OK done\r\n"
+		case "EXAMINE":
+			return "* 5 EXISTS\r\n" + tag + " OK done\r\n"
+		case "LOGOUT":
+			return "* BYE\r\n" + tag + " OK done\r\n"
+		}
+		return tag + " OK done\r\n"
+	})
+}
+
+func mailMonitor(checkType model.CheckType, port int, tlsMode string) *model.Monitor {
+	return &model.Monitor{
+		ID:        "mail",
+		CheckType: checkType,
+		Target:    "127.0.0.1",
+		Port:      port,
+		TimeoutMS: 2000,
+		Options: &model.MonitorOptions{Mail: &model.MailOptions{
+			Credentials: model.Credentials{Username: "monitor", Password: "secret"},
+			TLS:         tlsMode,
+		}},
+	}
+}
+
+func TestMailCleartextLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		checker   Checker
+		server    func(*testing.T, *commandLog) int
+		tls       string
+		wantUp    bool
+		wantCount int
+	}{
+		{"pop3 refuses without tls", &POP3Checker{}, pop3Server, "", false, 0},
+		{"pop3 with tls none", &POP3Checker{}, pop3Server, model.MailTLSNone, true, 3},
+		{"imap refuses without tls", &IMAPChecker{}, imapServer, "", false, 0},
+		{"imap with tls none", &IMAPChecker{}, imapServer, model.MailTLSNone, true, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received commandLog
+			port := tt.server(t, &received)
+			monitor := mailMonitor(tt.checker.Type(), port, tt.tls)
+
+			result, err := tt.checker.Check(context.Background(), monitor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if up := result.Status == model.StatusUp; up != tt.wantUp {
+				t.Fatalf("status = %s (%s), want up %v", result.Status, result.Error, tt.wantUp)
+			}
+			if !tt.wantUp {
+				if !strings.Contains(result.Error, "cleartext") {
+					t.Errorf("error = %q, want a cleartext refusal", result.Error)
+				}
+				if received.contains("secret") {
+					t.Error("password sent in cleartext")
+				}
+				return
+			}
+			if result.Details["messages"] != tt.wantCount {
+				t.Errorf("messages = %v, want %d", result.Details["messages"], tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestValidateMailOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   model.CheckType
+		opts    model.MailOptions
+		wantErr bool
+	}{
+		{"defaults", model.CheckIMAP, model.MailOptions{}, false},
+		{"unknown tls mode", model.CheckIMAP, model.MailOptions{TLS: "ssl"}, true},
+		{"two password sources", model.CheckPOP3, model.MailOptions{Credentials: model.Credentials{Password: "a", PasswordEnv: "B"}}, true},
+		{"line break in username", model.CheckPOP3, model.MailOptions{Credentials: model.Credentials{Username: "a\r\nDELE 1"}}, true},
+		{"line break in password", model.CheckPOP3, model.MailOptions{Credentials: model.Credentials{Username: "a", Password: "b\nQUIT"}}, true},
+		{"round trip on imap", model.CheckIMAP, model.MailOptions{RoundTrip: &model.MailRoundTrip{}}, true},
+		{"round trip without imap user", model.CheckSMTP, model.MailOptions{RoundTrip: &model.MailRoundTrip{
+			From: "a@example.com", To: "b@example.com", IMAPHost: "imap.example.com",
+		}}, true},
+		{"round trip imap line break", model.CheckSMTP, model.MailOptions{RoundTrip: &model.MailRoundTrip{
+			From: "a@example.com", To: "b@example.com", IMAPHost: "imap.example.com",
+			IMAP: model.Credentials{Username: "b", Password: "x\r\ny"},
+		}}, true},
+		{"round trip", model.CheckSMTP, model.MailOptions{RoundTrip: &model.MailRoundTrip{
+			From: "a@example.com", To: "b@example.com", IMAPHost: "imap.example.com",
+			IMAP: model.Credentials{Username: "b", PasswordEnv: "IMAP_PASSWORD"},
+		}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMailOptions(tt.check, &tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMailOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMailEndpoint(t *testing.T) {
+	tests := []struct {
+		check    model.CheckType
+		port     int
+		mode     string
+		wantPort int
+		wantMode string
+	}{
+		{model.CheckIMAP, 0, "", 143, ""},
+		{model.CheckIMAP, 0, model.MailTLSImplicit, 993, model.MailTLSImplicit},
+		{model.CheckPOP3, 995, "", 995, model.MailTLSImplicit},
+		{model.CheckSMTP, 465, "", 465, model.MailTLSImplicit},
+		{model.CheckSMTP, 587, model.MailTLSStartTLS, 587, model.MailTLSStartTLS},
+	}
+	for _, tt := range tests {
+		port, mode := mailEndpoint(tt.check, tt.port, tt.mode)
+		if port != tt.wantPort || mode != tt.wantMode {
+			t.Errorf("mailEndpoint(%s, %d, %q) = %d, %q, want %d, %q", tt.check, tt.port, tt.mode, port, mode, tt.wantPort, tt.wantMode)
+		}
+	}
+}
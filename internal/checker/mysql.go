@@ -0,0 +1,211 @@
+package checker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const defaultMySQLPort = 3306
+
+// mysqlNet is the driver network the checker dials through, so connections
+// follow the address family of the running check.
+const mysqlNet = "pingmesh-tcp"
+
+var registerMySQLDial sync.Once
+
+// MySQLChecker connects to MySQL or MariaDB, authenticates and runs a query.
+type MySQLChecker struct{}
+
+func (c *MySQLChecker) Type() model.CheckType {
+	return model.CheckMySQL
+}
+
+func (c *MySQLChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := databaseOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	host := strings.Trim(monitor.Target, "[]")
+	port := monitor.Port
+	if port == 0 {
+		port = defaultMySQLPort
+	}
+
+	registerMySQLDial.Do(func() {
+		mysql.RegisterDialContext(mysqlNet, func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialContext(ctx, &net.Dialer{}, "tcp", addr)
+		})
+	})
+	cfg := mysql.NewConfig()
+	cfg.Net = mysqlNet
+	cfg.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	cfg.User = opts.Username
+	cfg.Passwd = password
+	cfg.DBName = opts.Database
+	cfg.Timeout = timeout
+	cfg.TLS = databaseTLSConfig(opts.TLS, host)
+	// The driver logs failed connections itself; errors are in the result.
+	cfg.Logger = log.New(io.Discard, "", 0)
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: fmt.Sprintf("invalid connection settings: %v", err)}, nil
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		err = conn.PingContext(ctx)
+	}
+	connectMS := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: connectMS,
+			Error:     fmt.Sprintf("mysql connect failed: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+
+	result := &Result{
+		Status:  model.StatusUp,
+		Details: map[string]any{"connect_ms": connectMS},
+	}
+	var version string
+	if err := conn.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err == nil {
+		result.Details["server_version"] = version
+	}
+
+	query := opts.Query
+	if query == "" {
+		query = "SELECT 1"
+	}
+	queryStart := time.Now()
+	err = mysqlQuery(ctx, conn, query, result)
+	result.Details["query_ms"] = float64(time.Since(queryStart).Microseconds()) / 1000.0
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("query failed: %v", err)
+	} else if opts.Replication {
+		mysqlReplication(ctx, conn, opts, result)
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	return result, nil
+}
+
+// mysqlQuery runs the check query and records the first value returned.
+func mysqlQuery(ctx context.Context, conn *sql.Conn, query string, result *Result) error {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		if count == 0 {
+			if row, err := scanRowMap(rows); err == nil {
+				if cols, _ := rows.Columns(); len(cols) > 0 {
+					result.Details["query_result"] = truncateActual(row[cols[0]])
+				}
+			}
+		}
+		count++
+	}
+	result.Details["rows"] = count
+	return rows.Err()
+}
+
+// mysqlReplication records whether the server replicates from a source and,
+// if so, whether replication is running and how far it is behind.
+func mysqlReplication(ctx context.Context, conn *sql.Conn, opts *model.DatabaseOptions, result *Result) {
+	// SHOW REPLICA STATUS replaced SHOW SLAVE STATUS in MySQL 8.0.22;
+	// MariaDB and older servers only know the latter.
+	rows, err := conn.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = conn.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("reading replication status: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		result.Details["role"] = "primary"
+		return
+	}
+	status, err := scanRowMap(rows)
+	if err != nil {
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("reading replication status: %v", err)
+		return
+	}
+	result.Details["role"] = "replica"
+
+	ioRunning := firstColumn(status, "Replica_IO_Running", "Slave_IO_Running")
+	sqlRunning := firstColumn(status, "Replica_SQL_Running", "Slave_SQL_Running")
+	if ioRunning != "Yes" || sqlRunning != "Yes" {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("replication stopped (io thread %s, sql thread %s)", ioRunning, sqlRunning)
+		return
+	}
+
+	lag := firstColumn(status, "Seconds_Behind_Source", "Seconds_Behind_Master")
+	if lagSec, err := strconv.ParseFloat(lag, 64); err == nil {
+		applyReplicationLag(result, opts, lagSec)
+	}
+}
+
+// scanRowMap reads the current row as strings keyed by column name.
+func scanRowMap(rows *sql.Rows) (map[string]string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	row := make(map[string]string, len(cols))
+	for i, col := range cols {
+		row[col] = values[i].String
+	}
+	return row, nil
+}
+
+// firstColumn returns the value of the first of names present in row.
+func firstColumn(row map[string]string, names ...string) string {
+	for _, name := range names {
+		if v, ok := row[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const (
+	defaultNTPPort = 123
+
+	// ntpEpochOffset is the number of seconds from the NTP epoch (1900) to
+	// the Unix epoch (1970).
+	ntpEpochOffset = 2208988800
+
+	defaultMaxStratum = 15
+)
+
+// NTPChecker queries an NTP server (RFC 5905) and reports its stratum, the
+// local clock's offset from it, and the server's root delay and dispersion.
+type NTPChecker struct{}
+
+func (c *NTPChecker) Type() model.CheckType {
+	return model.CheckNTP
+}
+
+func (c *NTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := udpOptions(monitor)
+
+	// Like current ntpd and chrony, the transmit timestamp on the wire is
+	// random; the real send time is kept locally.
+	var sentAt, receivedAt time.Time
+	var cookie [8]byte
+	request := func() []byte {
+		req := make([]byte, 48)
+		req[0] = 4<<3 | 3 // version 4, client mode
+		rand.Read(cookie[:])
+		copy(req[40:], cookie[:])
+		sentAt = time.Now()
+		return req
+	}
+	accept := func(b []byte) bool {
+		// The server echoes our transmit timestamp as the origin timestamp.
+		if len(b) < 48 || b[0]&7 != 4 || string(b[24:32]) != string(cookie[:]) {
+			return false
+		}
+		receivedAt = time.Now()
+		return true
+	}
+
+	reply, rtt, err := udpExchange(ctx, monitor, defaultNTPPort, request, accept)
+	latency := durationMS(rtt)
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     fmt.Sprintf("ntp: %v", err),
+		}, nil
+	}
+
+	leap := reply[0] >> 6
+	stratum := int(reply[1])
+	refID := reply[12:16]
+	rxTime := ntpTime(binary.BigEndian.Uint64(reply[32:40]))
+	txTime := ntpTime(binary.BigEndian.Uint64(reply[40:48]))
+
+	offset := (rxTime.Sub(sentAt) + txTime.Sub(receivedAt)) / 2
+	delay := receivedAt.Sub(sentAt) - txTime.Sub(rxTime)
+
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: latency,
+		Details: map[string]any{
+			"stratum":            stratum,
+			"leap_indicator":     leap,
+			"offset_ms":          durationMS(offset),
+			"delay_ms":           durationMS(delay),
+			"root_delay_ms":      ntpShortMS(binary.BigEndian.Uint32(reply[4:8])),
+			"root_dispersion_ms": ntpShortMS(binary.BigEndian.Uint32(reply[8:12])),
+			"reference_id":       ntpRefID(stratum, refID),
+		},
+	}
+
+	maxStratum := opts.MaxStratum
+	if maxStratum == 0 {
+		maxStratum = defaultMaxStratum
+	}
+	offsetMS := math.Abs(durationMS(offset))
+
+	switch {
+	case stratum == 0:
+		// A kiss-o'-death packet; the reference ID says why.
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("server sent kiss-o'-death %q", strings.TrimRight(string(refID), "\x00"))
+	case leap == 3:
+		result.Status = model.StatusDown
+		result.Error = "server clock is not synchronized"
+	case stratum > maxStratum:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("stratum %d exceeds %d", stratum, maxStratum)
+	case opts.MaxOffsetMS > 0 && offsetMS > opts.MaxOffsetMS:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("clock offset %.1fms exceeds %.0fms", durationMS(offset), opts.MaxOffsetMS)
+	case opts.WarnOffsetMS > 0 && offsetMS > opts.WarnOffsetMS:
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("clock offset %.1fms exceeds %.0fms", durationMS(offset), opts.WarnOffsetMS)
+	}
+	return result, nil
+}
+
+// ntpTime converts a 64-bit NTP timestamp to a time.
+func ntpTime(v uint64) time.Time {
+	secs := int64(v>>32) - ntpEpochOffset
+	nanos := int64((v & 0xffffffff) * 1e9 >> 32)
+	return time.Unix(secs, nanos)
+}
+
+// ntpShortMS converts a 32-bit NTP short format (16.16 fixed-point seconds)
+// to milliseconds.
+func ntpShortMS(v uint32) float64 {
+	return math.Round(float64(v)/65536*1e6) / 1000
+}
+
+// ntpRefID renders a reference ID: a source name such as "GPS" for
+// stratum 0 and 1, otherwise the upstream server's IPv4 address (or a hash
+// of its IPv6 address).
+func ntpRefID(stratum int, id []byte) string {
+	if stratum <= 1 {
+		return strings.TrimRight(string(id), "\x00")
+	}
+	return net.IP(id).String()
+}
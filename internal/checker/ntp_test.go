@@ -0,0 +1,146 @@
+package checker
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// toNTP converts a time to a 64-bit NTP timestamp.
+func toNTP(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return secs<<32 | frac
+}
+
+// ntpServer answers client requests with a server reply whose clock runs
+// skew ahead of the local one.
+func ntpServer(t *testing.T, leap, stratum byte, refID string, skew time.Duration) int {
+	return serveUDP(t, func(req []byte) []byte {
+		if len(req) < 48 || req[0]&7 != 3 {
+			return nil
+		}
+		now := time.Now().Add(skew)
+		b := make([]byte, 48)
+		b[0] = leap<<6 | 4<<3 | 4 // server mode
+		b[1] = stratum
+		binary.BigEndian.PutUint32(b[4:8], 0x00008000)  // root delay 0.5s
+		binary.BigEndian.PutUint32(b[8:12], 0x00000800) // root dispersion 31.25ms
+		copy(b[12:16], refID)
+		copy(b[24:32], req[40:48])
+		binary.BigEndian.PutUint64(b[32:40], toNTP(now))
+		binary.BigEndian.PutUint64(b[40:48], toNTP(now))
+		return b
+	})
+}
+
+func TestNTPCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		leap      byte
+		stratum   byte
+		refID     string
+		skew      time.Duration
+		opts      *model.UDPOptions
+		want      model.CheckStatus
+		wantError string
+	}{
+		{"synchronized", 0, 1, "GPS", 0, nil, model.StatusUp, ""},
+		{"offset within limits", 0, 2, "\x0a\x00\x00\x01", 0, &model.UDPOptions{WarnOffsetMS: 100, MaxOffsetMS: 1000}, model.StatusUp, ""},
+		{"offset above warning", 0, 2, "\x0a\x00\x00\x01", 500 * time.Millisecond,
+			&model.UDPOptions{WarnOffsetMS: 100, MaxOffsetMS: 1000}, model.StatusDegraded, "exceeds 100ms"},
+		{"offset above maximum", 0, 2, "\x0a\x00\x00\x01", -2 * time.Second,
+			&model.UDPOptions{WarnOffsetMS: 100, MaxOffsetMS: 1000}, model.StatusDown, "exceeds 1000ms"},
+		{"stratum too high", 0, 4, "\x0a\x00\x00\x01", 0, &model.UDPOptions{MaxStratum: 3}, model.StatusDown, "stratum 4 exceeds 3"},
+		{"unsynchronized", 3, 16, "INIT", 0, nil, model.StatusDown, "not synchronized"},
+		{"kiss-o'-death", 0, 0, "RATE", 0, nil, model.StatusDown, `kiss-o'-death "RATE"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := ntpServer(t, tt.leap, tt.stratum, tt.refID, tt.skew)
+			result := checkUDP(t, &NTPChecker{}, port, tt.opts)
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+		})
+	}
+
+	port := ntpServer(t, 0, 2, "\x0a\x00\x00\x01", 250*time.Millisecond)
+	result := checkUDP(t, &NTPChecker{}, port, nil)
+	if offset := result.Details["offset_ms"].(float64); offset < 200 || offset > 300 {
+		t.Errorf("offset_ms = %.1f, want about 250", offset)
+	}
+	want := map[string]any{"stratum": 2, "reference_id": "10.0.0.1", "root_delay_ms": 500.0, "root_dispersion_ms": 31.25}
+	for key, v := range want {
+		if result.Details[key] != v {
+			t.Errorf("details[%s] = %v, want %v", key, result.Details[key], v)
+		}
+	}
+}
+
+func TestNTPCheckIgnoresForeignReplies(t *testing.T) {
+	// A reply that does not echo our transmit timestamp is not an answer.
+	port := serveUDP(t, func(req []byte) []byte {
+		b := make([]byte, 48)
+		b[0] = 4<<3 | 4
+		b[1] = 1
+		return b
+	})
+	result := checkUDP(t, &NTPChecker{}, port, nil)
+	if result.Status != model.StatusDown || !strings.Contains(result.Error, "no reply") {
+		t.Errorf("result = %s (%s), want down (no reply)", result.Status, result.Error)
+	}
+}
+
+func TestNTPTime(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want time.Time
+	}{
+		{uint64(ntpEpochOffset) << 32, time.Unix(0, 0)},
+		{uint64(ntpEpochOffset+1)<<32 | 1<<31, time.Unix(1, 5e8)},
+		{toNTP(time.Date(2026, 10, 18, 12, 0, 0, 250e6, time.UTC)), time.Date(2026, 10, 18, 12, 0, 0, 250e6, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := ntpTime(tt.v); !got.Equal(tt.want) {
+			t.Errorf("ntpTime(%#x) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestNTPShortMS(t *testing.T) {
+	tests := []struct {
+		v    uint32
+		want float64
+	}{
+		{0, 0},
+		{1 << 16, 1000},
+		{1 << 15, 500},
+		{0x00000800, 31.25},
+	}
+	for _, tt := range tests {
+		if got := ntpShortMS(tt.v); got != tt.want {
+			t.Errorf("ntpShortMS(%#x) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestNTPRefID(t *testing.T) {
+	tests := []struct {
+		stratum int
+		id      []byte
+		want    string
+	}{
+		{1, []byte("GPS\x00"), "GPS"},
+		{0, []byte("RATE"), "RATE"},
+		{2, []byte{192, 0, 2, 10}, "192.0.2.10"},
+	}
+	for _, tt := range tests {
+		if got := ntpRefID(tt.stratum, tt.id); got != tt.want {
+			t.Errorf("ntpRefID(%d, %v) = %q, want %q", tt.stratum, tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// POP3Checker logs in to a POP3 server and reads the mailbox size.
+type POP3Checker struct{}
+
+func (c *POP3Checker) Type() model.CheckType {
+	return model.CheckPOP3
+}
+
+func (c *POP3Checker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := mailOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	start := time.Now()
+	result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+	if err := pop3Session(ctx, monitor, opts, password, timeout, result); err != nil {
+		result.Status = model.StatusDown
+		result.Error = err.Error()
+	}
+	result.LatencyMS = sinceMS(start)
+	return result, nil
+}
+
+func pop3Session(ctx context.Context, monitor *model.Monitor, opts *model.MailOptions, password string,
+	timeout time.Duration, result *Result) error {
+	host := strings.Trim(monitor.Target, "[]")
+	port, mode := mailEndpoint(model.CheckPOP3, monitor.Port, opts.TLS)
+	cfg := mailTLSConfig(opts, host)
+
+	stage := time.Now()
+	conn, err := dialMail(ctx, net.JoinHostPort(host, strconv.Itoa(port)), mode, cfg, timeout)
+	if err != nil {
+		return fmt.Errorf("pop3 connect failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	pc := &pop3Conn{conn: conn, r: bufio.NewReader(conn)}
+	if _, err := pc.readStatus(); err != nil {
+		return fmt.Errorf("pop3 greeting: %v", err)
+	}
+	result.Details["connect_ms"] = sinceMS(stage)
+
+	if mode != model.MailTLSImplicit && mode != model.MailTLSNone {
+		stage = time.Now()
+		offered := false
+		if _, err := pc.cmd("CAPA"); err == nil {
+			capabilities, err := pc.readMultiline()
+			if err != nil {
+				return fmt.Errorf("CAPA: %v", err)
+			}
+			offered = strings.Contains(strings.ToUpper(strings.Join(capabilities, " ")), "STLS")
+		}
+		if !offered && mode == model.MailTLSStartTLS {
+			return fmt.Errorf("pop3 server does not advertise STLS")
+		}
+		if offered {
+			if _, err := pc.cmd("STLS"); err != nil {
+				return fmt.Errorf("STLS: %v", err)
+			}
+			tlsConn := tls.Client(conn, cfg)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				return fmt.Errorf("tls handshake failed: %v", err)
+			}
+			pc.conn, pc.r = tlsConn, bufio.NewReader(tlsConn)
+			result.Details["tls_ms"] = sinceMS(stage)
+		}
+	}
+	recordTLS(result, pc.conn)
+
+	if opts.Username == "" {
+		pc.cmd("QUIT")
+		return nil
+	}
+
+	if err := mailAuthAllowed(pc.conn, mode, "pop3", opts.Username, password); err != nil {
+		return err
+	}
+
+	stage = time.Now()
+	if _, err := pc.cmd("USER " + opts.Username); err != nil {
+		return fmt.Errorf("pop3 login failed: %v", err)
+	}
+	if _, err := pc.cmd("PASS " + password); err != nil {
+		return fmt.Errorf("pop3 login failed: %v", err)
+	}
+	result.Details["auth_ms"] = sinceMS(stage)
+
+	// STAT replies "+OK <messages> <octets>".
+	stat, err := pc.cmd("STAT")
+	if err != nil {
+		return fmt.Errorf("STAT: %v", err)
+	}
+	if fields := strings.Fields(stat); len(fields) >= 1 {
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			result.Details["messages"] = n
+		}
+	}
+	pc.cmd("QUIT")
+	return nil
+}
+
+// pop3Conn is a minimal POP3 client.
+type pop3Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// cmd sends a command and returns the text after "+OK". An -ERR reply is
+// returned as an error.
+func (pc *pop3Conn) cmd(command string) (string, error) {
+	if _, err := io.WriteString(pc.conn, command+"\r\n"); err != nil {
+		return "", err
+	}
+	return pc.readStatus()
+}
+
+func (pc *pop3Conn) readStatus() (string, error) {
+	line, err := pc.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if rest, ok := strings.CutPrefix(line, "+OK"); ok {
+		return strings.TrimSpace(rest), nil
+	}
+	return "", fmt.Errorf("%s", truncateActual(line))
+}
+
+// readMultiline reads a dot-terminated response body.
+func (pc *pop3Conn) readMultiline() ([]string, error) {
+	var lines []string
+	for {
+		line, err := pc.r.ReadString('\n')
+		if err != nil {
+			return lines, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			return lines, nil
+		}
+		lines = append(lines, strings.TrimPrefix(line, "."))
+	}
+}
@@ -0,0 +1,168 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const defaultPostgresPort = 5432
+
+// PostgresChecker connects to PostgreSQL, authenticates and runs a query.
+type PostgresChecker struct{}
+
+func (c *PostgresChecker) Type() model.CheckType {
+	return model.CheckPostgres
+}
+
+func (c *PostgresChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := databaseOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	cfg, err := pgx.ParseConfig(postgresDSN(monitor, opts, password))
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: fmt.Sprintf("invalid connection settings: %v", err)}, nil
+	}
+	cfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	cfg.DialFunc = familyDialer(ctx, &net.Dialer{Timeout: timeout})
+	cfg.LookupFunc = func(ctx context.Context, host string) ([]string, error) {
+		ips, err := lookupIP(ctx, host)
+		addrs := make([]string, len(ips))
+		for i, ip := range ips {
+			addrs[i] = ip.String()
+		}
+		return addrs, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := pgx.ConnectConfig(ctx, cfg)
+	connectMS := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: connectMS,
+			// pgx lists one line per attempted address and TLS mode.
+			Error: "postgres connect failed: " + strings.Join(strings.Fields(err.Error()), " "),
+		}, nil
+	}
+	defer conn.Close(context.Background())
+
+	result := &Result{
+		Status: model.StatusUp,
+		Details: map[string]any{
+			"connect_ms":     connectMS,
+			"server_version": conn.PgConn().ParameterStatus("server_version"),
+		},
+	}
+
+	query := opts.Query
+	if query == "" {
+		query = "SELECT 1"
+	}
+	queryStart := time.Now()
+	err = postgresQuery(ctx, conn, query, result)
+	result.Details["query_ms"] = float64(time.Since(queryStart).Microseconds()) / 1000.0
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("query failed: %v", err)
+	} else if opts.Replication {
+		postgresReplication(ctx, conn, opts, result)
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	return result, nil
+}
+
+// postgresDSN builds a connection URL so credentials are escaped properly.
+func postgresDSN(monitor *model.Monitor, opts *model.DatabaseOptions, password string) string {
+	port := monitor.Port
+	if port == 0 {
+		port = defaultPostgresPort
+	}
+	u := &url.URL{
+		Scheme: "postgres",
+		Host:   net.JoinHostPort(strings.Trim(monitor.Target, "[]"), strconv.Itoa(port)),
+		Path:   "/" + opts.Database,
+	}
+	if opts.Username != "" {
+		u.User = url.UserPassword(opts.Username, password)
+	}
+
+	sslmode := "prefer"
+	switch opts.TLS {
+	case model.DBTLSDisable:
+		sslmode = "disable"
+	case model.DBTLSRequire:
+		sslmode = "require"
+	case model.DBTLSVerify:
+		sslmode = "verify-full"
+	}
+	q := url.Values{}
+	q.Set("sslmode", sslmode)
+	q.Set("application_name", "pingmesh")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// postgresQuery runs the check query and records the first value returned.
+func postgresQuery(ctx context.Context, conn *pgx.Conn, query string, result *Result) error {
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		if count == 0 {
+			if values, err := rows.Values(); err == nil && len(values) > 0 {
+				result.Details["query_result"] = truncateActual(fmt.Sprint(values[0]))
+			}
+		}
+		count++
+	}
+	result.Details["rows"] = count
+	return rows.Err()
+}
+
+// postgresReplication records whether the server is a primary or a replica
+// and, on replicas, how far replay is behind.
+func postgresReplication(ctx context.Context, conn *pgx.Conn, opts *model.DatabaseOptions, result *Result) {
+	var inRecovery bool
+	var lagSec float64
+	err := conn.QueryRow(ctx,
+		`SELECT pg_is_in_recovery(),
+		        COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8`,
+	).Scan(&inRecovery, &lagSec)
+	if err != nil {
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("reading replication status: %v", err)
+		return
+	}
+
+	if !inRecovery {
+		result.Details["role"] = "primary"
+		var replicas int
+		if err := conn.QueryRow(ctx, "SELECT count(*) FROM pg_stat_replication").Scan(&replicas); err == nil {
+			result.Details["replicas"] = replicas
+		}
+		return
+	}
+	result.Details["role"] = "replica"
+	applyReplicationLag(result, opts, lagSec)
+}
@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const defaultRADIUSPort = 1812
+
+// RADIUS packet codes (RFC 2865, RFC 2866, RFC 5997).
+const (
+	radiusAccessAccept       = 2
+	radiusAccessReject       = 3
+	radiusAccountingResponse = 5
+	radiusStatusServer       = 12
+
+	radiusMessageAuthenticator = 80
+)
+
+var radiusCodes = map[byte]string{
+	1:  "Access-Request",
+	2:  "Access-Accept",
+	3:  "Access-Reject",
+	4:  "Accounting-Request",
+	5:  "Accounting-Response",
+	11: "Access-Challenge",
+	12: "Status-Server",
+}
+
+// RADIUSChecker sends a Status-Server request (RFC 5997) signed with the
+// shared secret and expects an Access-Accept or Accounting-Response whose
+// authenticator proves the server knows the same secret.
+type RADIUSChecker struct{}
+
+func (c *RADIUSChecker) Type() model.CheckType {
+	return model.CheckRADIUS
+}
+
+func (c *RADIUSChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := udpOptions(monitor)
+	secret, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+	if secret == "" {
+		return &Result{Status: model.StatusDown, Error: "radius: no shared secret configured"}, nil
+	}
+
+	var req []byte
+	request := func() []byte {
+		req = radiusStatusServerPacket(secret)
+		return req
+	}
+	accept := func(b []byte) bool {
+		if len(b) < 20 || b[1] != req[1] {
+			return false
+		}
+		n := int(binary.BigEndian.Uint16(b[2:4]))
+		return n >= 20 && n <= len(b)
+	}
+
+	reply, rtt, err := udpExchange(ctx, monitor, defaultRADIUSPort, request, accept)
+	latency := durationMS(rtt)
+	if err != nil {
+		msg := fmt.Sprintf("radius: %v", err)
+		if errors.Is(err, errNoReply) {
+			// Servers drop requests from unknown clients and requests whose
+			// Message-Authenticator does not verify.
+			msg += " (unknown client or wrong shared secret?)"
+		}
+		return &Result{Status: model.StatusDown, LatencyMS: latency, Error: msg}, nil
+	}
+	reply = reply[:binary.BigEndian.Uint16(reply[2:4])]
+
+	code := reply[0]
+	name, ok := radiusCodes[code]
+	if !ok {
+		name = fmt.Sprintf("code %d", code)
+	}
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: latency,
+		Details:   map[string]any{"response_code": name},
+	}
+	switch {
+	case !radiusResponseValid(reply, req[4:20], secret):
+		result.Status = model.StatusDown
+		result.Error = "response authenticator mismatch (wrong shared secret?)"
+	case code == radiusAccessReject:
+		result.Status = model.StatusDown
+		result.Error = "server sent Access-Reject"
+	case code != radiusAccessAccept && code != radiusAccountingResponse:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("unexpected %s", name)
+	}
+	return result, nil
+}
+
+// radiusStatusServerPacket builds a Status-Server request with a random
+// identifier and request authenticator and a Message-Authenticator, which
+// RFC 5997 requires.
+func radiusStatusServerPacket(secret string) []byte {
+	const length = 20 + 18
+	p := make([]byte, length)
+	p[0] = radiusStatusServer
+	rand.Read(p[1:2])
+	binary.BigEndian.PutUint16(p[2:4], length)
+	rand.Read(p[4:20])
+	p[20] = radiusMessageAuthenticator
+	p[21] = 18
+
+	// The HMAC is computed over the packet with the attribute zeroed.
+	mac := hmac.New(md5.New, []byte(secret))
+	mac.Write(p)
+	copy(p[22:], mac.Sum(nil))
+	return p
+}
+
+// radiusResponseValid checks a response authenticator:
+// MD5(code | identifier | length | request authenticator | attributes | secret).
+func radiusResponseValid(reply, requestAuth []byte, secret string) bool {
+	h := md5.New()
+	h.Write(reply[:4])
+	h.Write(requestAuth)
+	h.Write(reply[20:])
+	h.Write([]byte(secret))
+	return hmac.Equal(h.Sum(nil), reply[4:20])
+}
@@ -0,0 +1,152 @@
+package checker
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// radiusReply builds a response to req signed with secret.
+func radiusReply(req []byte, code byte, secret string) []byte {
+	p := make([]byte, 20)
+	p[0] = code
+	p[1] = req[1]
+	binary.BigEndian.PutUint16(p[2:4], 20)
+	h := md5.New()
+	h.Write(p[:4])
+	h.Write(req[4:20])
+	h.Write([]byte(secret))
+	copy(p[4:20], h.Sum(nil))
+	return p
+}
+
+// radiusServer answers Status-Server requests whose Message-Authenticator
+// verifies with "s3cret" using reply, and drops the rest.
+func radiusServer(t *testing.T, reply func(req []byte) []byte) int {
+	return serveUDP(t, func(req []byte) []byte {
+		if len(req) != 38 || req[0] != radiusStatusServer {
+			return nil
+		}
+		got := append([]byte(nil), req[22:38]...)
+		signed := append([]byte(nil), req...)
+		clear(signed[22:38])
+		mac := hmac.New(md5.New, []byte("s3cret"))
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), got) {
+			return nil
+		}
+		return reply(req)
+	})
+}
+
+func TestRADIUSCheck(t *testing.T) {
+	secret := model.Credentials{Password: "s3cret"}
+	tests := []struct {
+		name      string
+		reply     func(req []byte) []byte
+		opts      *model.UDPOptions
+		want      model.CheckStatus
+		wantCode  string
+		wantError string
+	}{
+		{"access accept", func(req []byte) []byte { return radiusReply(req, radiusAccessAccept, "s3cret") },
+			&model.UDPOptions{Credentials: secret}, model.StatusUp, "Access-Accept", ""},
+		{"accounting response", func(req []byte) []byte { return radiusReply(req, radiusAccountingResponse, "s3cret") },
+			&model.UDPOptions{Credentials: secret}, model.StatusUp, "Accounting-Response", ""},
+		{"access reject", func(req []byte) []byte { return radiusReply(req, radiusAccessReject, "s3cret") },
+			&model.UDPOptions{Credentials: secret}, model.StatusDown, "Access-Reject", "Access-Reject"},
+		{"unexpected code", func(req []byte) []byte { return radiusReply(req, 11, "s3cret") },
+			&model.UDPOptions{Credentials: secret}, model.StatusDown, "Access-Challenge", "unexpected Access-Challenge"},
+		{"forged authenticator", func(req []byte) []byte { return radiusReply(req, radiusAccessAccept, "other") },
+			&model.UDPOptions{Credentials: secret}, model.StatusDown, "Access-Accept", "authenticator mismatch"},
+		{"wrong secret", func(req []byte) []byte { return radiusReply(req, radiusAccessAccept, "s3cret") },
+			&model.UDPOptions{Credentials: model.Credentials{Password: "guess"}}, model.StatusDown, "", "wrong shared secret?"},
+		{"no secret", nil, nil, model.StatusDown, "", "no shared secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := radiusServer(t, tt.reply)
+			result := checkUDP(t, &RADIUSChecker{}, port, tt.opts)
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+			if got, _ := result.Details["response_code"].(string); got != tt.wantCode {
+				t.Errorf("response_code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRADIUSCheckSkipsOtherIdentifiers(t *testing.T) {
+	// A reply to an earlier request, with another identifier, is skipped.
+	port := radiusServer(t, func(req []byte) []byte {
+		stale := append([]byte(nil), req...)
+		stale[1]++
+		return radiusReply(stale, radiusAccessAccept, "s3cret")
+	})
+	result := checkUDP(t, &RADIUSChecker{}, port, &model.UDPOptions{Credentials: model.Credentials{Password: "s3cret"}})
+	if result.Status != model.StatusDown || !strings.Contains(result.Error, "no reply") {
+		t.Errorf("result = %s (%s), want down (no reply)", result.Status, result.Error)
+	}
+}
+
+func TestRADIUSStatusServerPacket(t *testing.T) {
+	a := radiusStatusServerPacket("s3cret")
+	b := radiusStatusServerPacket("s3cret")
+	if len(a) != 38 || a[0] != radiusStatusServer || binary.BigEndian.Uint16(a[2:4]) != 38 {
+		t.Fatalf("packet = % x, want a 38-byte Status-Server", a)
+	}
+	if a[20] != radiusMessageAuthenticator || a[21] != 18 {
+		t.Errorf("attribute = %d/%d, want Message-Authenticator of length 18", a[20], a[21])
+	}
+	if string(a[4:20]) == string(b[4:20]) {
+		t.Error("two packets share a request authenticator")
+	}
+
+	signed := append([]byte(nil), a...)
+	clear(signed[22:])
+	mac := hmac.New(md5.New, []byte("s3cret"))
+	mac.Write(signed)
+	if !hmac.Equal(mac.Sum(nil), a[22:]) {
+		t.Error("Message-Authenticator does not verify")
+	}
+}
+
+func TestRADIUSResponseValid(t *testing.T) {
+	req := radiusStatusServerPacket("s3cret")
+	reply := radiusReply(req, radiusAccessAccept, "s3cret")
+	withAttrs := append(append([]byte(nil), reply[:20]...), 18, 6, 'h', 'e', 'l', 'o')
+	binary.BigEndian.PutUint16(withAttrs[2:4], 26)
+	h := md5.New()
+	h.Write(withAttrs[:4])
+	h.Write(req[4:20])
+	h.Write(withAttrs[20:])
+	h.Write([]byte("s3cret"))
+	copy(withAttrs[4:20], h.Sum(nil))
+
+	tampered := append([]byte(nil), withAttrs...)
+	tampered[len(tampered)-1] = 'p'
+
+	tests := []struct {
+		name   string
+		reply  []byte
+		auth   []byte
+		secret string
+		want   bool
+	}{
+		{"valid", reply, req[4:20], "s3cret", true},
+		{"valid with attributes", withAttrs, req[4:20], "s3cret", true},
+		{"wrong secret", reply, req[4:20], "guess", false},
+		{"other request", reply, radiusStatusServerPacket("s3cret")[4:20], "s3cret", false},
+		{"tampered attributes", tampered, req[4:20], "s3cret", false},
+	}
+	for _, tt := range tests {
+		if got := radiusResponseValid(tt.reply, tt.auth, tt.secret); got != tt.want {
+			t.Errorf("%s: radiusResponseValid() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const (
+	defaultRedisPort = 6379
+	// maxRedisBulk caps a single bulk reply; INFO output is a few KB.
+	maxRedisBulk = 1 << 20
+)
+
+// RedisChecker speaks RESP to Redis: it authenticates, selects a database
+// and runs a command, PING by default.
+type RedisChecker struct{}
+
+func (c *RedisChecker) Type() model.CheckType {
+	return model.CheckRedis
+}
+
+func (c *RedisChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := databaseOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	host := strings.Trim(monitor.Target, "[]")
+	port := monitor.Port
+	if port == 0 {
+		port = defaultRedisPort
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	conn, err := dialContext(ctx, &net.Dialer{Timeout: timeout}, "tcp", address)
+	if cfg := databaseTLSConfig(opts.TLS, host); cfg != nil && err == nil {
+		tlsConn := tls.Client(conn, cfg)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+		}
+		conn = tlsConn
+	}
+	connectMS := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: connectMS,
+			Error:     fmt.Sprintf("redis connect failed: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	result := &Result{
+		Status:  model.StatusUp,
+		Details: map[string]any{"connect_ms": connectMS},
+	}
+
+	if err := rc.setup(opts, password); err != nil {
+		result.Status = model.StatusDown
+		result.Error = err.Error()
+		result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+		return result, nil
+	}
+
+	args := strings.Fields(opts.Query)
+	if len(args) == 0 {
+		args = []string{"PING"}
+	}
+	queryStart := time.Now()
+	reply, err := rc.do(args...)
+	result.Details["query_ms"] = float64(time.Since(queryStart).Microseconds()) / 1000.0
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("%s failed: %v", strings.ToUpper(args[0]), err)
+	} else {
+		result.Details["query_result"] = truncateActual(fmt.Sprint(reply))
+		if opts.Replication {
+			redisReplication(rc, opts, result)
+		}
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	return result, nil
+}
+
+// redisConn is a minimal RESP2 client for a single check.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// setup authenticates and selects the configured database.
+func (rc *redisConn) setup(opts *model.DatabaseOptions, password string) error {
+	if password != "" {
+		args := []string{"AUTH", password}
+		if opts.Username != "" {
+			args = []string{"AUTH", opts.Username, password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			return fmt.Errorf("AUTH failed: %v", err)
+		}
+	}
+	if opts.Database != "" {
+		if _, err := rc.do("SELECT", opts.Database); err != nil {
+			return fmt.Errorf("SELECT %s failed: %v", opts.Database, err)
+		}
+	}
+	return nil
+}
+
+// do sends a command and reads its reply.
+func (rc *redisConn) do(args ...string) (any, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return rc.readReply()
+}
+
+// readReply parses one RESP2 reply. Error replies are returned as a
+// redisError.
+func (rc *redisConn) readReply() (any, error) {
+	line, err := rc.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxRedisBulk {
+			return nil, fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, 0, min(n, 1024))
+		for i := 0; i < n; i++ {
+			item, err := rc.readReply()
+			var re redisError
+			if err != nil && !errors.As(err, &re) {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", truncateActual(line))
+}
+
+// redisReplication reads INFO replication to record the server's role and,
+// on replicas, the state of the link to the primary.
+func redisReplication(rc *redisConn, opts *model.DatabaseOptions, result *Result) {
+	reply, err := rc.do("INFO", "replication")
+	if err != nil {
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("reading replication status: %v", err)
+		return
+	}
+	text, _ := reply.(string)
+	info := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			info[key] = value
+		}
+	}
+
+	if info["role"] != "slave" {
+		result.Details["role"] = "primary"
+		if n, err := strconv.Atoi(info["connected_slaves"]); err == nil {
+			result.Details["replicas"] = n
+		}
+		return
+	}
+
+	result.Details["role"] = "replica"
+	if info["master_link_status"] != "up" {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("replication link to %s is %s", info["master_host"], info["master_link_status"])
+		return
+	}
+	// Redis reports seconds since the last interaction with the primary,
+	// the closest it has to a lag figure.
+	if lag, err := strconv.ParseFloat(info["master_last_io_seconds_ago"], 64); err == nil {
+		applyReplicationLag(result, opts, lag)
+	}
+}
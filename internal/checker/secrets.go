@@ -0,0 +1,47 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// allowedSecrets holds the environment variables and file patterns that
+// monitors may read secrets from on this node. Like the registry, it is set
+// once at startup, before any check runs.
+var allowedSecrets struct {
+	env   []string
+	files []string
+}
+
+// AllowSecrets sets the environment variables and the file paths or glob
+// patterns that monitors may read secrets from on this node. Nothing is
+// readable until it is called.
+func AllowSecrets(env, files []string) {
+	allowedSecrets.env = env
+	allowedSecrets.files = files
+}
+
+// secretEnvAllowed returns an error unless the node's config allows
+// monitors to read the environment variable name.
+func secretEnvAllowed(name string) error {
+	if !slices.Contains(allowedSecrets.env, name) {
+		return fmt.Errorf("environment variable %s is not allowed by this node's secrets.env config", name)
+	}
+	return nil
+}
+
+// secretFileAllowed returns the cleaned path if the node's config allows
+// monitors to read it.
+func secretFileAllowed(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("secret file %q must be an absolute path", path)
+	}
+	clean := filepath.Clean(path)
+	for _, pattern := range allowedSecrets.files {
+		if ok, _ := filepath.Match(pattern, clean); ok {
+			return clean, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not allowed by this node's secrets.files config", clean)
+}
@@ -0,0 +1,64 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestResolvePassword(t *testing.T) {
+	dir := t.TempDir()
+	allowedFile := filepath.Join(dir, "allowed", "pg.secret")
+	otherFile := filepath.Join(dir, "other.secret")
+	os.Mkdir(filepath.Dir(allowedFile), 0o700)
+	os.WriteFile(allowedFile, []byte("file-pass\n"), 0o600)
+	os.WriteFile(otherFile, []byte("other-pass\n"), 0o600)
+	t.Setenv("PINGMESH_TEST_PASSWORD", "env-pass")
+	t.Setenv("PINGMESH_TEST_OTHER", "other")
+
+	AllowSecrets([]string{"PINGMESH_TEST_PASSWORD", "PINGMESH_TEST_UNSET"}, []string{filepath.Join(dir, "allowed", "*")})
+	t.Cleanup(func() { AllowSecrets(nil, nil) })
+
+	tests := []struct {
+		name    string
+		creds   model.Credentials
+		want    string
+		wantErr string
+	}{
+		{"inline", model.Credentials{Password: "inline"}, "inline", ""},
+		{"allowed env", model.Credentials{PasswordEnv: "PINGMESH_TEST_PASSWORD"}, "env-pass", ""},
+		{"env not allowed", model.Credentials{PasswordEnv: "PINGMESH_TEST_OTHER"}, "", "not allowed by this node's secrets.env"},
+		{"allowed env unset", model.Credentials{PasswordEnv: "PINGMESH_TEST_UNSET"}, "", "is not set"},
+		{"allowed file", model.Credentials{PasswordFile: allowedFile}, "file-pass", ""},
+		{"file not allowed", model.Credentials{PasswordFile: otherFile}, "", "not allowed by this node's secrets.files"},
+		{"traversal out of an allowed directory", model.Credentials{PasswordFile: filepath.Join(dir, "allowed", "..", "other.secret")}, "", "not allowed"},
+		{"relative file", model.Credentials{PasswordFile: "pg.secret"}, "", "must be an absolute path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePassword(tt.creds)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolvePassword() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePassword() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolvePassword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePasswordNothingAllowed(t *testing.T) {
+	t.Setenv("PINGMESH_TEST_PASSWORD", "env-pass")
+	if _, err := ResolvePassword(model.Credentials{PasswordEnv: "PINGMESH_TEST_PASSWORD"}); err == nil {
+		t.Error("ResolvePassword() read an environment variable without a secrets config")
+	}
+}
@@ -0,0 +1,270 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// probeHeader tags round-trip messages so they can be found over IMAP.
+const probeHeader = "X-PingMesh-Probe"
+
+// SMTPChecker runs the SMTP EHLO/STARTTLS/AUTH dialogue and, optionally, a
+// send-and-receive round trip.
+type SMTPChecker struct{}
+
+func (c *SMTPChecker) Type() model.CheckType {
+	return model.CheckSMTP
+}
+
+func (c *SMTPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+	opts := mailOptions(monitor)
+
+	password, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	start := time.Now()
+	result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+	if err := smtpDialogue(ctx, monitor, opts, password, timeout, result); err != nil {
+		result.Status = model.StatusDown
+		result.Error = err.Error()
+	}
+	result.LatencyMS = sinceMS(start)
+	return result, nil
+}
+
+// smtpDialogue connects, negotiates TLS, authenticates and, for round-trip
+// checks, sends the probe and waits for it to arrive.
+func smtpDialogue(ctx context.Context, monitor *model.Monitor, opts *model.MailOptions, password string,
+	timeout time.Duration, result *Result) error {
+	host := strings.Trim(monitor.Target, "[]")
+	port, mode := mailEndpoint(model.CheckSMTP, monitor.Port, opts.TLS)
+	cfg := mailTLSConfig(opts, host)
+
+	stage := time.Now()
+	conn, err := dialMail(ctx, net.JoinHostPort(host, strconv.Itoa(port)), mode, cfg, timeout)
+	if err != nil {
+		return fmt.Errorf("smtp connect failed: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("smtp greeting: %v", err)
+	}
+	defer client.Close()
+
+	helo := opts.HeloName
+	if helo == "" {
+		helo = "localhost"
+	}
+	if err := client.Hello(helo); err != nil {
+		return fmt.Errorf("EHLO: %v", err)
+	}
+	result.Details["connect_ms"] = sinceMS(stage)
+
+	if mode != model.MailTLSImplicit && mode != model.MailTLSNone {
+		offered, _ := client.Extension("STARTTLS")
+		if !offered && mode == model.MailTLSStartTLS {
+			return fmt.Errorf("smtp server does not advertise STARTTLS")
+		}
+		if offered {
+			stage = time.Now()
+			if err := client.StartTLS(cfg); err != nil {
+				return fmt.Errorf("STARTTLS: %v", err)
+			}
+			result.Details["tls_ms"] = sinceMS(stage)
+		}
+	}
+	if state, ok := client.TLSConnectionState(); ok {
+		result.Details["tls_version"] = tls.VersionName(state.Version)
+	}
+
+	_, mechanisms := client.Extension("AUTH")
+	if mechanisms != "" {
+		result.Details["auth_mechanisms"] = strings.Fields(mechanisms)
+	}
+
+	if opts.Username != "" {
+		stage = time.Now()
+		auth, err := smtpAuth(mechanisms, opts.Username, password, host)
+		if err != nil {
+			return err
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth failed: %v", err)
+		}
+		result.Details["auth_ms"] = sinceMS(stage)
+	}
+
+	if opts.RoundTrip == nil {
+		client.Quit()
+		return nil
+	}
+	return mailRoundTrip(ctx, client, opts.RoundTrip, timeout, result)
+}
+
+// smtpAuth picks PLAIN, or LOGIN for servers that only offer that.
+func smtpAuth(mechanisms, username, password, host string) (smtp.Auth, error) {
+	offered := strings.Fields(strings.ToUpper(mechanisms))
+	switch {
+	case slices.Contains(offered, "PLAIN"):
+		return smtp.PlainAuth("", username, password, host), nil
+	case slices.Contains(offered, "LOGIN"):
+		return &loginAuth{username: username, password: password}, nil
+	case len(offered) == 0:
+		return nil, fmt.Errorf("smtp server does not offer AUTH (it may require STARTTLS first)")
+	}
+	return nil, fmt.Errorf("smtp server offers no supported AUTH mechanism (%s)", mechanisms)
+}
+
+// loginAuth implements the non-standard but widespread AUTH LOGIN.
+type loginAuth struct {
+	username, password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, fmt.Errorf("unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username", "user name":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
+
+// mailRoundTrip sends a tagged message over the authenticated SMTP session
+// and polls the IMAP mailbox until it arrives or the check times out.
+func mailRoundTrip(ctx context.Context, client *smtp.Client, rt *model.MailRoundTrip, timeout time.Duration, result *Result) error {
+	token := make([]byte, 12)
+	rand.Read(token)
+	probe := hex.EncodeToString(token)
+	result.Details["probe_id"] = probe
+
+	stage := time.Now()
+	if err := client.Mail(rt.From); err != nil {
+		return fmt.Errorf("MAIL FROM: %v", err)
+	}
+	if err := client.Rcpt(rt.To); err != nil {
+		return fmt.Errorf("RCPT TO: %v", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %v", err)
+	}
+	msg := fmt.Sprintf("From: <%s>\r\nTo: <%s>\r\nSubject: PingMesh round-trip %s\r\nDate: %s\r\n"+
+		"Message-ID: <%s@pingmesh>\r\n%s: %s\r\n\r\nPingMesh mail round-trip probe. Safe to delete.\r\n",
+		rt.From, rt.To, probe, time.Now().Format(time.RFC1123Z), probe, probeHeader, probe)
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("DATA: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %v", err)
+	}
+	client.Quit()
+	sent := time.Now()
+	result.Details["send_ms"] = sinceMS(stage)
+
+	password, err := ResolvePassword(rt.IMAP)
+	if err != nil {
+		return fmt.Errorf("imap: %v", err)
+	}
+	port, mode := mailEndpoint(model.CheckIMAP, rt.IMAPPort, rt.IMAPTLS)
+	if rt.IMAPTLS == "" && rt.IMAPPort == 0 {
+		port, mode = 993, model.MailTLSImplicit
+	}
+	mailbox := rt.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	interval := time.Duration(rt.PollIntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	// The IMAP stage reports its own timings; keep the SMTP ones.
+	imapResult := &Result{Details: map[string]any{}}
+	address := net.JoinHostPort(strings.Trim(rt.IMAPHost, "[]"), strconv.Itoa(port))
+	cfg := mailTLSConfig(&model.MailOptions{}, strings.Trim(rt.IMAPHost, "[]"))
+	err = imapSession(ctx, address, mode, cfg, rt.IMAP.Username, password, timeout, imapResult, func(ic *imapConn) error {
+		if _, err := ic.cmd("SELECT " + imapQuote(mailbox)); err != nil {
+			return fmt.Errorf("SELECT %s: %v", mailbox, err)
+		}
+		for {
+			lines, err := ic.cmd("UID SEARCH HEADER " + probeHeader + " " + imapQuote(probe))
+			if err != nil {
+				return fmt.Errorf("SEARCH: %v", err)
+			}
+			if uids := imapSearchResults(lines); len(uids) > 0 {
+				result.Details["delivery_ms"] = sinceMS(sent)
+				if !rt.KeepMessages {
+					imapDelete(ic, uids)
+				}
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("message not delivered to %s within %.1fs", mailbox, time.Since(sent).Seconds())
+			case <-time.After(interval):
+			}
+			ic.cmd("NOOP")
+		}
+	})
+	if ms, ok := imapResult.Details["auth_ms"]; ok {
+		result.Details["imap_login_ms"] = ms
+	}
+	return err
+}
+
+// imapSearchResults extracts the UIDs from "* SEARCH" responses.
+func imapSearchResults(lines []string) []string {
+	var uids []string
+	for _, line := range lines {
+		if rest, ok := strings.CutPrefix(line, "* SEARCH"); ok {
+			uids = append(uids, strings.Fields(rest)...)
+		}
+	}
+	return uids
+}
+
+// imapDelete removes delivered probes. Without UIDPLUS, a plain EXPUNGE
+// could remove other messages the user flagged, so they are only flagged.
+func imapDelete(ic *imapConn, uids []string) {
+	set := strings.Join(uids, ",")
+	if _, err := ic.cmd(`UID STORE ` + set + ` +FLAGS.SILENT (\Deleted)`); err != nil {
+		return
+	}
+	lines, err := ic.cmd("CAPABILITY")
+	if err == nil && strings.Contains(strings.ToUpper(strings.Join(lines, " ")), "UIDPLUS") {
+		ic.cmd("UID EXPUNGE " + set)
+	}
+}
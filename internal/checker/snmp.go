@@ -0,0 +1,358 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+const (
+	defaultSNMPPort      = 161
+	defaultSNMPCommunity = "public"
+
+	// sysUpTimeOID is SNMPv2-MIB::sysUpTime.0, in hundredths of a second.
+	sysUpTimeOID = "1.3.6.1.2.1.1.3.0"
+)
+
+// BER tags used by SNMPv2c (RFC 3416).
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berNull        = 0x05
+	berOID         = 0x06
+	berSequence    = 0x30
+	berIPAddress   = 0x40
+	berCounter32   = 0x41
+	berGauge32     = 0x42
+	berTimeTicks   = 0x43
+	berCounter64   = 0x46
+	berNoSuchObj   = 0x80
+	berNoSuchInst  = 0x81
+	berEndOfView   = 0x82
+	snmpGetRequest = 0xa0
+	snmpResponse   = 0xa2
+)
+
+// snmpErrors names the SNMP error-status values of a response.
+var snmpErrors = []string{"noError", "tooBig", "noSuchName", "badValue", "readOnly", "genErr"}
+
+// SNMPChecker sends an SNMPv2c GET, by default for sysUpTime, and checks
+// the returned value.
+type SNMPChecker struct{}
+
+func (c *SNMPChecker) Type() model.CheckType {
+	return model.CheckSNMP
+}
+
+func (c *SNMPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := udpOptions(monitor)
+	community, err := ResolvePassword(opts.Credentials)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+	if community == "" {
+		community = defaultSNMPCommunity
+	}
+	oidText := opts.OID
+	if oidText == "" {
+		oidText = sysUpTimeOID
+	}
+	oid, err := parseOID(oidText)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+	matches, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match})
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	var requestID int32
+	var resp *snmpGetResponse
+	request := func() []byte {
+		var b [4]byte
+		rand.Read(b[:])
+		requestID = int32(binary.BigEndian.Uint32(b[:]) &^ (1 << 31))
+		return snmpGetRequestPacket(community, requestID, oid)
+	}
+	accept := func(b []byte) bool {
+		r, err := parseSNMPResponse(b)
+		if err != nil || r.requestID != requestID {
+			return false
+		}
+		resp = r
+		return true
+	}
+
+	_, rtt, err := udpExchange(ctx, monitor, defaultSNMPPort, request, accept)
+	latency := durationMS(rtt)
+	if err != nil {
+		msg := fmt.Sprintf("snmp: %v", err)
+		if errors.Is(err, errNoReply) {
+			// Agents silently drop requests with an unknown community.
+			msg += " (wrong community?)"
+		}
+		return &Result{Status: model.StatusDown, LatencyMS: latency, Error: msg}, nil
+	}
+
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: latency,
+		Details:   map[string]any{"oid": oidText},
+	}
+	if resp.errorStatus != 0 {
+		name := fmt.Sprintf("error %d", resp.errorStatus)
+		if resp.errorStatus < len(snmpErrors) {
+			name = snmpErrors[resp.errorStatus]
+		}
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("agent returned %s", name)
+		return result, nil
+	}
+
+	value, err := formatSNMPValue(resp.valueTag, resp.value)
+	if err != nil {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("%s: %v", oidText, err)
+		return result, nil
+	}
+	result.Details["value"] = truncateActual(value)
+	if resp.valueTag == berTimeTicks {
+		ticks, _ := strconv.ParseUint(value, 10, 64)
+		result.Details["uptime_seconds"] = ticks / 100
+	}
+	if !matches([]byte(value)) {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("expected %q, got %q", opts.Expect, truncateActual(value))
+	}
+	return result, nil
+}
+
+// snmpGetResponse is the part of a Response-PDU the check uses.
+type snmpGetResponse struct {
+	requestID   int32
+	errorStatus int
+	valueTag    byte
+	value       []byte
+}
+
+// snmpGetRequestPacket encodes an SNMPv2c GetRequest for one OID.
+func snmpGetRequestPacket(community string, requestID int32, oid []uint64) []byte {
+	varbind := berTLV(berSequence, berTLV(berOID, encodeOID(oid)), berTLV(berNull))
+	pdu := berTLV(snmpGetRequest,
+		berTLV(berInteger, encodeBERInt(int64(requestID))),
+		berTLV(berInteger, encodeBERInt(0)),
+		berTLV(berInteger, encodeBERInt(0)),
+		berTLV(berSequence, varbind),
+	)
+	return berTLV(berSequence,
+		berTLV(berInteger, encodeBERInt(1)), // version: SNMPv2c
+		berTLV(berOctetString, []byte(community)),
+		pdu,
+	)
+}
+
+// parseSNMPResponse decodes an SNMPv2c Response-PDU carrying one varbind.
+func parseSNMPResponse(b []byte) (*snmpGetResponse, error) {
+	tag, msg, _, err := readBER(b)
+	if err != nil || tag != berSequence {
+		return nil, fmt.Errorf("not an SNMP message")
+	}
+	var fields [3][]byte
+	var tags [3]byte
+	for i := range fields {
+		if tags[i], fields[i], msg, err = readBER(msg); err != nil {
+			return nil, err
+		}
+	}
+	if tags[2] != snmpResponse {
+		return nil, fmt.Errorf("unexpected PDU type 0x%02x", tags[2])
+	}
+
+	pdu := fields[2]
+	var ints [3]int64
+	for i := range ints {
+		var v []byte
+		if tag, v, pdu, err = readBER(pdu); err != nil || tag != berInteger {
+			return nil, fmt.Errorf("malformed PDU")
+		}
+		ints[i] = decodeBERInt(v)
+	}
+	r := &snmpGetResponse{requestID: int32(ints[0]), errorStatus: int(ints[1])}
+
+	// varbind list → first varbind → (name, value)
+	_, list, _, err := readBER(pdu)
+	if err != nil {
+		return nil, err
+	}
+	_, varbind, _, err := readBER(list)
+	if err != nil {
+		if r.errorStatus != 0 {
+			return r, nil
+		}
+		return nil, err
+	}
+	if _, _, varbind, err = readBER(varbind); err != nil {
+		return nil, err
+	}
+	if r.valueTag, r.value, _, err = readBER(varbind); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// formatSNMPValue renders a varbind value as text.
+func formatSNMPValue(tag byte, v []byte) (string, error) {
+	switch tag {
+	case berInteger:
+		return strconv.FormatInt(decodeBERInt(v), 10), nil
+	case berOctetString:
+		return printableReply(v), nil
+	case berOID:
+		return decodeOID(v), nil
+	case berIPAddress:
+		return net.IP(v).String(), nil
+	case berCounter32, berGauge32, berTimeTicks, berCounter64:
+		return new(big.Int).SetBytes(v).String(), nil
+	case berNull:
+		return "", nil
+	case berNoSuchObj:
+		return "", fmt.Errorf("no such object")
+	case berNoSuchInst:
+		return "", fmt.Errorf("no such instance")
+	case berEndOfView:
+		return "", fmt.Errorf("end of MIB view")
+	}
+	return "", fmt.Errorf("unsupported value type 0x%02x", tag)
+}
+
+// berTLV encodes a tag, length and the concatenated contents.
+func berTLV(tag byte, contents ...[]byte) []byte {
+	var body []byte
+	for _, c := range contents {
+		body = append(body, c...)
+	}
+	out := []byte{tag}
+	switch n := len(body); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	default:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	}
+	return append(out, body...)
+}
+
+// readBER splits the first TLV off b and returns its tag, contents and the
+// bytes after it.
+func readBER(b []byte) (tag byte, contents, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, fmt.Errorf("truncated BER data")
+	}
+	tag, n, b := b[0], int(b[1]), b[2:]
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 3 || len(b) < size {
+			return 0, nil, nil, fmt.Errorf("unsupported BER length")
+		}
+		n = 0
+		for _, c := range b[:size] {
+			n = n<<8 | int(c)
+		}
+		b = b[size:]
+	}
+	if len(b) < n {
+		return 0, nil, nil, fmt.Errorf("truncated BER data")
+	}
+	return tag, b[:n], b[n:], nil
+}
+
+// encodeBERInt encodes v as a minimal two's-complement integer.
+func encodeBERInt(v int64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	i := 0
+	for i < 7 && (b[i] == 0 && b[i+1]&0x80 == 0 || b[i] == 0xff && b[i+1]&0x80 != 0) {
+		i++
+	}
+	return b[i:]
+}
+
+func decodeBERInt(b []byte) int64 {
+	var v int64
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// parseOID parses a dotted object identifier such as 1.3.6.1.2.1.1.3.0.
+func parseOID(s string) ([]uint64, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	oid := make([]uint64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid[i] = n
+	}
+	if oid[0] > 2 || oid[0] < 2 && oid[1] >= 40 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	return oid, nil
+}
+
+// encodeOID encodes an OID's arcs, the first two combined, in base 128.
+func encodeOID(oid []uint64) []byte {
+	arcs := append([]uint64{oid[0]*40 + oid[1]}, oid[2:]...)
+	var out []byte
+	for _, arc := range arcs {
+		var chunk []byte
+		for {
+			chunk = append([]byte{byte(arc & 0x7f)}, chunk...)
+			arc >>= 7
+			if arc == 0 {
+				break
+			}
+		}
+		for i := 0; i < len(chunk)-1; i++ {
+			chunk[i] |= 0x80
+		}
+		out = append(out, chunk...)
+	}
+	return out
+}
+
+func decodeOID(b []byte) string {
+	var arcs []string
+	var arc uint64
+	for _, c := range b {
+		arc = arc<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			continue
+		}
+		if len(arcs) == 0 {
+			first := min(arc/40, 2)
+			arcs = append(arcs, strconv.FormatUint(first, 10), strconv.FormatUint(arc-first*40, 10))
+		} else {
+			arcs = append(arcs, strconv.FormatUint(arc, 10))
+		}
+		arc = 0
+	}
+	return strings.Join(arcs, ".")
+}
@@ -0,0 +1,314 @@
+package checker
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// snmpResponsePacket encodes an SNMPv2c Response-PDU for one OID.
+func snmpResponsePacket(community string, requestID int32, errorStatus int64, oid []uint64, valueTag byte, value []byte) []byte {
+	varbind := berTLV(berSequence, berTLV(berOID, encodeOID(oid)), berTLV(valueTag, value))
+	pdu := berTLV(snmpResponse,
+		berTLV(berInteger, encodeBERInt(int64(requestID))),
+		berTLV(berInteger, encodeBERInt(errorStatus)),
+		berTLV(berInteger, encodeBERInt(0)),
+		berTLV(berSequence, varbind),
+	)
+	return berTLV(berSequence,
+		berTLV(berInteger, encodeBERInt(1)),
+		berTLV(berOctetString, []byte(community)),
+		pdu,
+	)
+}
+
+// snmpAgent answers GetRequests carrying the community "s3cret" from
+// values, keyed by OID and holding the value's tag and contents; a nil
+// value answers genErr. Other communities are dropped like a real agent.
+func snmpAgent(t *testing.T, values map[string][]byte) int {
+	return serveUDP(t, func(req []byte) []byte {
+		_, msg, _, err := readBER(req)
+		if err != nil {
+			return nil
+		}
+		_, _, msg, _ = readBER(msg) // version
+		_, community, msg, _ := readBER(msg)
+		tag, pdu, _, err := readBER(msg)
+		if err != nil || string(community) != "s3cret" || tag != snmpGetRequest {
+			return nil
+		}
+		_, id, pdu, _ := readBER(pdu)
+		_, _, pdu, _ = readBER(pdu)
+		_, _, pdu, _ = readBER(pdu)
+		_, list, _, _ := readBER(pdu)
+		_, varbind, _, _ := readBER(list)
+		_, name, _, _ := readBER(varbind)
+
+		oidText := decodeOID(name)
+		oid, _ := parseOID(oidText)
+		requestID := int32(decodeBERInt(id))
+		raw, ok := values[oidText]
+		if !ok {
+			return snmpResponsePacket("s3cret", requestID, 0, oid, berNoSuchObj, nil)
+		}
+		if raw == nil {
+			return snmpResponsePacket("s3cret", requestID, 5, oid, berNull, nil) // genErr
+		}
+		return snmpResponsePacket("s3cret", requestID, 0, oid, raw[0], raw[1:])
+	})
+}
+
+func TestSNMPCheck(t *testing.T) {
+	port := snmpAgent(t, map[string][]byte{
+		sysUpTimeOID:        append([]byte{berTimeTicks}, 0x01, 0x30, 0x4b), // 77899 ticks
+		"1.3.6.1.2.1.1.5.0": append([]byte{berOctetString}, "core-sw1"...),
+		"1.3.6.1.2.1.1.7.0": nil,
+	})
+	secret := model.Credentials{Password: "s3cret"}
+
+	tests := []struct {
+		name      string
+		opts      *model.UDPOptions
+		want      model.CheckStatus
+		wantValue string
+		wantError string
+	}{
+		{"uptime", &model.UDPOptions{Credentials: secret}, model.StatusUp, "77899", ""},
+		{"string value", &model.UDPOptions{Credentials: secret, OID: "1.3.6.1.2.1.1.5.0", Expect: "core-"}, model.StatusUp, "core-sw1", ""},
+		{"unexpected value", &model.UDPOptions{Credentials: secret, OID: "1.3.6.1.2.1.1.5.0", Expect: "edge-"}, model.StatusDown, "core-sw1", `expected "edge-"`},
+		{"no such object", &model.UDPOptions{Credentials: secret, OID: "1.3.6.1.2.1.1.6.0"}, model.StatusDown, "", "no such object"},
+		{"agent error", &model.UDPOptions{Credentials: secret, OID: "1.3.6.1.2.1.1.7.0"}, model.StatusDown, "", "agent returned genErr"},
+		{"wrong community", nil, model.StatusDown, "", "wrong community?"},
+		{"bad oid", &model.UDPOptions{Credentials: secret, OID: "sysUpTime"}, model.StatusDown, "", "invalid OID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkUDP(t, &SNMPChecker{}, port, tt.opts)
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+			if got, _ := result.Details["value"].(string); got != tt.wantValue {
+				t.Errorf("value = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+
+	result := checkUDP(t, &SNMPChecker{}, port, &model.UDPOptions{Credentials: secret})
+	if got := result.Details["uptime_seconds"]; got != uint64(778) {
+		t.Errorf("uptime_seconds = %v, want 778", got)
+	}
+}
+
+func TestSNMPGetRequestPacket(t *testing.T) {
+	// snmpget -v2c -c public 192.0.2.1 1.3.6.1.2.1.1.3.0, request ID 1.
+	want := []byte{
+		0x30, 0x26,
+		0x02, 0x01, 0x01,
+		0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+		0xa0, 0x19,
+		0x02, 0x01, 0x01,
+		0x02, 0x01, 0x00,
+		0x02, 0x01, 0x00,
+		0x30, 0x0e, 0x30, 0x0c,
+		0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x03, 0x00,
+		0x05, 0x00,
+	}
+	oid, _ := parseOID(sysUpTimeOID)
+	if got := snmpGetRequestPacket("public", 1, oid); !bytes.Equal(got, want) {
+		t.Errorf("snmpGetRequestPacket() = % x, want % x", got, want)
+	}
+}
+
+func TestParseSNMPResponse(t *testing.T) {
+	oid := []uint64{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	ok := snmpResponsePacket("public", 4242, 0, oid, berTimeTicks, []byte{0x64})
+
+	tests := []struct {
+		name    string
+		packet  []byte
+		want    *snmpGetResponse
+		wantErr bool
+	}{
+		{"response", ok, &snmpGetResponse{requestID: 4242, valueTag: berTimeTicks, value: []byte{0x64}}, false},
+		{"error without varbinds", berTLV(berSequence,
+			berTLV(berInteger, encodeBERInt(1)),
+			berTLV(berOctetString, []byte("public")),
+			berTLV(snmpResponse,
+				berTLV(berInteger, encodeBERInt(7)),
+				berTLV(berInteger, encodeBERInt(1)),
+				berTLV(berInteger, encodeBERInt(0)),
+				berTLV(berSequence),
+			),
+		), &snmpGetResponse{requestID: 7, errorStatus: 1}, false},
+		{"get request", snmpGetRequestPacket("public", 1, oid), nil, true},
+		{"truncated", ok[:len(ok)-3], nil, true},
+		{"not a sequence", []byte{0x04, 0x00}, nil, true},
+		{"empty", nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSNMPResponse(tt.packet)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseSNMPResponse() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseSNMPResponse() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSNMPValue(t *testing.T) {
+	tests := []struct {
+		tag     byte
+		v       []byte
+		want    string
+		wantErr bool
+	}{
+		{berInteger, []byte{0xff, 0x38}, "-200", false},
+		{berOctetString, []byte("Linux core-sw1"), "Linux core-sw1", false},
+		{berOctetString, []byte{0x00, 0x1b, 0xa4}, "001ba4", false},
+		{berOID, []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x89, 0x37}, "1.3.6.1.4.1.1207", false},
+		{berIPAddress, []byte{192, 0, 2, 1}, "192.0.2.1", false},
+		{berCounter32, []byte{0x00, 0xff, 0xff, 0xff, 0xff}, "4294967295", false},
+		{berCounter64, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, "18446744073709551616", false},
+		{berTimeTicks, []byte{0x01, 0x30, 0x4b}, "77899", false},
+		{berNull, nil, "", false},
+		{berNoSuchObj, nil, "", true},
+		{berNoSuchInst, nil, "", true},
+		{berEndOfView, nil, "", true},
+		{0x44, []byte{0x00}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := formatSNMPValue(tt.tag, tt.v)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("formatSNMPValue(0x%02x, % x) = %q, %v, want %q (error %v)", tt.tag, tt.v, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBERTLV(t *testing.T) {
+	long := bytes.Repeat([]byte{'x'}, 300)
+	tests := []struct {
+		name   string
+		tag    byte
+		body   []byte
+		header []byte
+	}{
+		{"short", berOctetString, []byte("abc"), []byte{0x04, 0x03}},
+		{"empty", berNull, nil, []byte{0x05, 0x00}},
+		{"one length byte", berOctetString, long[:200], []byte{0x04, 0x81, 0xc8}},
+		{"two length bytes", berOctetString, long, []byte{0x04, 0x82, 0x01, 0x2c}},
+	}
+	for _, tt := range tests {
+		got := berTLV(tt.tag, tt.body)
+		if !bytes.Equal(got[:len(tt.header)], tt.header) || !bytes.Equal(got[len(tt.header):], tt.body) {
+			t.Errorf("%s: berTLV() header = % x, want % x", tt.name, got[:len(tt.header)], tt.header)
+			continue
+		}
+		tag, contents, rest, err := readBER(append(got, 0xaa))
+		if err != nil || tag != tt.tag || !bytes.Equal(contents, tt.body) || !bytes.Equal(rest, []byte{0xaa}) {
+			t.Errorf("%s: readBER(berTLV()) = 0x%02x, %d bytes, rest % x, %v", tt.name, tag, len(contents), rest, err)
+		}
+	}
+}
+
+func TestReadBERErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"tag only", []byte{0x04}},
+		{"short contents", []byte{0x04, 0x05, 'a'}},
+		{"indefinite length", []byte{0x30, 0x80, 0x00, 0x00}},
+		{"length too long", []byte{0x04, 0x84, 0x00, 0x00, 0x00, 0x01, 'a'}},
+		{"missing length bytes", []byte{0x04, 0x82, 0x01}},
+	}
+	for _, tt := range tests {
+		if _, _, _, err := readBER(tt.b); err == nil {
+			t.Errorf("%s: readBER(% x) succeeded, want an error", tt.name, tt.b)
+		}
+	}
+}
+
+func TestBERInt(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x00, 0x80}},
+		{256, []byte{0x01, 0x00}},
+		{-1, []byte{0xff}},
+		{-128, []byte{0x80}},
+		{-129, []byte{0xff, 0x7f}},
+		{2147483647, []byte{0x7f, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		got := encodeBERInt(tt.v)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("encodeBERInt(%d) = % x, want % x", tt.v, got, tt.want)
+		}
+		if back := decodeBERInt(got); back != tt.v {
+			t.Errorf("decodeBERInt(% x) = %d, want %d", got, back, tt.v)
+		}
+	}
+}
+
+func TestParseOID(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []uint64
+		wantErr bool
+	}{
+		{"1.3.6.1.2.1.1.3.0", []uint64{1, 3, 6, 1, 2, 1, 1, 3, 0}, false},
+		{".1.3.6.1", []uint64{1, 3, 6, 1}, false},
+		{"2.999.1", []uint64{2, 999, 1}, false},
+		{"1", nil, true},
+		{"", nil, true},
+		{"1.3.x", nil, true},
+		{"1..3", nil, true},
+		{"3.1", nil, true},
+		{"1.40", nil, true},
+		{"1.3.4294967296", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOID(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOID(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOID(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestOIDEncoding(t *testing.T) {
+	tests := []struct {
+		oid  string
+		want []byte
+	}{
+		{"1.3.6.1.2.1.1.3.0", []byte{0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x03, 0x00}},
+		{"1.3.6.1.4.1.2021.10.1.3.1", []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x8f, 0x65, 0x0a, 0x01, 0x03, 0x01}},
+		{"2.999.3", []byte{0x88, 0x37, 0x03}},
+		{"0.0", []byte{0x00}},
+	}
+	for _, tt := range tests {
+		oid, err := parseOID(tt.oid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := encodeOID(oid)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("encodeOID(%s) = % x, want % x", tt.oid, got, tt.want)
+		}
+		if back := decodeOID(got); back != tt.oid {
+			t.Errorf("decodeOID(% x) = %s, want %s", got, back, tt.oid)
+		}
+	}
+}
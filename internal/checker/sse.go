@@ -0,0 +1,169 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// SSEChecker opens a server-sent event stream and waits for an event of the
+// configured type whose data matches the expectation.
+type SSEChecker struct{}
+
+func (c *SSEChecker) Type() model.CheckType {
+	return model.CheckSSE
+}
+
+func (c *SSEChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := streamOptions(monitor)
+	matches, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match})
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	var phases httpPhases
+	req, err := newStreamRequest(phases.withTrace(ctx), monitor)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	start := time.Now()
+	resp, err := streamClient(ctx).Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		result := &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     fmt.Sprintf("request failed: %v", err),
+		}
+		phases.addTimings(result)
+		return result, nil
+	}
+	defer resp.Body.Close()
+
+	result := &Result{
+		Status:     model.StatusUp,
+		LatencyMS:  latency,
+		StatusCode: resp.StatusCode,
+		Details:    map[string]any{"status_code": resp.StatusCode},
+	}
+	phases.addTimings(result)
+
+	if resp.StatusCode != http.StatusOK {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return result, nil
+	}
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct != "text/event-stream" {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("unexpected content type %q", resp.Header.Get("Content-Type"))
+		return result, nil
+	}
+
+	opened := time.Now()
+	waitCtx, cancel := streamWait(ctx, opts)
+	defer cancel()
+	stop := context.AfterFunc(waitCtx, func() { resp.Body.Close() })
+	defer stop()
+
+	events := newSSEReader(resp.Body)
+	received := 0
+	for {
+		ev, err := events.next()
+		if err != nil {
+			if waitCtx.Err() != nil {
+				err = nil
+			} else if err == io.EOF {
+				err = fmt.Errorf("stream ended")
+			}
+			result.Status = model.StatusDown
+			result.Error = noMessageError(opts, received, time.Since(opened), err)
+			break
+		}
+		received++
+		if (opts.Event == "" || ev.event == opts.Event) && matches([]byte(ev.data)) {
+			result.Details["first_message_ms"] = durationMS(time.Since(opened))
+			result.Details["event"] = ev.event
+			if ev.id != "" {
+				result.Details["event_id"] = ev.id
+			}
+			result.Details["message"] = printableReply([]byte(ev.data))
+			break
+		}
+	}
+	result.Details["messages"] = received
+	return result, nil
+}
+
+// sseEvent is one dispatched server-sent event.
+type sseEvent struct {
+	event string
+	data  string
+	id    string
+}
+
+// sseReader parses an event stream as described in the HTML standard.
+// Comments, such as keep-alive lines, are skipped.
+type sseReader struct {
+	r    *bufio.Reader
+	last string // last event ID seen, which carries over between events
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event with data.
+func (s *sseReader) next() (*sseEvent, error) {
+	var data []string
+	event := ""
+	size := 0
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size += len(line)
+		if size > maxStreamMessage {
+			return nil, fmt.Errorf("event exceeds %d bytes", maxStreamMessage)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// A blank line dispatches the event; events without data are
+			// dropped.
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				return &sseEvent{event: event, data: strings.Join(data, "\n"), id: s.last}, nil
+			}
+			event, size = "", 0
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.last = value
+			}
+		}
+	}
+}
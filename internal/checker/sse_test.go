@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestSSECheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" || r.Header.Get("X-Api-Key") != "k" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+			return
+		case "/error":
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		io.WriteString(w, ": keep-alive\n\n")
+		io.WriteString(w, "data: hello\n\n")
+		io.WriteString(w, "event: status\nid: 7\ndata: {\"ok\":\ndata: true}\n\n")
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/ends" {
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	key := &model.HTTPOptions{Headers: map[string]string{"X-Api-Key": "k"}}
+
+	tests := []struct {
+		name         string
+		path         string
+		opts         *model.StreamOptions
+		httpOpts     *model.HTTPOptions
+		want         model.CheckStatus
+		wantEvent    string
+		wantMessage  string
+		wantError    string
+		wantMessages int
+	}{
+		{"first event", "/events", nil, key, model.StatusUp, "message", "hello", "", 1},
+		{"event type", "/events", &model.StreamOptions{Event: "status"}, key, model.StatusUp, "status", "{\"ok\":\ntrue}", "", 2},
+		{"matching data", "/events", &model.StreamOptions{Expect: `"ok":\s*true`, Match: "regex"}, key, model.StatusUp, "status", "{\"ok\":\ntrue}", "", 2},
+		{"no matching event", "/events", &model.StreamOptions{Event: "alert", WaitMS: 200}, key, model.StatusDown, "", "",
+			`no "alert" event within`, 2},
+		{"stream ends", "/ends", &model.StreamOptions{Expect: "bye"}, key, model.StatusDown, "", "",
+			`no message matching "bye" after 2 received: stream ended`, 2},
+		{"not an event stream", "/json", nil, key, model.StatusDown, "", "", `unexpected content type "application/json"`, 0},
+		{"http error", "/error", nil, key, model.StatusDown, "", "", "HTTP 503", 0},
+		{"missing header", "/events", nil, nil, model.StatusDown, "", "", "HTTP 403", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkStream(t, &SSEChecker{}, srv.URL+tt.path, tt.opts, tt.httpOpts)
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+			if got, _ := result.Details["event"].(string); got != tt.wantEvent {
+				t.Errorf("event = %q, want %q", got, tt.wantEvent)
+			}
+			if got, _ := result.Details["message"].(string); got != tt.wantMessage {
+				t.Errorf("message = %q, want %q", got, tt.wantMessage)
+			}
+			if got, _ := result.Details["messages"].(int); got != tt.wantMessages {
+				t.Errorf("messages = %d, want %d", got, tt.wantMessages)
+			}
+		})
+	}
+
+	result := checkStream(t, &SSEChecker{}, srv.URL+"/events", &model.StreamOptions{Event: "status"}, key)
+	if result.Details["event_id"] != "7" {
+		t.Errorf("event_id = %v, want 7", result.Details["event_id"])
+	}
+}
+
+func TestSSEReader(t *testing.T) {
+	stream := ": connected\n\n" +
+		"data: first\r\n\r\n" +
+		"event: update\nid: 41\ndata:no space\ndata:  two spaces\n\n" +
+		"event: empty\n\n" + // no data: dropped
+		"retry: 1000\ndata: carries the id\n\n" +
+		"id: bad\x00id\nevent: update\ndata: keeps id\n\n" +
+		"data: unterminated"
+	want := []sseEvent{
+		{event: "message", data: "first"},
+		{event: "update", data: "no space\n two spaces", id: "41"},
+		{event: "message", data: "carries the id", id: "41"},
+		{event: "update", data: "keeps id", id: "41"},
+	}
+
+	r := newSSEReader(strings.NewReader(stream))
+	var got []sseEvent
+	for {
+		ev, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, *ev)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestSSEReaderLimit(t *testing.T) {
+	big := "data: " + strings.Repeat("x", maxStreamMessage) + "\n\n"
+	if _, err := newSSEReader(strings.NewReader(big)).next(); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("next() error = %v, want the event size limit", err)
+	}
+}
@@ -0,0 +1,115 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// maxStreamMessage caps the size of a single WebSocket message or
+// server-sent event.
+const maxStreamMessage = 1 << 20
+
+// streamOptions returns the monitor's stream options, or defaults.
+func streamOptions(monitor *model.Monitor) *model.StreamOptions {
+	if monitor.Options != nil && monitor.Options.Stream != nil {
+		return monitor.Options.Stream
+	}
+	return &model.StreamOptions{}
+}
+
+// StreamURL parses the target of a websocket or sse monitor. ws:// and
+// wss:// are accepted as http:// and https://; bare hosts use HTTPS.
+func StreamURL(target string, port int) (*url.URL, error) {
+	target = strings.TrimSpace(target)
+	lower := strings.ToLower(target)
+	switch {
+	case strings.HasPrefix(lower, "ws://"):
+		target = "http://" + target[len("ws://"):]
+	case strings.HasPrefix(lower, "wss://"):
+		target = "https://" + target[len("wss://"):]
+	}
+	return TargetURL(target, targetScheme(target, "https"), port)
+}
+
+// newStreamRequest builds the GET that opens a stream, with the monitor's
+// headers and authentication.
+func newStreamRequest(ctx context.Context, monitor *model.Monitor) (*http.Request, error) {
+	u, err := StreamURL(monitor.Target, monitor.Port)
+	if err != nil {
+		return nil, err
+	}
+	opts := &model.HTTPOptions{}
+	if monitor.Options != nil && monitor.Options.HTTP != nil {
+		opts = monitor.Options.HTTP
+	}
+	req, err := newHTTPRequest(ctx, u.String(), opts)
+	if err != nil {
+		return nil, err
+	}
+	req.Method = http.MethodGet
+	req.Body, req.GetBody, req.ContentLength = nil, nil, 0
+	return req, nil
+}
+
+// streamClient returns an HTTP/1.1 client for opening streams. The monitor
+// timeout is enforced through the request context, since a client timeout
+// would also cut off the stream.
+func streamClient(ctx context.Context) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{},
+			DialContext:       familyDialer(ctx, &net.Dialer{}),
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+// streamWait returns a context that ends WaitMS after the stream opened,
+// or with ctx if that is sooner.
+func streamWait(ctx context.Context, opts *model.StreamOptions) (context.Context, context.CancelFunc) {
+	if opts.WaitMS > 0 {
+		return context.WithTimeout(ctx, time.Duration(opts.WaitMS)*time.Millisecond)
+	}
+	return context.WithCancel(ctx)
+}
+
+// noMessageError describes a stream that ended without a matching message.
+func noMessageError(opts *model.StreamOptions, received int, waited time.Duration, err error) string {
+	what := "no message"
+	if opts.Event != "" {
+		what = fmt.Sprintf("no %q event", opts.Event)
+	}
+	if opts.Expect != "" {
+		what += fmt.Sprintf(" matching %q", opts.Expect)
+	}
+	if err != nil {
+		return fmt.Sprintf("%s after %d received: %v", what, received, err)
+	}
+	return fmt.Sprintf("%s within %s (%d received)", what, waited.Round(time.Millisecond), received)
+}
+
+// ValidateStreamOptions checks the settings shared by the websocket and
+// sse checkers.
+func ValidateStreamOptions(checkType model.CheckType, opts *model.StreamOptions) error {
+	if _, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match}); err != nil {
+		return err
+	}
+	if opts.WaitMS < 0 {
+		return fmt.Errorf("wait_ms must not be negative")
+	}
+	if opts.Send != "" && checkType != model.CheckWebSocket {
+		return fmt.Errorf("send only applies to websocket checks")
+	}
+	if opts.Event != "" && checkType != model.CheckSSE {
+		return fmt.Errorf("event only applies to sse checks")
+	}
+	return nil
+}
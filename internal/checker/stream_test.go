@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// checkStream runs a stream checker against target with a two-second
+// timeout.
+func checkStream(t *testing.T, c Checker, target string, opts *model.StreamOptions, httpOpts *model.HTTPOptions) *Result {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	m := &model.Monitor{Target: target, Options: &model.MonitorOptions{Stream: opts, HTTP: httpOpts}}
+	result, err := c.Check(ctx, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestStreamURL(t *testing.T) {
+	tests := []struct {
+		target  string
+		port    int
+		want    string
+		wantErr bool
+	}{
+		{"ws://example.com/live", 0, "http://example.com/live", false},
+		{"WSS://example.com/live?room=1", 0, "https://example.com/live?room=1", false},
+		{"wss://example.com:8443/live", 0, "https://example.com:8443/live", false},
+		{"example.com/events", 0, "https://example.com/events", false},
+		{"example.com", 8080, "https://example.com:8080", false},
+		{"http://example.com/events", 0, "http://example.com/events", false},
+		{"", 0, "", true},
+		{"ftp://example.com", 0, "", true},
+	}
+	for _, tt := range tests {
+		got, err := StreamURL(tt.target, tt.port)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("StreamURL(%q, %d) error = %v, wantErr %v", tt.target, tt.port, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("StreamURL(%q, %d) = %s, want %s", tt.target, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestNoMessageError(t *testing.T) {
+	tests := []struct {
+		opts     model.StreamOptions
+		received int
+		err      error
+		want     string
+	}{
+		{model.StreamOptions{}, 0, nil, "no message within 1.5s (0 received)"},
+		{model.StreamOptions{Expect: "pong"}, 3, nil, `no message matching "pong" within 1.5s (3 received)`},
+		{model.StreamOptions{Event: "status", Expect: "ok"}, 2, nil, `no "status" event matching "ok" within 1.5s (2 received)`},
+		{model.StreamOptions{}, 1, errors.New("stream ended"), "no message after 1 received: stream ended"},
+	}
+	for _, tt := range tests {
+		if got := noMessageError(&tt.opts, tt.received, 1500*time.Millisecond, tt.err); got != tt.want {
+			t.Errorf("noMessageError(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestValidateStreamOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType model.CheckType
+		opts      model.StreamOptions
+		wantErr   bool
+	}{
+		{"websocket send", model.CheckWebSocket, model.StreamOptions{Send: "ping", Expect: "pong", WaitMS: 5000}, false},
+		{"sse event", model.CheckSSE, model.StreamOptions{Event: "status", Expect: `"ok":\s*true`, Match: "regex"}, false},
+		{"bad regex", model.CheckSSE, model.StreamOptions{Expect: "(", Match: "regex"}, true},
+		{"negative wait", model.CheckWebSocket, model.StreamOptions{WaitMS: -1}, true},
+		{"send on sse", model.CheckSSE, model.StreamOptions{Send: "ping"}, true},
+		{"event on websocket", model.CheckWebSocket, model.StreamOptions{Event: "status"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateStreamOptions(tt.checkType, &tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateStreamOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
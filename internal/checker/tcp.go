@@ -1,14 +1,25 @@
 package checker
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/model"
 )
 
+// maxTCPRead caps how much a single banner read or expect step buffers.
+const maxTCPRead = 64 * 1024
+
 // TCPChecker performs TCP port connectivity checks.
 type TCPChecker struct{}
 
@@ -18,12 +29,14 @@ func (c *TCPChecker) Type() model.CheckType {
 
 func (c *TCPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
 	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
-	address := fmt.Sprintf("%s:%d", monitor.Target, monitor.Port)
+	host := strings.Trim(monitor.Target, "[]")
+	address := net.JoinHostPort(host, strconv.Itoa(monitor.Port))
 
 	start := time.Now()
+	deadline := start.Add(timeout)
 
 	dialer := &net.Dialer{Timeout: timeout}
-	conn, err := dialer.DialContext(ctx, "tcp", address)
+	conn, err := dialContext(ctx, dialer, "tcp", address)
 	latency := float64(time.Since(start).Microseconds()) / 1000.0
 
 	if err != nil {
@@ -33,10 +46,167 @@ func (c *TCPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result
 			Error:     fmt.Sprintf("tcp connect failed: %v", err),
 		}, nil
 	}
-	conn.Close()
+	defer conn.Close()
+
+	if monitor.Options == nil || monitor.Options.TCP == nil {
+		return &Result{
+			Status:    model.StatusUp,
+			LatencyMS: latency,
+		}, nil
+	}
+	opts := monitor.Options.TCP
+
+	result := &Result{
+		Status:  model.StatusUp,
+		Details: map[string]any{"connect_ms": latency},
+	}
+	conn.SetDeadline(deadline)
+
+	if opts.TLS {
+		serverName := opts.ServerName
+		if serverName == "" {
+			serverName = host
+		}
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: opts.SkipVerify,
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("tls handshake failed: %v", err)
+			result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+			return result, nil
+		}
+		state := tlsConn.ConnectionState()
+		result.Details["tls_version"] = tls.VersionName(state.Version)
+		conn = tlsConn
+	}
+
+	if opts.ReadBanner {
+		banner, err := readChunk(conn, deadline)
+		if len(banner) > 0 {
+			result.Details["banner"] = truncateActual(strings.TrimSpace(string(banner)))
+		}
+		if err != nil && len(banner) == 0 {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("reading banner: %v", err)
+		}
+	}
+
+	if result.Status == model.StatusUp {
+		if err := runTCPSteps(conn, opts.Steps, deadline, result); err != nil {
+			result.Status = model.StatusDown
+			result.Error = err.Error()
+		}
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000.0
+	return result, nil
+}
+
+// runTCPSteps executes the send/expect script, recording each response in
+// result details.
+func runTCPSteps(conn net.Conn, steps []model.TCPStep, deadline time.Time, result *Result) error {
+	var responses []string
+	defer func() {
+		if len(responses) > 0 {
+			result.Details["responses"] = responses
+		}
+	}()
+
+	for i, step := range steps {
+		if step.Send != "" {
+			if _, err := conn.Write([]byte(step.Send)); err != nil {
+				return fmt.Errorf("step %d: send failed: %v", i+1, err)
+			}
+		}
+		if step.Expect == "" {
+			continue
+		}
+
+		readDeadline := deadline
+		if step.ReadTimeoutMS > 0 {
+			readDeadline = time.Now().Add(time.Duration(step.ReadTimeoutMS) * time.Millisecond)
+			if readDeadline.After(deadline) {
+				readDeadline = deadline
+			}
+		}
+
+		matches, err := expectMatcher(step)
+		if err != nil {
+			return fmt.Errorf("step %d: %v", i+1, err)
+		}
+
+		got, err := readUntil(conn, readDeadline, matches)
+		responses = append(responses, truncateActual(string(got)))
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, io.EOF) {
+				return fmt.Errorf("step %d: expected %q, got %q", i+1, step.Expect, truncateActual(string(got)))
+			}
+			return fmt.Errorf("step %d: read failed: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// expectMatcher returns a function reporting whether a step's expectation
+// is met by the data read so far.
+func expectMatcher(step model.TCPStep) (func([]byte) bool, error) {
+	switch step.Match {
+	case "", "contains":
+		expect := []byte(step.Expect)
+		return func(b []byte) bool { return bytes.Contains(b, expect) }, nil
+	case "regex":
+		re, err := regexp.Compile(step.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %v", err)
+		}
+		return re.Match, nil
+	}
+	return nil, fmt.Errorf("unknown match %q", step.Match)
+}
+
+// readChunk waits for the first data from the server.
+func readChunk(conn net.Conn, deadline time.Time) ([]byte, error) {
+	conn.SetReadDeadline(deadline)
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	return buf[:n], err
+}
+
+// readUntil reads until matches reports true, the deadline passes, the
+// server closes the connection or maxTCPRead bytes have been buffered.
+func readUntil(conn net.Conn, deadline time.Time, matches func([]byte) bool) ([]byte, error) {
+	conn.SetReadDeadline(deadline)
+	var got []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		got = append(got, buf[:n]...)
+		if matches(got) {
+			return got, nil
+		}
+		if err != nil {
+			return got, err
+		}
+		if len(got) >= maxTCPRead {
+			return got, fmt.Errorf("no match in first %d bytes", maxTCPRead)
+		}
+	}
+}
 
-	return &Result{
-		Status:    model.StatusUp,
-		LatencyMS: latency,
-	}, nil
+// ValidateTCPOptions checks that every step is well formed.
+func ValidateTCPOptions(opts *model.TCPOptions) error {
+	for i, step := range opts.Steps {
+		if step.Send == "" && step.Expect == "" {
+			return fmt.Errorf("step %d: needs send or expect", i+1)
+		}
+		if step.ReadTimeoutMS < 0 {
+			return fmt.Errorf("step %d: read_timeout_ms must not be negative", i+1)
+		}
+		if _, err := expectMatcher(step); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestValidateTCPOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []model.TCPStep
+		wantErr bool
+	}{
+		{"send and expect", []model.TCPStep{{Send: "PING\r\n", Expect: "+PONG"}}, false},
+		{"regex", []model.TCPStep{{Expect: `^220 `, Match: "regex"}}, false},
+		{"empty step", []model.TCPStep{{}}, true},
+		{"negative read timeout", []model.TCPStep{{Expect: "x", ReadTimeoutMS: -1}}, true},
+		{"bad regex", []model.TCPStep{{Expect: "(", Match: "regex"}}, true},
+		{"unknown match", []model.TCPStep{{Expect: "x", Match: "glob"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateTCPOptions(&model.TCPOptions{Steps: tt.steps}); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateTCPOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTCPCheck(t *testing.T) {
+	port := serveLines(t, "220 mail.example.com ESMTP\r\n", func(line string) string {
+		switch line {
+		case "PING":
+			return "+PONG\r\n"
+		case "INFO":
+			return "version:7.2.4\r\nrole:master\r\n"
+		case "QUIT":
+			return ""
+		}
+		return "-ERR unknown command\r\n"
+	})
+
+	tests := []struct {
+		name       string
+		opts       *model.TCPOptions
+		wantUp     bool
+		wantBanner string
+	}{
+		{"connect only", nil, true, ""},
+		{"banner", &model.TCPOptions{ReadBanner: true}, true, "220 mail.example.com ESMTP"},
+		{"send and expect", &model.TCPOptions{ReadBanner: true, Steps: []model.TCPStep{
+			{Send: "PING\r\n", Expect: "+PONG"},
+			{Send: "INFO\r\n", Expect: `role:(master|replica)`, Match: "regex"},
+		}}, true, "220 mail.example.com ESMTP"},
+		{"expect a banner as a step", &model.TCPOptions{Steps: []model.TCPStep{{Expect: "ESMTP"}}}, true, ""},
+		{"unexpected response", &model.TCPOptions{ReadBanner: true, Steps: []model.TCPStep{
+			{Send: "FLUSHALL\r\n", Expect: "+OK", ReadTimeoutMS: 200},
+		}}, false, "220 mail.example.com ESMTP"},
+		{"server closes", &model.TCPOptions{ReadBanner: true, Steps: []model.TCPStep{
+			{Send: "QUIT\r\n", Expect: "221"},
+		}}, false, "220 mail.example.com ESMTP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: "127.0.0.1", Port: port, TimeoutMS: 2000}
+			if tt.opts != nil {
+				m.Options = &model.MonitorOptions{TCP: tt.opts}
+			}
+			result, err := (&TCPChecker{}).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (result.Status == model.StatusUp) != tt.wantUp {
+				t.Errorf("status = %s (%s), want up %v", result.Status, result.Error, tt.wantUp)
+			}
+			if banner, _ := result.Details["banner"].(string); banner != tt.wantBanner {
+				t.Errorf("banner = %q, want %q", banner, tt.wantBanner)
+			}
+		})
+	}
+}
+
+func TestTCPCheckTLS(t *testing.T) {
+	host, port, _ := tlsTestServer(t)
+
+	tests := []struct {
+		name   string
+		opts   model.TCPOptions
+		wantUp bool
+	}{
+		{"untrusted certificate", model.TCPOptions{TLS: true, ServerName: "example.com"}, false},
+		{"skip verify", model.TCPOptions{TLS: true, SkipVerify: true}, true},
+	}
+	for _, tt := range tests {
+		m := &model.Monitor{Target: host, Port: port, TimeoutMS: 2000, Options: &model.MonitorOptions{TCP: &tt.opts}}
+		result, _ := (&TCPChecker{}).Check(context.Background(), m)
+		if (result.Status == model.StatusUp) != tt.wantUp {
+			t.Errorf("%s: status = %s (%s), want up %v", tt.name, result.Status, result.Error, tt.wantUp)
+		}
+		if !tt.wantUp && !strings.Contains(result.Error, "tls handshake failed") {
+			t.Errorf("%s: error = %q, want a handshake failure", tt.name, result.Error)
+		}
+	}
+}
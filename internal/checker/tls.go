@@ -0,0 +1,290 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// defaultExpiryWarnDays is how close to expiry a certificate may get before
+// checks report degraded.
+const defaultExpiryWarnDays = 7
+
+// TLSChecker connects to a TLS endpoint, optionally via STARTTLS, and
+// validates the certificate chain, hostname and expiry.
+type TLSChecker struct{}
+
+func (c *TLSChecker) Type() model.CheckType {
+	return model.CheckTLS
+}
+
+func (c *TLSChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	timeout := time.Duration(monitor.TimeoutMS) * time.Millisecond
+
+	opts := &model.TLSOptions{}
+	if monitor.Options != nil && monitor.Options.TLS != nil {
+		opts = monitor.Options.TLS
+	}
+
+	host := strings.Trim(monitor.Target, "[]")
+	port := monitor.Port
+	if port == 0 {
+		port = defaultTLSPort(opts.StartTLS)
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	serverName := opts.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	start := time.Now()
+	state, err := tlsHandshake(ctx, address, serverName, opts.StartTLS, timeout)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: latency,
+		Details:   map[string]any{},
+	}
+	if opts.StartTLS != "" {
+		result.Details["starttls"] = opts.StartTLS
+	}
+
+	// Verification was skipped during the handshake so the certificate can
+	// still be recorded when it is invalid; do it now.
+	chainErr, hostErr := verifyPeerCertificates(state.PeerCertificates, serverName)
+	result.Details["tls_chain_valid"] = chainErr == nil
+	result.Details["tls_hostname_valid"] = hostErr == nil
+
+	addCertDetails(result, state)
+	applyExpiry(result, state, opts.ExpiryWarnDays)
+	applyVerification(result, chainErr, hostErr)
+
+	return result, nil
+}
+
+// applyVerification marks the result down for a chain or hostname failure,
+// after any expiry message. An expired leaf also fails chain verification,
+// so that error is left out when it only repeats the expiry.
+func applyVerification(result *Result, chainErr, hostErr error) {
+	var invalid x509.CertificateInvalidError
+	if errors.As(chainErr, &invalid) && invalid.Reason == x509.Expired && result.Status == model.StatusDown {
+		chainErr = nil
+	}
+
+	var msg string
+	switch {
+	case chainErr != nil:
+		msg = fmt.Sprintf("untrusted certificate chain: %v", chainErr)
+	case hostErr != nil:
+		msg = fmt.Sprintf("certificate hostname mismatch: %v", hostErr)
+	default:
+		return
+	}
+	result.Status = model.StatusDown
+	if result.Error != "" {
+		msg = result.Error + "; " + msg
+	}
+	result.Error = msg
+}
+
+func defaultTLSPort(startTLS string) int {
+	switch startTLS {
+	case "smtp":
+		return 25
+	case "imap":
+		return 143
+	case "pop3":
+		return 110
+	}
+	return 443
+}
+
+// tlsHandshake dials address, upgrades via STARTTLS when requested, and
+// completes a TLS handshake without verifying the peer.
+func tlsHandshake(ctx context.Context, address, serverName, startTLS string, timeout time.Duration) (*tls.ConnectionState, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialContext(ctx, dialer, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("tcp connect failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // verified separately to keep details for bad certs
+	}
+
+	switch startTLS {
+	case "":
+	case "smtp":
+		// net/smtp handles EHLO and multi-line replies for us.
+		client, err := smtp.NewClient(conn, serverName)
+		if err != nil {
+			return nil, fmt.Errorf("smtp greeting: %v", err)
+		}
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return nil, fmt.Errorf("smtp server does not advertise STARTTLS")
+		}
+		if err := client.StartTLS(cfg); err != nil {
+			return nil, fmt.Errorf("smtp STARTTLS: %v", err)
+		}
+		state, _ := client.TLSConnectionState()
+		client.Quit()
+		return &state, nil
+	case "imap", "pop3":
+		if err := upgradeText(conn, startTLS); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported starttls protocol %q", startTLS)
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("tls handshake failed: %v", err)
+	}
+	state := tlsConn.ConnectionState()
+	return &state, nil
+}
+
+// upgradeText performs the plaintext part of an IMAP or POP3 STARTTLS exchange.
+func upgradeText(conn net.Conn, protocol string) error {
+	r := bufio.NewReader(conn)
+
+	greeting, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("%s greeting: %v", protocol, err)
+	}
+
+	var command, okPrefix string
+	if protocol == "imap" {
+		if !strings.HasPrefix(greeting, "* OK") {
+			return fmt.Errorf("imap greeting: %s", strings.TrimSpace(greeting))
+		}
+		command, okPrefix = "a1 STARTTLS\r\n", "a1 OK"
+	} else {
+		if !strings.HasPrefix(greeting, "+OK") {
+			return fmt.Errorf("pop3 greeting: %s", strings.TrimSpace(greeting))
+		}
+		command, okPrefix = "STLS\r\n", "+OK"
+	}
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return fmt.Errorf("%s STARTTLS: %v", protocol, err)
+	}
+
+	// IMAP servers may send untagged lines before the tagged response.
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("%s STARTTLS: %v", protocol, err)
+		}
+		if strings.HasPrefix(line, "* ") && protocol == "imap" {
+			continue
+		}
+		if !strings.HasPrefix(line, okPrefix) {
+			return fmt.Errorf("%s STARTTLS refused: %s", protocol, strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+// verifyPeerCertificates validates the chain against the system roots and
+// the leaf against serverName, reporting the two failures separately.
+func verifyPeerCertificates(certs []*x509.Certificate, serverName string) (chainErr, hostErr error) {
+	if len(certs) == 0 {
+		err := fmt.Errorf("no peer certificates")
+		return err, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := certs[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		chainErr = err
+	}
+	if err := leaf.VerifyHostname(serverName); err != nil {
+		hostErr = err
+	}
+	return chainErr, hostErr
+}
+
+// addCertDetails records the negotiated connection and certificate chain.
+func addCertDetails(result *Result, state *tls.ConnectionState) {
+	result.Details["tls_version"] = tls.VersionName(state.Version)
+	result.Details["tls_cipher_suite"] = tls.CipherSuiteName(state.CipherSuite)
+
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+
+	leaf := state.PeerCertificates[0]
+	result.Details["tls_subject"] = leaf.Subject.CommonName
+	result.Details["tls_issuer"] = leaf.Issuer.CommonName
+	result.Details["tls_not_before"] = leaf.NotBefore.UTC().Format(time.RFC3339)
+	result.Details["tls_not_after"] = leaf.NotAfter.UTC().Format(time.RFC3339)
+
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	result.Details["tls_sans"] = sans
+
+	chain := make([]map[string]string, 0, len(state.PeerCertificates))
+	for _, cert := range state.PeerCertificates {
+		chain = append(chain, map[string]string{
+			"subject":   cert.Subject.String(),
+			"issuer":    cert.Issuer.String(),
+			"not_after": cert.NotAfter.UTC().Format(time.RFC3339),
+		})
+	}
+	result.Details["tls_chain"] = chain
+}
+
+// applyExpiry marks the result down for an expired leaf certificate and
+// degraded when it expires within warnDays.
+func applyExpiry(result *Result, state *tls.ConnectionState, warnDays int) {
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	if warnDays <= 0 {
+		warnDays = defaultExpiryWarnDays
+	}
+
+	leaf := state.PeerCertificates[0]
+	daysUntilExpiry := time.Until(leaf.NotAfter).Hours() / 24
+	result.Details["tls_expiry_days"] = int(daysUntilExpiry)
+
+	switch {
+	case daysUntilExpiry < 0:
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("TLS certificate expired %d days ago", -int(daysUntilExpiry))
+	case daysUntilExpiry < float64(warnDays):
+		result.Status = model.StatusDegraded
+		result.Error = fmt.Sprintf("TLS certificate expires in %d days", int(daysUntilExpiry))
+	}
+}
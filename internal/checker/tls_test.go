@@ -0,0 +1,187 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// tlsTestServer returns the host and port of a TLS server with the
+// httptest certificate, which is valid for example.com and 127.0.0.1 but
+// not signed by a trusted root.
+func tlsTestServer(t *testing.T) (string, int, *tls.Config) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return u.Hostname(), port, srv.TLS
+}
+
+// startTLSServer serves one plaintext STARTTLS exchange and then a TLS
+// handshake with cfg, returning the listening port.
+func startTLSServer(t *testing.T, cfg *tls.Config, greeting, reply string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(greeting + "\r\n"))
+		if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+			return
+		}
+		conn.Write([]byte(reply + "\r\n"))
+		tls.Server(conn, cfg).Handshake()
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestTLSCheck(t *testing.T) {
+	host, port, _ := tlsTestServer(t)
+
+	tests := []struct {
+		name       string
+		serverName string
+		wantHost   bool
+	}{
+		{"certificate hostname", "example.com", true},
+		{"wrong hostname", "pingmesh.invalid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Monitor{Target: host, Port: port, TimeoutMS: 5000,
+				Options: &model.MonitorOptions{TLS: &model.TLSOptions{ServerName: tt.serverName}}}
+			result, err := (&TLSChecker{}).Check(context.Background(), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The test certificate is self-signed, so the check is down but
+			// still records the certificate.
+			if result.Status != model.StatusDown || result.Details["tls_chain_valid"] != false {
+				t.Errorf("result = %s %v, want down with an untrusted chain", result.Status, result.Details)
+			}
+			if result.Details["tls_hostname_valid"] != tt.wantHost {
+				t.Errorf("tls_hostname_valid = %v, want %v", result.Details["tls_hostname_valid"], tt.wantHost)
+			}
+			if _, ok := result.Details["tls_not_after"]; !ok {
+				t.Error("certificate details missing")
+			}
+		})
+	}
+}
+
+func TestTLSCheckStartTLS(t *testing.T) {
+	_, _, cfg := tlsTestServer(t)
+
+	tests := []struct {
+		protocol, greeting, reply string
+		wantUp                    bool
+	}{
+		{"imap", "* OK IMAP ready", "a1 OK begin TLS", true},
+		{"pop3", "+OK POP3 ready", "+OK begin TLS", true},
+		{"imap", "* OK IMAP ready", "a1 NO not now", false},
+		{"pop3", "-ERR go away", "", false},
+	}
+	for _, tt := range tests {
+		port := startTLSServer(t, cfg, tt.greeting, tt.reply)
+		m := &model.Monitor{Target: "127.0.0.1", Port: port, TimeoutMS: 5000,
+			Options: &model.MonitorOptions{TLS: &model.TLSOptions{StartTLS: tt.protocol}}}
+		result, _ := (&TLSChecker{}).Check(context.Background(), m)
+
+		// A completed handshake records the certificate, even though the
+		// test certificate leaves the check down.
+		_, handshook := result.Details["tls_version"]
+		if handshook != tt.wantUp {
+			t.Errorf("%s %q: handshake = %v (%s), want %v", tt.protocol, tt.reply, handshook, result.Error, tt.wantUp)
+		}
+	}
+}
+
+func TestApplyExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		expires  time.Duration
+		warnDays int
+		want     model.CheckStatus
+	}{
+		{"valid", 90 * 24 * time.Hour, 0, model.StatusUp},
+		{"within default warning", 3 * 24 * time.Hour, 0, model.StatusDegraded},
+		{"outside custom warning", 3 * 24 * time.Hour, 2, model.StatusUp},
+		{"within custom warning", 20 * 24 * time.Hour, 30, model.StatusDegraded},
+		{"expired", -48 * time.Hour, 0, model.StatusDown},
+	}
+	for _, tt := range tests {
+		result := &Result{Status: model.StatusUp, Details: map[string]any{}}
+		state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{NotAfter: time.Now().Add(tt.expires)}}}
+		applyExpiry(result, state, tt.warnDays)
+		if result.Status != tt.want {
+			t.Errorf("%s: status = %s (%s), want %s", tt.name, result.Status, result.Error, tt.want)
+		}
+	}
+}
+
+func TestDefaultTLSPort(t *testing.T) {
+	for startTLS, want := range map[string]int{"": 443, "smtp": 25, "imap": 143, "pop3": 110} {
+		if got := defaultTLSPort(startTLS); got != want {
+			t.Errorf("defaultTLSPort(%q) = %d, want %d", startTLS, got, want)
+		}
+	}
+}
+
+func TestApplyVerification(t *testing.T) {
+	expiredErr := x509.CertificateInvalidError{Reason: x509.Expired}
+	untrusted := x509.UnknownAuthorityError{}
+	mismatch := errors.New("not valid for example.com")
+
+	tests := []struct {
+		name       string
+		status     model.CheckStatus
+		error      string
+		chainErr   error
+		hostErr    error
+		wantStatus model.CheckStatus
+		wantError  string
+	}{
+		{"valid", model.StatusUp, "", nil, nil, model.StatusUp, ""},
+		{"untrusted", model.StatusUp, "", untrusted, nil, model.StatusDown,
+			"untrusted certificate chain: x509: certificate signed by unknown authority"},
+		{"hostname", model.StatusUp, "", nil, mismatch, model.StatusDown,
+			"certificate hostname mismatch: not valid for example.com"},
+		{"expired", model.StatusDown, "TLS certificate expired 3 days ago", expiredErr, nil, model.StatusDown,
+			"TLS certificate expired 3 days ago"},
+		{"expired with wrong hostname", model.StatusDown, "TLS certificate expired 3 days ago", expiredErr, mismatch, model.StatusDown,
+			"TLS certificate expired 3 days ago; certificate hostname mismatch: not valid for example.com"},
+		{"expiring and untrusted", model.StatusDegraded, "TLS certificate expires in 2 days", untrusted, nil, model.StatusDown,
+			"TLS certificate expires in 2 days; untrusted certificate chain: x509: certificate signed by unknown authority"},
+		// Not yet valid fails verification with the same reason but is not
+		// reported by applyExpiry.
+		{"not yet valid", model.StatusUp, "", expiredErr, nil, model.StatusDown,
+			"untrusted certificate chain: x509: certificate has expired or is not yet valid: "},
+	}
+	for _, tt := range tests {
+		result := &Result{Status: tt.status, Error: tt.error}
+		applyVerification(result, tt.chainErr, tt.hostErr)
+		if result.Status != tt.wantStatus || result.Error != tt.wantError {
+			t.Errorf("%s: result = %s (%s), want %s (%s)", tt.name, result.Status, result.Error, tt.wantStatus, tt.wantError)
+		}
+	}
+}
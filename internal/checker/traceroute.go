@@ -0,0 +1,639 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	defaultMaxHops        = 30
+	defaultTraceProbes    = 3
+	defaultProbeTimeoutMS = 1000
+	defaultUDPTracePort   = 33434
+)
+
+// TracerouteChecker traces the network path to a target. The check is up
+// when the destination answers within max_hops.
+type TracerouteChecker struct{}
+
+func (c *TracerouteChecker) Type() model.CheckType {
+	return model.CheckTraceroute
+}
+
+func (c *TracerouteChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	start := time.Now()
+	trace := TraceMonitor(ctx, monitor)
+
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000.0,
+		Details: map[string]any{
+			"protocol":  trace.Protocol,
+			"reached":   trace.Reached,
+			"hop_count": len(trace.Hops),
+			"hops":      trace.Hops,
+		},
+	}
+
+	switch {
+	case trace.Error != "":
+		result.Status = model.StatusDown
+		result.Error = trace.Error
+	case !trace.Reached:
+		result.Status = model.StatusDown
+		result.Error = unreachedError(trace)
+	default:
+		result.LatencyMS = trace.Hops[len(trace.Hops)-1].AvgMS
+	}
+	return result, nil
+}
+
+// unreachedError names the last hop that answered.
+func unreachedError(trace *model.TraceResult) string {
+	for i := len(trace.Hops) - 1; i >= 0; i-- {
+		if hop := trace.Hops[i]; hop.Address != "" {
+			return fmt.Sprintf("destination not reached; last response from %s at hop %d", hop.Address, hop.TTL)
+		}
+	}
+	return "destination not reached; no hop responded"
+}
+
+// TraceMonitor traces the path to a monitor's target host using the
+// monitor's traceroute options. It works for every check type, so it can
+// capture the path when any monitor fails.
+func TraceMonitor(ctx context.Context, monitor *model.Monitor) *model.TraceResult {
+	opts := model.TracerouteOptions{}
+	if monitor.Options != nil && monitor.Options.Traceroute != nil {
+		opts = *monitor.Options.Traceroute
+	}
+	if opts.Protocol == "" {
+		opts.Protocol = model.TraceICMP
+	}
+
+	host, port := traceTarget(monitor)
+	trace := &model.TraceResult{Target: host, Protocol: opts.Protocol, Hops: []model.Hop{}}
+
+	if opts.Protocol == model.TraceTCP && opts.Port == 0 {
+		opts.Port = port
+	}
+	hops, reached, err := traceroute(ctx, host, opts)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.Hops = hops
+	trace.Reached = reached
+	return trace
+}
+
+// traceTarget extracts the host to trace and the service port from any
+// monitor; HTTP monitors carry URLs rather than hosts.
+func traceTarget(monitor *model.Monitor) (string, int) {
+	port := monitor.Port
+	switch monitor.CheckType {
+	case model.CheckHTTP, model.CheckHTTPS, model.CheckHTTPKeyword:
+		scheme := "http"
+		if monitor.CheckType == model.CheckHTTPS {
+			scheme = "https"
+		}
+		if monitor.CheckType == model.CheckHTTPKeyword {
+			scheme = targetScheme(monitor.Target, "http")
+		}
+		if u, err := TargetURL(monitor.Target, scheme, monitor.Port); err == nil {
+			port, _ = strconv.Atoi(u.Port())
+			if port == 0 {
+				port = 80
+				if u.Scheme == "https" {
+					port = 443
+				}
+			}
+			return u.Hostname(), port
+		}
+	}
+	if port == 0 {
+		port = 80
+	}
+	return strings.Trim(monitor.Target, "[]"), port
+}
+
+// traceProbe is one packet sent with a given TTL.
+type traceProbe struct {
+	ttl     int
+	round   int
+	sentAt  time.Time
+	from    string
+	rtt     time.Duration
+	done    bool
+	reached bool
+}
+
+// tracer collects replies for the probes of one traceroute.
+type tracer struct {
+	dest     net.IP
+	v6       bool
+	protocol string
+	port     int // udp base port or tcp destination port
+	echoID   int
+	udpPort  int // local port of the udp probe socket
+
+	mu          sync.Mutex
+	probes      map[int]*traceProbe
+	pending     int // tcp probes being set up, not yet registered
+	destTTL     int // lowest TTL the destination answered at
+	unreachable int // lowest TTL a router reported the destination unreachable
+	notify      chan struct{}
+}
+
+func (t *tracer) register(key, ttl, round int) {
+	t.mu.Lock()
+	t.probes[key] = &traceProbe{ttl: ttl, round: round, sentAt: time.Now()}
+	t.mu.Unlock()
+}
+
+// addPending and donePending bracket the setup of a tcp probe, whose key
+// is only known once its socket is bound.
+func (t *tracer) addPending() {
+	t.mu.Lock()
+	t.pending++
+	t.mu.Unlock()
+}
+
+func (t *tracer) donePending() {
+	t.mu.Lock()
+	t.pending--
+	t.mu.Unlock()
+}
+
+// record stores a reply for the probe identified by key.
+func (t *tracer) record(key int, from net.IP, reached, unreachable bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.probes[key]
+	if !ok || p.done {
+		return
+	}
+	p.done = true
+	p.rtt = time.Since(p.sentAt)
+	p.from = from.String()
+	p.reached = reached
+	if reached && (t.destTTL == 0 || p.ttl < t.destTTL) {
+		t.destTTL = p.ttl
+	}
+	if unreachable && (t.unreachable == 0 || p.ttl < t.unreachable) {
+		t.unreachable = p.ttl
+	}
+
+	select {
+	case t.notify <- struct{}{}:
+	default:
+	}
+}
+
+// roundDone reports whether every probe of a round that can still be
+// answered has been.
+func (t *tracer) roundDone(round int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending > 0 {
+		return false
+	}
+	for _, p := range t.probes {
+		if p.round != round || p.done {
+			continue
+		}
+		if t.destTTL > 0 && p.ttl > t.destTTL {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func (t *tracer) lastTTL(maxHops int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.destTTL > 0 {
+		return t.destTTL
+	}
+	return maxHops
+}
+
+// traceLimits applies the defaults for hop count, probes per hop and the
+// per-round probe timeout.
+func traceLimits(opts model.TracerouteOptions) (int, int, time.Duration) {
+	maxHops := opts.MaxHops
+	if maxHops <= 0 {
+		maxHops = defaultMaxHops
+	}
+	probes := opts.Probes
+	if probes <= 0 {
+		probes = defaultTraceProbes
+	}
+	probeTimeout := time.Duration(opts.ProbeTimeoutMS) * time.Millisecond
+	if probeTimeout <= 0 {
+		probeTimeout = defaultProbeTimeoutMS * time.Millisecond
+	}
+	return maxHops, probes, probeTimeout
+}
+
+// RawSocketsAvailable reports whether this process can open the raw ICMP
+// sockets tracing needs. The answer is probed once and cached.
+var RawSocketsAvailable = sync.OnceValue(func() bool {
+	for _, nw := range [][2]string{{"ip4:icmp", "0.0.0.0"}, {"ip6:ipv6-icmp", "::"}} {
+		if conn, err := icmp.ListenPacket(nw[0], nw[1]); err == nil {
+			conn.Close()
+			return true
+		}
+	}
+	return false
+})
+
+// TraceDuration is an upper bound on how long TraceMonitor takes for a
+// monitor: one probe timeout per round plus time for name resolution.
+func TraceDuration(monitor *model.Monitor) time.Duration {
+	opts := model.TracerouteOptions{}
+	if monitor.Options != nil && monitor.Options.Traceroute != nil {
+		opts = *monitor.Options.Traceroute
+	}
+	_, probes, probeTimeout := traceLimits(opts)
+	return time.Duration(probes)*probeTimeout + 5*time.Second
+}
+
+// traceroute runs probes rounds of one packet per TTL and aggregates the
+// replies per hop.
+func traceroute(ctx context.Context, host string, opts model.TracerouteOptions) ([]model.Hop, bool, error) {
+	maxHops, probes, probeTimeout := traceLimits(opts)
+
+	ips, err := lookupIP(ctx, host)
+	if err != nil || len(ips) == 0 {
+		return nil, false, fmt.Errorf("resolving %s: %v", host, err)
+	}
+	dest := ips[0]
+	dialInfoFrom(ctx).record(dest)
+	v6 := dest.To4() == nil
+
+	t := &tracer{
+		dest:     dest,
+		v6:       v6,
+		protocol: opts.Protocol,
+		port:     opts.Port,
+		echoID:   rand.IntN(0xffff),
+		probes:   map[int]*traceProbe{},
+		notify:   make(chan struct{}, 1),
+	}
+
+	network, address, proto := "ip4:icmp", "0.0.0.0", 1
+	if v6 {
+		network, address, proto = "ip6:ipv6-icmp", "::", 58
+	}
+	icmpConn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, false, fmt.Errorf("opening raw ICMP socket (traceroute needs root or CAP_NET_RAW): %v", err)
+	}
+	defer icmpConn.Close()
+
+	traceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var send func(ttl, round, seq int) error
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	switch opts.Protocol {
+	case model.TraceICMP:
+		send = func(ttl, round, seq int) error {
+			return t.sendEcho(icmpConn, ttl, round, seq)
+		}
+	case model.TraceUDP:
+		if t.port == 0 {
+			t.port = defaultUDPTracePort
+		}
+		udpNetwork := "udp4"
+		if v6 {
+			udpNetwork = "udp6"
+		}
+		udpConn, err := net.ListenPacket(udpNetwork, ":0")
+		if err != nil {
+			return nil, false, fmt.Errorf("opening udp socket: %v", err)
+		}
+		defer udpConn.Close()
+		t.udpPort = udpConn.LocalAddr().(*net.UDPAddr).Port
+		send = func(ttl, round, seq int) error {
+			return t.sendUDP(udpConn, ttl, round, seq)
+		}
+	case model.TraceTCP:
+		send = func(ttl, round, seq int) error {
+			t.addPending()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.sendSYN(traceCtx, ttl, round, probeTimeout)
+			}()
+			return nil
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown traceroute protocol %q", opts.Protocol)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t.receive(traceCtx, icmpConn, proto)
+	}()
+
+	for round := 0; round < probes && traceCtx.Err() == nil; round++ {
+		last := t.lastTTL(maxHops)
+		for ttl := 1; ttl <= last; ttl++ {
+			if err := send(ttl, round, round*maxHops+ttl); err != nil {
+				cancel()
+				return nil, false, fmt.Errorf("sending probe: %v", err)
+			}
+		}
+		t.waitRound(traceCtx, round, probeTimeout)
+	}
+	cancel()
+	wg.Wait()
+
+	hops, reached := t.hops(maxHops)
+	return hops, reached, nil
+}
+
+func (t *tracer) waitRound(ctx context.Context, round int, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for !t.roundDone(round) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			return
+		case <-t.notify:
+		}
+	}
+}
+
+func (t *tracer) sendEcho(conn *icmp.PacketConn, ttl, round, seq int) error {
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: t.echoID, Seq: seq, Data: []byte("pingmesh-trace")},
+	}
+	if t.v6 {
+		msg.Type = ipv6.ICMPTypeEchoRequest
+		if err := conn.IPv6PacketConn().SetHopLimit(ttl); err != nil {
+			return err
+		}
+	} else if err := conn.IPv4PacketConn().SetTTL(ttl); err != nil {
+		return err
+	}
+
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	t.register(seq, ttl, round)
+	_, err = conn.WriteTo(b, &net.IPAddr{IP: t.dest})
+	return err
+}
+
+func (t *tracer) sendUDP(conn net.PacketConn, ttl, round, seq int) error {
+	if t.v6 {
+		if err := ipv6.NewPacketConn(conn).SetHopLimit(ttl); err != nil {
+			return err
+		}
+	} else if err := ipv4.NewPacketConn(conn).SetTTL(ttl); err != nil {
+		return err
+	}
+	t.register(seq, ttl, round)
+	_, err := conn.WriteTo([]byte("pingmesh-trace"), &net.UDPAddr{IP: t.dest, Port: t.port + seq})
+	return err
+}
+
+// sendSYN opens a TCP connection with a limited TTL. Routers on the way
+// answer with ICMP; the destination completes or refuses the handshake.
+func (t *tracer) sendSYN(ctx context.Context, ttl, round int, timeout time.Duration) {
+	localPort := 0
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: tcpProbeControl(t.v6, ttl, func(port int) {
+			localPort = port
+			t.register(port, ttl, round)
+			t.donePending()
+		}),
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(t.dest.String(), strconv.Itoa(t.port)))
+	if conn != nil {
+		conn.Close()
+	}
+	if localPort == 0 {
+		// The socket was never bound, so no reply can match it.
+		t.donePending()
+		return
+	}
+	if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+		t.record(localPort, t.dest, true, false)
+	}
+}
+
+// receive reads ICMP replies until ctx is cancelled.
+func (t *tracer) receive(ctx context.Context, conn *icmp.PacketConn, proto int) {
+	buf := make([]byte, 1500)
+	for ctx.Err() == nil {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			continue
+		}
+		from := peer.(*net.IPAddr).IP
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case ipv4.ICMPTypeEchoReply, ipv6.ICMPTypeEchoReply:
+			echo, ok := msg.Body.(*icmp.Echo)
+			if ok && t.protocol == model.TraceICMP && echo.ID == t.echoID && from.Equal(t.dest) {
+				t.record(echo.Seq, from, true, false)
+			}
+		case ipv4.ICMPTypeTimeExceeded, ipv6.ICMPTypeTimeExceeded:
+			if body, ok := msg.Body.(*icmp.TimeExceeded); ok {
+				if key, ok := t.matchQuoted(body.Data); ok {
+					t.record(key, from, false, false)
+				}
+			}
+		case ipv4.ICMPTypeDestinationUnreachable, ipv6.ICMPTypeDestinationUnreachable:
+			if body, ok := msg.Body.(*icmp.DstUnreach); ok {
+				if key, ok := t.matchQuoted(body.Data); ok {
+					// Port unreachable from the destination ends a UDP trace.
+					reached := from.Equal(t.dest)
+					t.record(key, from, reached, !reached)
+				}
+			}
+		}
+	}
+}
+
+// matchQuoted finds the probe quoted in an ICMP error: the original IP
+// header followed by at least 8 bytes of the transport header.
+func (t *tracer) matchQuoted(data []byte) (int, bool) {
+	var proto int
+	var dst net.IP
+	var payload []byte
+	if t.v6 {
+		if len(data) < 48 {
+			return 0, false
+		}
+		proto, dst, payload = int(data[6]), net.IP(data[24:40]), data[40:]
+	} else {
+		if len(data) < 20 {
+			return 0, false
+		}
+		ihl := int(data[0]&0x0f) * 4
+		if len(data) < ihl+8 {
+			return 0, false
+		}
+		proto, dst, payload = int(data[9]), net.IP(data[16:20]), data[ihl:]
+	}
+	if !dst.Equal(t.dest) {
+		return 0, false
+	}
+
+	src := int(payload[0])<<8 | int(payload[1])
+	dstPort := int(payload[2])<<8 | int(payload[3])
+
+	switch t.protocol {
+	case model.TraceICMP:
+		if proto != 1 && proto != 58 {
+			return 0, false
+		}
+		id := int(payload[4])<<8 | int(payload[5])
+		seq := int(payload[6])<<8 | int(payload[7])
+		return seq, id == t.echoID
+	case model.TraceUDP:
+		if proto != syscall.IPPROTO_UDP || src != t.udpPort {
+			return 0, false
+		}
+		return dstPort - t.port, true
+	case model.TraceTCP:
+		if proto != syscall.IPPROTO_TCP || dstPort != t.port {
+			return 0, false
+		}
+		return src, true
+	}
+	return 0, false
+}
+
+// hops aggregates probes per TTL. Trailing silent hops are cut to one so
+// the result shows where the path went dark.
+func (t *tracer) hops(maxHops int) ([]model.Hop, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byTTL := map[int][]*traceProbe{}
+	lastResponding := 0
+	for _, p := range t.probes {
+		byTTL[p.ttl] = append(byTTL[p.ttl], p)
+		if p.done && p.ttl > lastResponding {
+			lastResponding = p.ttl
+		}
+	}
+
+	last := min(lastResponding+1, maxHops)
+	if t.destTTL > 0 {
+		last = t.destTTL
+	} else if t.unreachable > 0 {
+		last = t.unreachable
+	}
+
+	hops := make([]model.Hop, 0, last)
+	for ttl := 1; ttl <= last; ttl++ {
+		hop := model.Hop{TTL: ttl, Sent: len(byTTL[ttl])}
+		counts := map[string]int{}
+		var total time.Duration
+		for _, p := range byTTL[ttl] {
+			if !p.done {
+				continue
+			}
+			hop.Received++
+			counts[p.from]++
+			total += p.rtt
+			ms := durationMS(p.rtt)
+			if hop.MinMS == 0 || ms < hop.MinMS {
+				hop.MinMS = ms
+			}
+			hop.MaxMS = max(hop.MaxMS, ms)
+		}
+		if hop.Received > 0 {
+			hop.AvgMS = durationMS(total / time.Duration(hop.Received))
+			for addr := range counts {
+				hop.Addresses = append(hop.Addresses, addr)
+			}
+			// Ties, as when ECMP splits probes evenly, go to the lowest
+			// address so the same path reports the same hop every time.
+			slices.Sort(hop.Addresses)
+			for _, addr := range hop.Addresses {
+				if counts[addr] > counts[hop.Address] {
+					hop.Address = addr
+				}
+			}
+			if len(hop.Addresses) == 1 {
+				hop.Addresses = nil
+			}
+		}
+		if hop.Sent > 0 {
+			hop.LossPercent = float64(hop.Sent-hop.Received) / float64(hop.Sent) * 100
+		}
+		hops = append(hops, hop)
+	}
+	return hops, t.destTTL > 0
+}
+
+// ValidateTracerouteOptions checks protocol and limits. For traceroute
+// checks timeoutMS is the monitor timeout: every round waits up to a probe
+// timeout, with all hops of a round probed at once, so the rounds must end
+// before it. Zero skips that check, as path capture on other check types
+// is not bound by the monitor timeout.
+func ValidateTracerouteOptions(opts *model.TracerouteOptions, timeoutMS int64) error {
+	switch opts.Protocol {
+	case "", model.TraceICMP, model.TraceUDP, model.TraceTCP:
+	default:
+		return fmt.Errorf("unknown protocol %q", opts.Protocol)
+	}
+	if opts.MaxHops < 0 || opts.MaxHops > 64 {
+		return fmt.Errorf("max_hops must be between 1 and 64")
+	}
+	if opts.Probes < 0 || opts.Probes > 10 {
+		return fmt.Errorf("probes must be between 1 and 10")
+	}
+	if opts.ProbeTimeoutMS < 0 {
+		return fmt.Errorf("probe_timeout_ms must not be negative")
+	}
+	if opts.Port < 0 || opts.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if timeoutMS > 0 {
+		_, probes, probeTimeout := traceLimits(*opts)
+		if needed := int64(probes) * probeTimeout.Milliseconds(); needed >= timeoutMS {
+			return fmt.Errorf("%d probes at probe_timeout_ms %d take up to %dms, which must be below the monitor timeout of %dms",
+				probes, probeTimeout.Milliseconds(), needed, timeoutMS)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package checker
+
+import (
+	"errors"
+	"syscall"
+)
+
+// tcpProbeControl is not available on this platform; TCP traceroutes fail
+// with an error rather than sending probes without a TTL.
+func tcpProbeControl(v6 bool, ttl int, bound func(port int)) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		return errors.New("tcp traceroute is not supported on this platform")
+	}
+}
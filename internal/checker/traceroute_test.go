@@ -0,0 +1,261 @@
+package checker
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// quotedIPv4 builds the IPv4 header and first transport bytes a router
+// quotes in an ICMP error.
+func quotedIPv4(proto byte, dst string, transport ...byte) []byte {
+	header := make([]byte, 20)
+	header[0] = 0x45
+	header[9] = proto
+	copy(header[16:20], net.ParseIP(dst).To4())
+	return append(header, transport...)
+}
+
+// quotedIPv6 is quotedIPv4 for IPv6 headers.
+func quotedIPv6(proto byte, dst string, transport ...byte) []byte {
+	header := make([]byte, 40)
+	header[0] = 0x60
+	header[6] = proto
+	copy(header[24:40], net.ParseIP(dst).To16())
+	return append(header, transport...)
+}
+
+func TestTracerMatchQuoted(t *testing.T) {
+	v4 := net.ParseIP("192.0.2.10")
+	v6 := net.ParseIP("2001:db8::10")
+	// Transport headers: source port, destination port, then id/seq for
+	// ICMP echoes or length/checksum and sequence numbers otherwise.
+	echo := []byte{8, 0, 0, 0, 0x12, 0x34, 0x00, 0x07}
+	udp := []byte{0x9c, 0x40, 0x82, 0x9f, 0, 0, 0, 0} // 40000 -> 33439
+	tcp := []byte{0xa4, 0x10, 0x01, 0xbb, 0, 0, 0, 0} // 42000 -> 443
+
+	tests := []struct {
+		name    string
+		tracer  *tracer
+		data    []byte
+		wantKey int
+		wantOK  bool
+	}{
+		{"icmp echo", &tracer{dest: v4, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv4(1, "192.0.2.10", echo...), 7, true},
+		{"icmp echo of another tracer", &tracer{dest: v4, protocol: model.TraceICMP, echoID: 0x4321},
+			quotedIPv4(1, "192.0.2.10", echo...), 7, false},
+		{"icmp to another destination", &tracer{dest: v4, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv4(1, "192.0.2.99", echo...), 0, false},
+		{"icmp quoting udp", &tracer{dest: v4, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv4(syscall.IPPROTO_UDP, "192.0.2.10", echo...), 0, false},
+		{"icmpv6 echo", &tracer{dest: v6, v6: true, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv6(58, "2001:db8::10", echo...), 7, true},
+		{"udp port offset", &tracer{dest: v4, protocol: model.TraceUDP, port: 33434, udpPort: 40000},
+			quotedIPv4(syscall.IPPROTO_UDP, "192.0.2.10", udp...), 5, true},
+		{"udp from another socket", &tracer{dest: v4, protocol: model.TraceUDP, port: 33434, udpPort: 40001},
+			quotedIPv4(syscall.IPPROTO_UDP, "192.0.2.10", udp...), 0, false},
+		{"udp over ipv6", &tracer{dest: v6, v6: true, protocol: model.TraceUDP, port: 33434, udpPort: 40000},
+			quotedIPv6(syscall.IPPROTO_UDP, "2001:db8::10", udp...), 5, true},
+		{"tcp source port", &tracer{dest: v4, protocol: model.TraceTCP, port: 443},
+			quotedIPv4(syscall.IPPROTO_TCP, "192.0.2.10", tcp...), 42000, true},
+		{"tcp to another port", &tracer{dest: v4, protocol: model.TraceTCP, port: 80},
+			quotedIPv4(syscall.IPPROTO_TCP, "192.0.2.10", tcp...), 0, false},
+		{"ipv4 options", &tracer{dest: v4, protocol: model.TraceTCP, port: 443},
+			func() []byte {
+				data := quotedIPv4(syscall.IPPROTO_TCP, "192.0.2.10")
+				data[0] = 0x46 // four bytes of options before the transport header
+				return append(data, append([]byte{1, 1, 1, 1}, tcp...)...)
+			}(), 42000, true},
+		{"truncated ipv4", &tracer{dest: v4, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv4(1, "192.0.2.10", echo[:4]...), 0, false},
+		{"truncated ipv6", &tracer{dest: v6, v6: true, protocol: model.TraceICMP, echoID: 0x1234},
+			quotedIPv6(58, "2001:db8::10", echo[:4]...), 0, false},
+	}
+	for _, tt := range tests {
+		key, ok := tt.tracer.matchQuoted(tt.data)
+		if ok != tt.wantOK || (ok && key != tt.wantKey) {
+			t.Errorf("%s: matchQuoted() = %d, %v, want %d, %v", tt.name, key, ok, tt.wantKey, tt.wantOK)
+		}
+	}
+}
+
+// traceReply is a probe outcome for TestTracerHops: an empty from is a
+// probe that timed out.
+type traceReply struct {
+	ttl     int
+	from    string
+	rttMS   int
+	reached bool
+}
+
+func TestTracerHops(t *testing.T) {
+	tests := []struct {
+		name        string
+		replies     []traceReply
+		destTTL     int
+		unreachable int
+		maxHops     int
+		want        []model.Hop
+		wantReached bool
+	}{
+		{
+			name: "reached",
+			replies: []traceReply{
+				{1, "10.0.0.1", 1, false}, {1, "10.0.0.1", 3, false},
+				{2, "192.0.2.10", 10, true}, {2, "", 0, false},
+				// Probes past the destination are not hops.
+				{3, "192.0.2.10", 10, true},
+			},
+			destTTL: 2, maxHops: 30,
+			want: []model.Hop{
+				{TTL: 1, Address: "10.0.0.1", Sent: 2, Received: 2, MinMS: 1, AvgMS: 2, MaxMS: 3},
+				{TTL: 2, Address: "192.0.2.10", Sent: 2, Received: 1, LossPercent: 50, MinMS: 10, AvgMS: 10, MaxMS: 10},
+			},
+			wantReached: true,
+		},
+		{
+			name: "ecmp tie goes to the lowest address",
+			replies: []traceReply{
+				{1, "10.0.0.2", 2, false}, {1, "10.0.0.1", 2, false},
+				{1, "10.0.0.3", 2, false}, {1, "10.0.0.3", 2, false},
+				{2, "10.1.0.9", 4, false}, {2, "10.1.0.8", 4, false},
+			},
+			maxHops: 2,
+			want: []model.Hop{
+				{TTL: 1, Address: "10.0.0.3", Addresses: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, Sent: 4, Received: 4, MinMS: 2, AvgMS: 2, MaxMS: 2},
+				{TTL: 2, Address: "10.1.0.8", Addresses: []string{"10.1.0.8", "10.1.0.9"}, Sent: 2, Received: 2, MinMS: 4, AvgMS: 4, MaxMS: 4},
+			},
+		},
+		{
+			name: "silent tail cut to one hop",
+			replies: []traceReply{
+				{1, "10.0.0.1", 1, false},
+				{2, "", 0, false}, {3, "", 0, false}, {4, "", 0, false},
+			},
+			maxHops: 30,
+			want: []model.Hop{
+				{TTL: 1, Address: "10.0.0.1", Sent: 1, Received: 1, MinMS: 1, AvgMS: 1, MaxMS: 1},
+				{TTL: 2, Sent: 1, LossPercent: 100},
+			},
+		},
+		{
+			name: "unreachable",
+			replies: []traceReply{
+				{1, "10.0.0.1", 1, false}, {2, "10.0.0.2", 2, false}, {3, "10.0.0.3", 3, false},
+			},
+			unreachable: 2, maxHops: 30,
+			want: []model.Hop{
+				{TTL: 1, Address: "10.0.0.1", Sent: 1, Received: 1, MinMS: 1, AvgMS: 1, MaxMS: 1},
+				{TTL: 2, Address: "10.0.0.2", Sent: 1, Received: 1, MinMS: 2, AvgMS: 2, MaxMS: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tr := &tracer{probes: map[int]*traceProbe{}, destTTL: tt.destTTL, unreachable: tt.unreachable}
+		for i, r := range tt.replies {
+			p := &traceProbe{ttl: r.ttl}
+			if r.from != "" {
+				p.done, p.from, p.reached = true, r.from, r.reached
+				p.rtt = time.Duration(r.rttMS) * time.Millisecond
+			}
+			tr.probes[i] = p
+		}
+		// Map order varies, so aggregate several times.
+		for range 5 {
+			hops, reached := tr.hops(tt.maxHops)
+			if !reflect.DeepEqual(hops, tt.want) || reached != tt.wantReached {
+				t.Errorf("%s: hops() = %+v, %v, want %+v, %v", tt.name, hops, reached, tt.want, tt.wantReached)
+				break
+			}
+		}
+	}
+}
+
+func TestTracerRecord(t *testing.T) {
+	tr := &tracer{probes: map[int]*traceProbe{}, notify: make(chan struct{}, 1)}
+	for ttl := 1; ttl <= 4; ttl++ {
+		tr.register(ttl, ttl, 0)
+	}
+	tr.record(4, net.ParseIP("192.0.2.10"), true, false)
+	tr.record(3, net.ParseIP("192.0.2.10"), true, false)
+	tr.record(3, net.ParseIP("10.9.9.9"), false, false) // duplicate reply
+	tr.record(9, net.ParseIP("10.9.9.9"), false, false) // unknown probe
+
+	if tr.destTTL != 3 {
+		t.Errorf("destTTL = %d, want the lowest TTL that reached the destination", tr.destTTL)
+	}
+	if got := tr.probes[3].from; got != "192.0.2.10" {
+		t.Errorf("probe 3 from %s, want the first reply kept", got)
+	}
+	if tr.roundDone(0) {
+		t.Error("roundDone() = true with probes below the destination unanswered")
+	}
+	tr.record(1, net.ParseIP("10.0.0.1"), false, false)
+	tr.record(2, net.ParseIP("10.0.0.2"), false, false)
+	if !tr.roundDone(0) {
+		t.Error("roundDone() = false with every probe up to the destination answered")
+	}
+}
+
+func TestTraceTarget(t *testing.T) {
+	tests := []struct {
+		monitor  model.Monitor
+		wantHost string
+		wantPort int
+	}{
+		{model.Monitor{CheckType: model.CheckHTTPS, Target: "https://api.example.com/health"}, "api.example.com", 443},
+		{model.Monitor{CheckType: model.CheckHTTP, Target: "example.com"}, "example.com", 80},
+		{model.Monitor{CheckType: model.CheckHTTP, Target: "http://example.com:8080/"}, "example.com", 8080},
+		{model.Monitor{CheckType: model.CheckHTTPKeyword, Target: "https://[2001:db8::1]/"}, "2001:db8::1", 443},
+		{model.Monitor{CheckType: model.CheckTCP, Target: "db.internal", Port: 5432}, "db.internal", 5432},
+		{model.Monitor{CheckType: model.CheckTraceroute, Target: "[2001:db8::1]"}, "2001:db8::1", 80},
+		{model.Monitor{CheckType: model.CheckICMP, Target: "192.0.2.1"}, "192.0.2.1", 80},
+	}
+	for _, tt := range tests {
+		host, port := traceTarget(&tt.monitor)
+		if host != tt.wantHost || port != tt.wantPort {
+			t.Errorf("traceTarget(%s %q) = %s, %d, want %s, %d", tt.monitor.CheckType, tt.monitor.Target, host, port, tt.wantHost, tt.wantPort)
+		}
+	}
+}
+
+func TestValidateTracerouteOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      model.TracerouteOptions
+		timeoutMS int64
+		wantErr   string
+	}{
+		{"defaults", model.TracerouteOptions{}, 5000, ""},
+		{"tcp", model.TracerouteOptions{Protocol: model.TraceTCP, Port: 443, MaxHops: 64, Probes: 10, ProbeTimeoutMS: 400}, 5000, ""},
+		{"unknown protocol", model.TracerouteOptions{Protocol: "sctp"}, 5000, "unknown protocol"},
+		{"too many hops", model.TracerouteOptions{MaxHops: 65}, 5000, "max_hops"},
+		{"too many probes", model.TracerouteOptions{Probes: 11}, 5000, "probes"},
+		{"negative probe timeout", model.TracerouteOptions{ProbeTimeoutMS: -1}, 5000, "probe_timeout_ms"},
+		{"bad port", model.TracerouteOptions{Port: 70000}, 5000, "port"},
+		{"rounds outlast the timeout", model.TracerouteOptions{Probes: 5, ProbeTimeoutMS: 1000}, 5000,
+			"5 probes at probe_timeout_ms 1000 take up to 5000ms, which must be below the monitor timeout of 5000ms"},
+		{"defaults outlast a short timeout", model.TracerouteOptions{}, 2000, "3 probes at probe_timeout_ms 1000"},
+		// Hops of a round are probed at once, so they don't add up.
+		{"many hops", model.TracerouteOptions{MaxHops: 64, Probes: 2, ProbeTimeoutMS: 2000}, 5000, ""},
+		{"path capture only", model.TracerouteOptions{Probes: 10, ProbeTimeoutMS: 5000}, 0, ""},
+	}
+	for _, tt := range tests {
+		err := ValidateTracerouteOptions(&tt.opts, tt.timeoutMS)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: ValidateTracerouteOptions() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: ValidateTracerouteOptions() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
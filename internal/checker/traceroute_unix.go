@@ -0,0 +1,52 @@
+//go:build unix
+
+package checker
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// tcpProbeControl limits the TTL of a probe socket and binds it before the
+// connect, so the local port that identifies the probe is known up front.
+func tcpProbeControl(v6 bool, ttl int, bound func(port int)) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var opErr error
+		err := c.Control(func(fd uintptr) {
+			s := int(fd)
+			if v6 {
+				opErr = syscall.SetsockoptInt(s, syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+			} else {
+				opErr = syscall.SetsockoptInt(s, syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+			}
+			if opErr != nil {
+				return
+			}
+
+			var sa syscall.Sockaddr = &syscall.SockaddrInet4{}
+			if v6 {
+				sa = &syscall.SockaddrInet6{}
+			}
+			if opErr = syscall.Bind(s, sa); opErr != nil {
+				return
+			}
+			local, err := syscall.Getsockname(s)
+			if err != nil {
+				opErr = err
+				return
+			}
+			switch a := local.(type) {
+			case *syscall.SockaddrInet4:
+				bound(a.Port)
+			case *syscall.SockaddrInet6:
+				bound(a.Port)
+			default:
+				opErr = fmt.Errorf("unexpected socket address %T", local)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return opErr
+	}
+}
@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// udpResendInterval is how long a UDP request waits for a reply before it
+// is sent again.
+const udpResendInterval = time.Second
+
+// errNoReply reports that no acceptable reply arrived before the timeout.
+var errNoReply = errors.New("no reply")
+
+// UDPChecker sends a datagram and checks the reply.
+type UDPChecker struct{}
+
+func (c *UDPChecker) Type() model.CheckType {
+	return model.CheckUDP
+}
+
+func (c *UDPChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := udpOptions(monitor)
+	matches, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match})
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	send := func() []byte { return []byte(opts.Send) }
+	reply, rtt, err := udpExchange(ctx, monitor, 0, send, func([]byte) bool { return true })
+	latency := durationMS(rtt)
+	if err != nil {
+		return &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     fmt.Sprintf("udp: %v", err),
+		}, nil
+	}
+
+	result := &Result{
+		Status:    model.StatusUp,
+		LatencyMS: latency,
+		Details: map[string]any{
+			"reply":       printableReply(reply),
+			"reply_bytes": len(reply),
+		},
+	}
+	if !matches(reply) {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("expected %q, got %s", opts.Expect, printableReply(reply))
+	}
+	return result, nil
+}
+
+// udpOptions returns the monitor's UDP options, or defaults.
+func udpOptions(monitor *model.Monitor) *model.UDPOptions {
+	if monitor.Options != nil && monitor.Options.UDP != nil {
+		return monitor.Options.UDP
+	}
+	return &model.UDPOptions{}
+}
+
+// udpExchange sends a request to the monitor's target and returns the first
+// reply accept approves, with the time since the request was sent. An
+// unanswered request is rebuilt with request and resent every second until
+// ctx ends; replies accept rejects, such as answers to an earlier send, are
+// skipped. A closed port usually fails fast with "connection refused".
+func udpExchange(ctx context.Context, monitor *model.Monitor, defaultPort int, request func() []byte, accept func([]byte) bool) ([]byte, time.Duration, error) {
+	port := monitor.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	address := net.JoinHostPort(strings.Trim(monitor.Target, "[]"), strconv.Itoa(port))
+
+	conn, err := dialContext(ctx, &net.Dialer{}, "udp", address)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	// Unblock reads when the check is cancelled.
+	stop := context.AfterFunc(ctx, func() { conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	deadline, hasDeadline := ctx.Deadline()
+	buf := make([]byte, 64*1024)
+	for ctx.Err() == nil && (!hasDeadline || time.Now().Before(deadline)) {
+		sent := time.Now()
+		if _, err := conn.Write(request()); err != nil {
+			return nil, 0, err
+		}
+
+		wait := sent.Add(udpResendInterval)
+		if hasDeadline && deadline.Before(wait) {
+			wait = deadline
+		}
+		conn.SetReadDeadline(wait)
+		for {
+			n, err := conn.Read(buf)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, time.Since(sent), err
+			}
+			if accept(buf[:n]) {
+				return append([]byte(nil), buf[:n]...), time.Since(sent), nil
+			}
+		}
+	}
+	return nil, 0, errNoReply
+}
+
+// printableReply renders a reply as text when it is valid UTF-8 and as hex
+// otherwise, truncated for result details.
+func printableReply(b []byte) string {
+	if utf8.Valid(b) {
+		return truncateActual(strings.TrimSpace(string(b)))
+	}
+	return truncateActual(hex.EncodeToString(b))
+}
+
+// ValidateUDPOptions checks the settings shared by the udp, ntp, snmp and
+// radius checkers.
+func ValidateUDPOptions(checkType model.CheckType, opts *model.UDPOptions) error {
+	if _, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match}); err != nil {
+		return err
+	}
+	if err := validateCredentials(opts.Credentials); err != nil {
+		return err
+	}
+	if opts.WarnOffsetMS < 0 || opts.MaxOffsetMS < 0 {
+		return fmt.Errorf("offset thresholds must not be negative")
+	}
+	if opts.WarnOffsetMS > 0 && opts.MaxOffsetMS > 0 && opts.WarnOffsetMS >= opts.MaxOffsetMS {
+		return fmt.Errorf("warn_offset_ms must be below max_offset_ms")
+	}
+	if opts.MaxStratum < 0 || opts.MaxStratum > 15 {
+		return fmt.Errorf("max_stratum must be between 1 and 15")
+	}
+	if opts.OID != "" {
+		if _, err := parseOID(opts.OID); err != nil {
+			return err
+		}
+	}
+	if checkType == model.CheckRADIUS && opts.Password == "" && opts.PasswordEnv == "" && opts.PasswordFile == "" {
+		return fmt.Errorf("radius checks need the shared secret as password, password_env or password_file")
+	}
+	return nil
+}
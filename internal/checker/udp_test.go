@@ -0,0 +1,161 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// serveUDP answers datagrams on a local port with the reply returned by
+// handle, staying silent when it returns nil, and returns the port.
+func serveUDP(t *testing.T, handle func(req []byte) []byte) int {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := handle(append([]byte(nil), buf[:n]...)); reply != nil {
+				pc.WriteTo(reply, addr)
+			}
+		}
+	}()
+	return pc.LocalAddr().(*net.UDPAddr).Port
+}
+
+// checkUDP runs a checker against a local port with a one-second timeout.
+func checkUDP(t *testing.T, c Checker, port int, opts *model.UDPOptions) *Result {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m := &model.Monitor{Target: "127.0.0.1", Port: port, Options: &model.MonitorOptions{UDP: opts}}
+	result, err := c.Check(ctx, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestUDPCheck(t *testing.T) {
+	port := serveUDP(t, func(req []byte) []byte {
+		switch string(req) {
+		case "PING":
+			return []byte("PONG v1.4\n")
+		case "BIN":
+			return []byte{0xde, 0xad, 0xbe, 0xef}
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name      string
+		opts      *model.UDPOptions
+		want      model.CheckStatus
+		wantReply string
+	}{
+		{"any reply", &model.UDPOptions{Send: "PING"}, model.StatusUp, "PONG v1.4"},
+		{"expected reply", &model.UDPOptions{Send: "PING", Expect: "PONG"}, model.StatusUp, "PONG v1.4"},
+		{"regex", &model.UDPOptions{Send: "PING", Expect: `v1\.\d+`, Match: "regex"}, model.StatusUp, "PONG v1.4"},
+		{"unexpected reply", &model.UDPOptions{Send: "PING", Expect: "v2"}, model.StatusDown, "PONG v1.4"},
+		{"binary reply as hex", &model.UDPOptions{Send: "BIN"}, model.StatusUp, "deadbeef"},
+		{"no reply", &model.UDPOptions{Send: "HELLO"}, model.StatusDown, ""},
+		{"bad regex", &model.UDPOptions{Send: "PING", Expect: "(", Match: "regex"}, model.StatusDown, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkUDP(t, &UDPChecker{}, port, tt.opts)
+			if result.Status != tt.want {
+				t.Errorf("status = %s (%s), want %s", result.Status, result.Error, tt.want)
+			}
+			if got, _ := result.Details["reply"].(string); got != tt.wantReply {
+				t.Errorf("reply = %q, want %q", got, tt.wantReply)
+			}
+		})
+	}
+}
+
+func TestUDPExchangeResends(t *testing.T) {
+	// The first datagram is lost; the resend a second later is answered.
+	var seen int
+	port := serveUDP(t, func(req []byte) []byte {
+		if seen++; seen == 1 {
+			return nil
+		}
+		return []byte("ok")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	m := &model.Monitor{Target: "127.0.0.1", Port: port}
+	reply, rtt, err := udpExchange(ctx, m, 0, func() []byte { return []byte("x") }, func([]byte) bool { return true })
+	if err != nil || string(reply) != "ok" {
+		t.Fatalf("udpExchange() = %q, %v, want the reply to the resend", reply, err)
+	}
+	if rtt >= udpResendInterval {
+		t.Errorf("rtt = %s, want it measured from the resend", rtt)
+	}
+}
+
+func TestUDPExchangeSkipsRejectedReplies(t *testing.T) {
+	port := serveUDP(t, func(req []byte) []byte { return []byte("stale") })
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	m := &model.Monitor{Target: "127.0.0.1", Port: port}
+	_, _, err := udpExchange(ctx, m, 0, func() []byte { return []byte("x") }, func(b []byte) bool { return string(b) == "fresh" })
+	if err != errNoReply {
+		t.Errorf("udpExchange() error = %v, want %v", err, errNoReply)
+	}
+}
+
+func TestPrintableReply(t *testing.T) {
+	tests := []struct {
+		reply []byte
+		want  string
+	}{
+		{[]byte("  hello\r\n"), "hello"},
+		{[]byte{0x00, 0xff, 0x10}, "00ff10"},
+		{[]byte(strings.Repeat("a", 500)), truncateActual(strings.Repeat("a", 500))},
+	}
+	for _, tt := range tests {
+		if got := printableReply(tt.reply); got != tt.want {
+			t.Errorf("printableReply(%q) = %q, want %q", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestValidateUDPOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType model.CheckType
+		opts      model.UDPOptions
+		wantErr   bool
+	}{
+		{"udp", model.CheckUDP, model.UDPOptions{Send: "PING", Expect: "PONG"}, false},
+		{"ntp thresholds", model.CheckNTP, model.UDPOptions{WarnOffsetMS: 100, MaxOffsetMS: 1000, MaxStratum: 3}, false},
+		{"snmp oid", model.CheckSNMP, model.UDPOptions{OID: ".1.3.6.1.2.1.1.5.0"}, false},
+		{"radius secret", model.CheckRADIUS, model.UDPOptions{Credentials: model.Credentials{PasswordEnv: "RADIUS_SECRET"}}, false},
+		{"bad regex", model.CheckUDP, model.UDPOptions{Expect: "(", Match: "regex"}, true},
+		{"two password sources", model.CheckSNMP, model.UDPOptions{Credentials: model.Credentials{Password: "a", PasswordEnv: "B"}}, true},
+		{"negative offset", model.CheckNTP, model.UDPOptions{MaxOffsetMS: -1}, true},
+		{"warn above max", model.CheckNTP, model.UDPOptions{WarnOffsetMS: 500, MaxOffsetMS: 100}, true},
+		{"stratum out of range", model.CheckNTP, model.UDPOptions{MaxStratum: 16}, true},
+		{"bad oid", model.CheckSNMP, model.UDPOptions{OID: "1.3.six"}, true},
+		{"radius without secret", model.CheckRADIUS, model.UDPOptions{}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateUDPOptions(tt.checkType, &tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateUDPOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
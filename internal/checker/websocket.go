@@ -0,0 +1,259 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// websocketGUID is appended to the handshake key to form the accept value
+// (RFC 6455 section 1.3).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// WebSocketChecker opens a WebSocket, optionally sends a text message, and
+// waits for a message matching the expectation.
+type WebSocketChecker struct{}
+
+func (c *WebSocketChecker) Type() model.CheckType {
+	return model.CheckWebSocket
+}
+
+func (c *WebSocketChecker) Check(ctx context.Context, monitor *model.Monitor) (*Result, error) {
+	opts := streamOptions(monitor)
+	matches, err := expectMatcher(model.TCPStep{Expect: opts.Expect, Match: opts.Match})
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+
+	var phases httpPhases
+	req, err := newStreamRequest(phases.withTrace(ctx), monitor)
+	if err != nil {
+		return &Result{Status: model.StatusDown, Error: err.Error()}, nil
+	}
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	start := time.Now()
+	resp, err := streamClient(ctx).Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		result := &Result{
+			Status:    model.StatusDown,
+			LatencyMS: latency,
+			Error:     fmt.Sprintf("request failed: %v", err),
+		}
+		phases.addTimings(result)
+		return result, nil
+	}
+	defer resp.Body.Close()
+
+	result := &Result{
+		Status:     model.StatusUp,
+		LatencyMS:  latency,
+		StatusCode: resp.StatusCode,
+		Details:    map[string]any{"status_code": resp.StatusCode},
+	}
+	phases.addTimings(result)
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if resp.StatusCode != http.StatusSwitchingProtocols || !ok {
+		result.Status = model.StatusDown
+		result.Error = fmt.Sprintf("handshake failed: HTTP %d", resp.StatusCode)
+		return result, nil
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != websocketAccept(key) {
+		result.Status = model.StatusDown
+		result.Error = "handshake failed: invalid Sec-WebSocket-Accept"
+		return result, nil
+	}
+	if p := resp.Header.Get("Sec-WebSocket-Protocol"); p != "" {
+		result.Details["subprotocol"] = p
+	}
+
+	opened := time.Now()
+	waitCtx, cancel := streamWait(ctx, opts)
+	defer cancel()
+	stop := context.AfterFunc(waitCtx, func() { conn.Close() })
+	defer stop()
+
+	ws := &wsConn{rw: conn, r: bufio.NewReader(conn)}
+	if opts.Send != "" {
+		if err := ws.writeFrame(wsText, []byte(opts.Send)); err != nil {
+			result.Status = model.StatusDown
+			result.Error = fmt.Sprintf("sending message: %v", err)
+			return result, nil
+		}
+	}
+
+	received := 0
+	for {
+		msg, err := ws.readMessage()
+		if err != nil {
+			if waitCtx.Err() != nil {
+				err = nil
+			}
+			result.Status = model.StatusDown
+			result.Error = noMessageError(opts, received, time.Since(opened), err)
+			break
+		}
+		received++
+		if matches(msg) {
+			result.Details["first_message_ms"] = durationMS(time.Since(opened))
+			result.Details["message"] = printableReply(msg)
+			ws.writeFrame(wsClose, []byte{0x03, 0xe8}) // 1000: normal closure
+			break
+		}
+	}
+	result.Details["messages"] = received
+	return result, nil
+}
+
+// websocketAccept returns the Sec-WebSocket-Accept value for key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// wsConn reads and writes WebSocket frames on an upgraded connection.
+type wsConn struct {
+	rw io.ReadWriter
+	r  *bufio.Reader
+}
+
+// writeFrame sends one final, masked frame, as clients must.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	var mask [4]byte
+	rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.rw.Write(frame)
+	return err
+}
+
+// readMessage returns the next text or binary message, reassembling
+// fragments and answering pings on the way. A close frame from the server
+// is returned as an error.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			return nil, closeError(payload)
+		case wsText, wsBinary, wsContinuation:
+			msg = append(msg, payload...)
+			if len(msg) > maxStreamMessage {
+				return nil, fmt.Errorf("message exceeds %d bytes", maxStreamMessage)
+			}
+		default:
+			return nil, fmt.Errorf("unknown opcode 0x%x", opcode)
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode = head[0]&0x80 != 0, head[0]&0x0f
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxStreamMessage {
+		return false, 0, nil, fmt.Errorf("frame exceeds %d bytes", maxStreamMessage)
+	}
+	var mask []byte
+	if head[1]&0x80 != 0 {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.r, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if mask != nil {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// closeError describes a close frame's status code and reason.
+func closeError(payload []byte) error {
+	if len(payload) < 2 {
+		return errors.New("server closed the connection")
+	}
+	code := binary.BigEndian.Uint16(payload)
+	if reason := strings.TrimSpace(string(payload[2:])); reason != "" {
+		return fmt.Errorf("server closed the connection: %d %s", code, reason)
+	}
+	return fmt.Errorf("server closed the connection: %d", code)
+}
@@ -0,0 +1,214 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// wsServerFrame encodes an unmasked frame, as servers send them.
+func wsServerFrame(fin bool, opcode byte, payload []byte) []byte {
+	head := opcode
+	if fin {
+		head |= 0x80
+	}
+	frame := []byte{head}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	return append(frame, payload...)
+}
+
+// wsServer upgrades requests and hands the connection to the handler for
+// the request path. /plain answers without upgrading and /bad-accept
+// answers the handshake with the wrong key.
+func wsServer(t *testing.T, handlers map[string]func(ws *wsConn)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			w.Write([]byte("hello"))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0k" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		accept := websocketAccept(r.Header.Get("Sec-WebSocket-Key"))
+		if r.URL.Path == "/bad-accept" {
+			accept = websocketAccept("other")
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n")
+		if p := r.Header.Get("Sec-WebSocket-Protocol"); p != "" {
+			rw.WriteString("Sec-WebSocket-Protocol: " + strings.Split(p, ",")[0] + "\r\n")
+		}
+		rw.WriteString("\r\n")
+		rw.Flush()
+		ws := &wsConn{rw: conn, r: rw.Reader}
+		if handle := handlers[r.URL.Path]; handle != nil {
+			handle(ws)
+		}
+		// Wait for the client to close.
+		for {
+			if _, _, _, err := ws.readFrame(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebSocketCheck(t *testing.T) {
+	srv := wsServer(t, map[string]func(ws *wsConn){
+		"/echo": func(ws *wsConn) {
+			// Client frames arrive masked; readMessage unmasks them.
+			msg, err := ws.readMessage()
+			if err != nil {
+				return
+			}
+			ws.rw.Write(wsServerFrame(true, wsText, append([]byte("echo: "), msg...)))
+		},
+		"/feed": func(ws *wsConn) {
+			// A ping must be answered before the feed starts.
+			ws.rw.Write(wsServerFrame(true, wsPing, []byte("hb")))
+			if fin, opcode, payload, err := ws.readFrame(); err != nil || !fin || opcode != wsPong || string(payload) != "hb" {
+				return
+			}
+			ws.rw.Write(wsServerFrame(true, wsText, []byte(`{"type":"hello"}`)))
+			ws.rw.Write(wsServerFrame(false, wsText, []byte(`{"type":"tick",`)))
+			ws.rw.Write(wsServerFrame(true, wsContinuation, []byte(`"n":1}`)))
+			ws.rw.Write(wsServerFrame(true, wsBinary, []byte{0xff, 0x00}))
+		},
+		"/close": func(ws *wsConn) {
+			ws.rw.Write(wsServerFrame(true, wsClose, append([]byte{0x03, 0xf0}, "policy violation"...)))
+		},
+		"/silent": nil,
+	})
+	ws := "ws" + strings.TrimPrefix(srv.URL, "http")
+	auth := &model.HTTPOptions{BearerToken: "t0k", Headers: map[string]string{"Sec-WebSocket-Protocol": "v2.chat, chat"}}
+
+	tests := []struct {
+		name         string
+		path         string
+		opts         *model.StreamOptions
+		httpOpts     *model.HTTPOptions
+		want         model.CheckStatus
+		wantMessage  string
+		wantError    string
+		wantMessages int
+	}{
+		{"first message", "/feed", nil, auth, model.StatusUp, `{"type":"hello"}`, "", 1},
+		{"send and expect", "/echo", &model.StreamOptions{Send: "ping", Expect: "echo: ping"}, auth, model.StatusUp, "echo: ping", "", 1},
+		{"fragmented message", "/feed", &model.StreamOptions{Expect: `"n":\d+`, Match: "regex"}, auth, model.StatusUp, `{"type":"tick","n":1}`, "", 2},
+		{"binary message", "/feed", &model.StreamOptions{Expect: "\xff"}, auth, model.StatusUp, "ff00", "", 3},
+		{"no match before wait", "/feed", &model.StreamOptions{Expect: "tock", WaitMS: 200}, auth, model.StatusDown, "",
+			`no message matching "tock" within`, 3},
+		{"silent", "/silent", &model.StreamOptions{WaitMS: 100}, auth, model.StatusDown, "", "no message within", 0},
+		{"closed by server", "/close", nil, auth, model.StatusDown, "", "server closed the connection: 1008 policy violation", 0},
+		{"not upgraded", "/plain", nil, auth, model.StatusDown, "", "handshake failed: HTTP 200", 0},
+		{"unauthorized", "/echo", nil, nil, model.StatusDown, "", "handshake failed: HTTP 401", 0},
+		{"bad accept", "/bad-accept", nil, auth, model.StatusDown, "", "invalid Sec-WebSocket-Accept", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkStream(t, &WebSocketChecker{}, ws+tt.path, tt.opts, tt.httpOpts)
+			if result.Status != tt.want || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("result = %s (%s), want %s (%s)", result.Status, result.Error, tt.want, tt.wantError)
+			}
+			if got, _ := result.Details["message"].(string); got != tt.wantMessage {
+				t.Errorf("message = %q, want %q", got, tt.wantMessage)
+			}
+			if got, _ := result.Details["messages"].(int); got != tt.wantMessages {
+				t.Errorf("messages = %d, want %d", got, tt.wantMessages)
+			}
+		})
+	}
+
+	result := checkStream(t, &WebSocketChecker{}, ws+"/feed", nil, auth)
+	if result.Details["subprotocol"] != "v2.chat" {
+		t.Errorf("subprotocol = %v, want v2.chat", result.Details["subprotocol"])
+	}
+	if _, ok := result.Details["first_message_ms"]; !ok {
+		t.Error("first_message_ms missing")
+	}
+}
+
+func TestWebSocketCheckRefused(t *testing.T) {
+	result := checkStream(t, &WebSocketChecker{}, "ws://127.0.0.1:1/", nil, nil)
+	if result.Status != model.StatusDown || !strings.Contains(result.Error, "request failed") {
+		t.Errorf("result = %s (%s), want down (request failed)", result.Status, result.Error)
+	}
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// The example handshake from RFC 6455 section 1.3.
+	if got := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("websocketAccept() = %s, want s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", got)
+	}
+}
+
+func TestWSConnFrames(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 70000)
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{"empty", nil},
+		{"short", []byte("hello")},
+		{"16-bit length", long[:300]},
+		{"64-bit length", long},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		c := &wsConn{rw: &buf, r: bufio.NewReader(&buf)}
+		if err := c.writeFrame(wsText, tt.payload); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Bytes()[1]&0x80 == 0 {
+			t.Errorf("%s: client frame is not masked", tt.name)
+		}
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil || !fin || opcode != wsText || !bytes.Equal(payload, tt.payload) {
+			t.Errorf("%s: readFrame() = %v, %x, %d bytes, %v", tt.name, fin, opcode, len(payload), err)
+		}
+	}
+}
+
+func TestWSConnReadMessageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []byte
+		want   string
+	}{
+		{"close without status", wsServerFrame(true, wsClose, nil), "server closed the connection"},
+		{"close with status", wsServerFrame(true, wsClose, []byte{0x03, 0xe9}), "server closed the connection: 1001"},
+		{"unknown opcode", wsServerFrame(true, 0x3, nil), "unknown opcode 0x3"},
+		{"oversized frame", []byte{0x81, 127, 0, 0, 0, 0, 0x01, 0, 0, 0}, "frame exceeds"},
+		{"truncated", []byte{0x81, 5, 'a'}, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		c := &wsConn{rw: &out, r: bufio.NewReader(bytes.NewReader(tt.frames))}
+		if _, err := c.readMessage(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: readMessage() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/config"
@@ -17,6 +18,8 @@ func newHistoryCmd() *cobra.Command {
 		nodeID    string
 		since     string
 		limit     int
+		timings   bool
+		diffs     bool
 	)
 
 	cmd := &cobra.Command{
@@ -60,6 +63,15 @@ func newHistoryCmd() *cobra.Command {
 				return nil
 			}
 
+			if timings {
+				printTimings(results)
+				return nil
+			}
+			if diffs {
+				printContentDiffs(results)
+				return nil
+			}
+
 			fmt.Printf("%-20s  %-10s  %-10s  %-8s  %-10s  %s\n", "TIME", "MONITOR", "NODE", "STATUS", "LATENCY", "ERROR")
 			for _, r := range results {
 				ts := time.UnixMilli(r.Timestamp).Format("15:04:05")
@@ -87,6 +99,70 @@ func newHistoryCmd() *cobra.Command {
 	cmd.Flags().StringVar(&nodeID, "node", "", "filter by node ID")
 	cmd.Flags().StringVar(&since, "since", "24h", "show results since duration ago")
 	cmd.Flags().IntVar(&limit, "limit", 50, "max results to show")
+	cmd.Flags().BoolVar(&timings, "timings", false, "show the HTTP phase breakdown (DNS, connect, TLS, TTFB, transfer) instead of errors")
+	cmd.Flags().BoolVar(&diffs, "diffs", false, "show the content diffs recorded by content-change checks")
 
 	return cmd
 }
+
+// printTimings lists results with their HTTP phase times. Phases a check
+// skipped, and results without timings, show as "-".
+func printTimings(results []model.CheckResult) {
+	fmt.Printf("%-20s  %-10s  %-10s  %-8s  %9s", "TIME", "MONITOR", "NODE", "STATUS", "LATENCY")
+	for _, phase := range model.HTTPPhases {
+		fmt.Printf("  %9s", strings.ToUpper(phase))
+	}
+	fmt.Println()
+
+	for _, r := range results {
+		var details struct {
+			Timings map[string]float64 `json:"timings"`
+		}
+		json.Unmarshal(r.Details, &details)
+
+		fmt.Printf("%-20s  %-10s  %-10s  %-8s  %7.1fms",
+			time.UnixMilli(r.Timestamp).Format("15:04:05"), shortID(r.MonitorID), shortID(r.NodeID), r.Status, r.LatencyMS)
+		for _, phase := range model.HTTPPhases {
+			if ms, ok := details.Timings[phase+"_ms"]; ok {
+				fmt.Printf("  %7.1fms", ms)
+			} else {
+				fmt.Printf("  %9s", "-")
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// printContentDiffs prints the stored diff of each result whose content
+// differed too much from its baseline.
+func printContentDiffs(results []model.CheckResult) {
+	found := false
+	for _, r := range results {
+		var details struct {
+			Similarity   float64 `json:"similarity"`
+			BaselineHash string  `json:"baseline_hash"`
+			ContentHash  string  `json:"content_hash"`
+			ContentDiff  string  `json:"content_diff"`
+		}
+		json.Unmarshal(r.Details, &details)
+		if details.ContentDiff == "" {
+			continue
+		}
+		found = true
+		fmt.Printf("%s  monitor %s  node %s  %.1f%% similar (%s -> %s)\n",
+			time.UnixMilli(r.Timestamp).Format("2006-01-02 15:04:05"), shortID(r.MonitorID), shortID(r.NodeID),
+			details.Similarity, shortID(details.BaselineHash), shortID(details.ContentHash))
+		fmt.Println(details.ContentDiff)
+	}
+	if !found {
+		fmt.Println("No content changes found.")
+	}
+}
+
+// shortID returns the first 8 characters of an ID.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/config"
@@ -44,15 +45,18 @@ func newIncidentsCmd() *cobra.Command {
 				return nil
 			}
 
-			fmt.Printf("%-10s  %-10s  %-12s  %-20s  %s\n", "ID", "MONITOR", "STATUS", "STARTED", "CONFIRMED BY")
+			fmt.Printf("%-10s  %-10s  %-9s  %-12s  %-20s  %s\n", "ID", "MONITOR", "KIND", "STATUS", "STARTED", "CONFIRMED BY")
 			for _, inc := range incidents {
 				started := time.UnixMilli(inc.StartedAt).Format("2006-01-02 15:04")
 				confirmedBy := "-"
 				if len(inc.ConfirmingNodes) > 0 {
 					confirmedBy = fmt.Sprintf("%d nodes", len(inc.ConfirmingNodes))
 				}
-				fmt.Printf("%-10s  %-10s  %-12s  %-20s  %s\n",
-					inc.ID[:8], inc.MonitorID[:8], inc.Status, started, confirmedBy)
+				if len(inc.ConfirmingRegions) > 0 {
+					confirmedBy += fmt.Sprintf(" in %d regions", len(inc.ConfirmingRegions))
+				}
+				fmt.Printf("%-10s  %-10s  %-9s  %-12s  %-20s  %s\n",
+					inc.ID[:8], inc.MonitorID[:8], inc.Kind, inc.Status, started, confirmedBy)
 			}
 
 			return nil
@@ -60,5 +64,164 @@ func newIncidentsCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&activeOnly, "active", false, "show only active incidents")
+	cmd.AddCommand(newIncidentPathsCmd())
 	return cmd
 }
+
+func newIncidentPathsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "paths <id>",
+		Short: "Show the network paths captured when an incident opened",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(dataDir)
+			if err != nil {
+				return err
+			}
+
+			id, err := resolveIncidentID(cfg.CLIAddr, args[0])
+			if err != nil {
+				return err
+			}
+
+			resp, err := http.Get(fmt.Sprintf("http://%s/api/v1/incidents/%s/paths", cfg.CLIAddr, id))
+			if err != nil {
+				return fmt.Errorf("connecting to agent: %w (is the agent running?)", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("incident not found: %s", args[0])
+			}
+
+			var captures []model.PathCapture
+			if err := json.NewDecoder(resp.Body).Decode(&captures); err != nil {
+				return fmt.Errorf("decoding response: %w", err)
+			}
+
+			if len(captures) == 0 {
+				fmt.Println("No paths captured for this incident.")
+				return nil
+			}
+
+			for _, c := range captures {
+				state := "healthy"
+				if c.Failing {
+					state = "failing"
+				}
+				reached := "reached"
+				if !c.Reached {
+					reached = "not reached"
+				}
+				fmt.Printf("Node %s (%s): %s trace to %s, %s\n", c.NodeID[:8], state, c.Protocol, c.Target, reached)
+				if c.Error != "" {
+					fmt.Printf("  error: %s\n\n", c.Error)
+					continue
+				}
+				fmt.Printf("  %-4s  %-40s  %6s  %8s  %8s  %8s\n", "TTL", "ADDRESS", "LOSS", "AVG", "MIN", "MAX")
+				for _, h := range c.Hops {
+					addr := h.Address
+					if addr == "" {
+						addr = "*"
+					} else if len(h.Addresses) > 1 {
+						addr = strings.Join(h.Addresses, ",")
+					}
+					if h.Received == 0 {
+						fmt.Printf("  %-4d  %-40s  %5.1f%%  %8s  %8s  %8s\n", h.TTL, addr, h.LossPercent, "-", "-", "-")
+						continue
+					}
+					fmt.Printf("  %-4d  %-40s  %5.1f%%  %6.1fms  %6.1fms  %6.1fms\n",
+						h.TTL, addr, h.LossPercent, h.AvgMS, h.MinMS, h.MaxMS)
+				}
+				fmt.Println()
+			}
+
+			printPathDivergence(captures)
+			return nil
+		},
+	}
+}
+
+// resolveIncidentID expands the short ID printed by "incidents" to a full one.
+func resolveIncidentID(addr, id string) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/v1/incidents", addr))
+	if err != nil {
+		return "", fmt.Errorf("connecting to agent: %w (is the agent running?)", err)
+	}
+	defer resp.Body.Close()
+
+	var incidents []model.Incident
+	if err := json.NewDecoder(resp.Body).Decode(&incidents); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+
+	var matches []string
+	for _, inc := range incidents {
+		if inc.ID == id {
+			return id, nil
+		}
+		if strings.HasPrefix(inc.ID, id) {
+			matches = append(matches, inc.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("incident not found: %s", id)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("incident ID %s is ambiguous", id)
+}
+
+// printPathDivergence reports, for each failing node, the last hop its path
+// shares with a healthy node's path; the fault usually lies just beyond it.
+func printPathDivergence(captures []model.PathCapture) {
+	var healthy, failing []model.PathCapture
+	for _, c := range captures {
+		if c.Error != "" {
+			continue
+		}
+		if c.Failing {
+			failing = append(failing, c)
+		} else {
+			healthy = append(healthy, c)
+		}
+	}
+	if len(failing) == 0 || len(healthy) == 0 {
+		return
+	}
+
+	fmt.Println("Divergence from healthy paths:")
+	for _, f := range failing {
+		best, bestNode := 0, ""
+		for _, h := range healthy {
+			if n := sharedHops(f.Hops, h.Hops); n > best || bestNode == "" {
+				best, bestNode = n, h.NodeID
+			}
+		}
+		switch {
+		case best == 0:
+			fmt.Printf("  %s: shares no hops with any healthy path\n", f.NodeID[:8])
+		case best >= len(f.Hops):
+			fmt.Printf("  %s: path matches %s for all %d hops\n", f.NodeID[:8], bestNode[:8], best)
+		default:
+			last := f.Hops[best-1]
+			addr := last.Address
+			if addr == "" {
+				addr = "*"
+			}
+			fmt.Printf("  %s: matches %s up to hop %d (%s), diverges at hop %d\n",
+				f.NodeID[:8], bestNode[:8], last.TTL, addr, f.Hops[best].TTL)
+		}
+	}
+}
+
+// sharedHops counts the leading hops whose responders are the same in both
+// paths. Silent hops match each other since nothing can be said about them.
+func sharedHops(a, b []model.Hop) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n].Address == b[n].Address {
+		n++
+	}
+	return n
+}
@@ -88,6 +88,7 @@ func newInitCmd() *cobra.Command {
 				Status:    model.NodeOnline,
 				LastSeen:  now,
 				CreatedAt: now,
+				Weight:    1,
 			}
 			if err := st.CreateNode(node); err != nil {
 				return fmt.Errorf("registering node: %w", err)
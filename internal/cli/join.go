@@ -118,6 +118,7 @@ func newJoinCmd() *cobra.Command {
 				Status:    model.NodeOnline,
 				LastSeen:  now,
 				CreatedAt: now,
+				Weight:    1,
 			}
 			if err := st.CreateNode(node); err != nil {
 				return fmt.Errorf("registering node: %w", err)
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/pingmesh/pingmesh/internal/config"
@@ -24,6 +27,7 @@ func newMonitorCmd() *cobra.Command {
 		newMonitorAddCmd(),
 		newMonitorShowCmd(),
 		newMonitorEditCmd(),
+		newMonitorAcceptCmd(),
 		newMonitorDeleteCmd(),
 	)
 
@@ -94,10 +98,38 @@ func newMonitorAddCmd() *cobra.Command {
 		interval   string
 		timeout    string
 		group      string
+		quorum     string
+		quorumN    int
 		keyword    string
 		status     int
 		dnsType    string
-		dnsExpect  string
+		dnsExpect  []string
+		httpOpts   httpFlags
+		tlsOpts    tlsFlags
+		dnsOpts    dnsFlags
+		tcpOpts    tcpFlags
+		icmpOpts   icmpFlags
+		traceOpts  traceFlags
+		grpcOpts   grpcFlags
+		dbOpts     dbFlags
+		mailOpts   mailFlags
+		domainOpts domainFlags
+		udpOpts    udpFlags
+		streamOpts streamFlags
+		content    contentFlags
+		assets     assetFlags
+		family     string
+		grace      string
+		flowFile   string
+		execCmd    string
+		execArgs   []string
+		execEnv    []string
+		asserts    []string
+		latWarn    float64
+		latCrit    float64
+		phaseWarn  []string
+		phaseCrit  []string
+		degraded   degradedFlags
 	)
 
 	cmd := &cobra.Command{
@@ -109,7 +141,9 @@ func newMonitorAddCmd() *cobra.Command {
 				return err
 			}
 
-			if name == "" || checkType == "" || target == "" {
+			targetOptional := checkType == string(model.CheckPush) || checkType == string(model.CheckHTTPFlow) ||
+				checkType == string(model.CheckExec)
+			if name == "" || checkType == "" || (target == "" && !targetOptional) {
 				return fmt.Errorf("--name, --type, and --target are required")
 			}
 
@@ -119,10 +153,226 @@ func newMonitorAddCmd() *cobra.Command {
 				Target:          target,
 				Port:            port,
 				GroupName:       group,
+				QuorumType:      quorum,
+				QuorumN:         quorumN,
 				ExpectedKeyword: keyword,
 				ExpectedStatus:  status,
 				DNSRecordType:   dnsType,
-				DNSExpected:     dnsExpect,
+			}
+			if len(dnsExpect) == 1 {
+				m.DNSExpected = dnsExpect[0]
+			} else if len(dnsExpect) > 1 {
+				dnsOpts.expected = dnsExpect
+			}
+
+			httpOptions, err := httpOpts.options()
+			if err != nil {
+				return err
+			}
+			if httpOptions != nil {
+				m.Options = &model.MonitorOptions{HTTP: httpOptions}
+			}
+
+			if tlsOptions := tlsOpts.options(); tlsOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.TLS = tlsOptions
+			}
+
+			if dnsOptions := dnsOpts.options(); dnsOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.DNS = dnsOptions
+			}
+
+			tcpOptions, err := tcpOpts.options()
+			if err != nil {
+				return err
+			}
+			if tcpOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.TCP = tcpOptions
+			}
+
+			icmpOptions, err := icmpOpts.options()
+			if err != nil {
+				return err
+			}
+			if icmpOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.ICMP = icmpOptions
+			}
+
+			traceOptions, err := traceOpts.options()
+			if err != nil {
+				return err
+			}
+			if traceOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Traceroute = traceOptions
+			}
+
+			grpcOptions, err := grpcOpts.options()
+			if err != nil {
+				return err
+			}
+			if grpcOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.GRPC = grpcOptions
+			}
+
+			if dbOptions := dbOpts.options(); dbOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Database = dbOptions
+			}
+
+			if mailOptions := mailOpts.options(); mailOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Mail = mailOptions
+			}
+
+			if domainOptions := domainOpts.options(); domainOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Domain = domainOptions
+			}
+
+			udpOptions, err := udpOpts.options()
+			if err != nil {
+				return err
+			}
+			if udpOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.UDP = udpOptions
+			}
+
+			streamOptions, err := streamOpts.options()
+			if err != nil {
+				return err
+			}
+			if streamOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Stream = streamOptions
+			}
+
+			if contentOptions := content.options(); contentOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Content = contentOptions
+			}
+
+			if assetOptions := assets.options(); assetOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Assets = assetOptions
+			}
+
+			if latWarn > 0 || latCrit > 0 || len(phaseWarn) > 0 || len(phaseCrit) > 0 {
+				phases, err := parsePhaseThresholds(phaseWarn, phaseCrit)
+				if err != nil {
+					return err
+				}
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Latency = &model.LatencyOptions{WarnMS: latWarn, CriticalMS: latCrit, Phases: phases}
+			}
+
+			if degradedOptions := degraded.options(); degradedOptions != nil {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Degraded = degradedOptions
+			}
+
+			if flowFile != "" {
+				flow, err := readFlowFile(flowFile)
+				if err != nil {
+					return err
+				}
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.HTTPFlow = flow
+			}
+
+			if execCmd != "" {
+				env := map[string]string{}
+				for _, kv := range execEnv {
+					k, v, ok := strings.Cut(kv, "=")
+					if !ok || k == "" {
+						return fmt.Errorf("invalid --env %q: want KEY=VALUE", kv)
+					}
+					env[k] = v
+				}
+				if len(env) == 0 {
+					env = nil
+				}
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Exec = &model.ExecOptions{Command: execCmd, Args: execArgs, Env: env}
+			}
+
+			if family != "" {
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.AddressFamily = family
+			}
+
+			if grace != "" {
+				ms, err := parseDurationMS(grace)
+				if err != nil {
+					return fmt.Errorf("invalid grace: %w", err)
+				}
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Push = &model.PushOptions{GraceMS: ms}
+			}
+
+			for _, spec := range asserts {
+				a, err := parseAssertion(spec)
+				if err != nil {
+					return err
+				}
+				if m.Options == nil {
+					m.Options = &model.MonitorOptions{}
+				}
+				m.Options.Assertions = append(m.Options.Assertions, a)
+			}
+
+			// Domain checks get a daily interval and a longer timeout from
+			// the agent unless set explicitly.
+			if checkType == string(model.CheckDomain) {
+				if !cmd.Flags().Changed("interval") {
+					interval = ""
+				}
+				if !cmd.Flags().Changed("timeout") {
+					timeout = ""
+				}
 			}
 
 			// Parse interval
@@ -166,26 +416,776 @@ func newMonitorAddCmd() *cobra.Command {
 			fmt.Printf("  Name:   %s\n", created.Name)
 			fmt.Printf("  Type:   %s\n", created.CheckType)
 			fmt.Printf("  Target: %s\n", created.Target)
+			if created.Options != nil && created.Options.Push != nil {
+				fmt.Printf("  Push URL: %s\n", pushURL(cfg, created.Options.Push.Token))
+			}
 
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "monitor name")
-	cmd.Flags().StringVar(&checkType, "type", "", "check type (icmp, tcp, http, https, dns, http_keyword)")
-	cmd.Flags().StringVar(&target, "target", "", "target host")
+	cmd.Flags().StringVar(&checkType, "type", "", "check type (icmp, tcp, http, https, dns, http_keyword, tls, traceroute, grpc, postgres, mysql, redis, smtp, imap, pop3, push, http_flow, exec, domain, udp, ntp, snmp, radius, websocket, sse)")
+	cmd.Flags().StringVar(&target, "target", "", "target host, or URL for HTTP checks (base URL for http_flow)")
 	cmd.Flags().IntVar(&port, "port", 0, "target port")
+	cmd.Flags().StringVar(&family, "address-family", "", "check over IPv4 or IPv6 only (v4, v6), or over each separately (both)")
+	cmd.Flags().StringVar(&flowFile, "flow-file", "", "http_flow checks: JSON file with the flow's variables and steps")
+	cmd.Flags().StringVar(&execCmd, "command", "", "exec checks: absolute path of the command to run; must match the node's exec.allow config")
+	cmd.Flags().StringArrayVar(&execArgs, "arg", nil, "exec checks: command argument (repeatable); {{target}}, {{port}} and {{timeout}} are substituted")
+	cmd.Flags().StringArrayVar(&execEnv, "env", nil, "exec checks: extra environment variable as KEY=VALUE (repeatable)")
+	cmd.Flags().StringVar(&grace, "grace", "", "push checks: how late a ping may be, and how long a started run may take (default 1m)")
 	cmd.Flags().StringVar(&interval, "interval", "60s", "check interval")
 	cmd.Flags().StringVar(&timeout, "timeout", "5s", "check timeout")
 	cmd.Flags().StringVar(&group, "group", "", "monitor group name")
+	cmd.Flags().StringVar(&quorum, "quorum", "majority", "nodes that must agree on a failure: majority, n_of_m (weighted node votes) or regions (distinct node locations)")
+	cmd.Flags().IntVar(&quorumN, "quorum-n", 0, "required node weight for n_of_m, or distinct regions for regions")
 	cmd.Flags().StringVar(&keyword, "keyword", "", "expected keyword in response body")
 	cmd.Flags().IntVar(&status, "status", 0, "expected HTTP status code")
-	cmd.Flags().StringVar(&dnsType, "dns-type", "", "DNS record type (A, AAAA, CNAME)")
-	cmd.Flags().StringVar(&dnsExpect, "dns-expect", "", "expected DNS answer")
+	cmd.Flags().StringVar(&dnsType, "dns-type", "", "DNS record type (A, AAAA, CNAME, MX, TXT, NS, SOA, SRV, CAA, PTR)")
+	cmd.Flags().StringArrayVar(&dnsExpect, "dns-expect", nil, "expected DNS answer (repeatable; see --dns-match)")
+	cmd.Flags().StringArrayVar(&asserts, "assert", nil, "response assertion as 'SOURCE[:PROPERTY] OPERATOR [VALUE]' (repeatable), e.g. 'json:$.status equals ok'")
+	cmd.Flags().Float64Var(&latWarn, "latency-warn", 0, "latency in ms above which a check is degraded")
+	cmd.Flags().Float64Var(&latCrit, "latency-critical", 0, "latency in ms above which a check is down")
+	cmd.Flags().StringArrayVar(&phaseWarn, "phase-warn", nil, "HTTP phase time as PHASE=MS above which a check is degraded (repeatable; dns, connect, tls, ttfb, transfer)")
+	cmd.Flags().StringArrayVar(&phaseCrit, "phase-critical", nil, "HTTP phase time as PHASE=MS above which a check is down (repeatable)")
+	httpOpts.register(cmd)
+	tlsOpts.register(cmd)
+	dnsOpts.register(cmd)
+	tcpOpts.register(cmd)
+	icmpOpts.register(cmd)
+	traceOpts.register(cmd)
+	grpcOpts.register(cmd)
+	dbOpts.register(cmd)
+	mailOpts.register(cmd)
+	domainOpts.register(cmd)
+	udpOpts.register(cmd)
+	streamOpts.register(cmd)
+	content.register(cmd)
+	assets.register(cmd)
+	degraded.register(cmd)
 
 	return cmd
 }
 
+// tlsFlags holds the certificate check options accepted by "monitor add".
+type tlsFlags struct {
+	serverName string
+	startTLS   string
+	warnDays   int
+}
+
+func (f *tlsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.serverName, "tls-server-name", "", "hostname to send as SNI and verify (default target)")
+	cmd.Flags().StringVar(&f.startTLS, "starttls", "", "upgrade via STARTTLS before the handshake (smtp, imap, pop3)")
+	cmd.Flags().IntVar(&f.warnDays, "expiry-warn-days", 0, "report degraded this many days before certificate expiry (default 7)")
+}
+
+// options returns the TLS options described by the flags, or nil if none were set.
+func (f *tlsFlags) options() *model.TLSOptions {
+	if f.serverName == "" && f.startTLS == "" && f.warnDays == 0 {
+		return nil
+	}
+	return &model.TLSOptions{
+		ServerName:     f.serverName,
+		StartTLS:       f.startTLS,
+		ExpiryWarnDays: f.warnDays,
+	}
+}
+
+// dnsFlags holds the resolver and matching options accepted by "monitor add".
+type dnsFlags struct {
+	resolvers        []string
+	transport        string
+	authoritative    bool
+	expected         []string
+	match            string
+	dnssec           bool
+	consistentSerial bool
+	alertOnChange    bool
+}
+
+func (f *dnsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.resolvers, "resolver", nil, "DNS resolver as host[:port], 'system' or a DoH URL (repeatable; default 8.8.8.8)")
+	cmd.Flags().StringVar(&f.transport, "dns-transport", "", "DNS transport (udp, tcp, tls, https)")
+	cmd.Flags().BoolVar(&f.authoritative, "authoritative", false, "send non-recursive queries and require an authoritative answer")
+	cmd.Flags().StringVar(&f.match, "dns-match", "", "how --dns-expect values are matched (any, all, exact, regex; default any)")
+	cmd.Flags().BoolVar(&f.dnssec, "dnssec", false, "require DNSSEC validated answers (signed answers with --authoritative)")
+	cmd.Flags().BoolVar(&f.consistentSerial, "consistent-serial", false, "SOA checks: require the same serial from every resolver")
+	cmd.Flags().BoolVar(&f.alertOnChange, "alert-on-change", false, "report down when answers differ from those first seen")
+}
+
+// options returns the DNS options described by the flags, or nil if none were set.
+func (f *dnsFlags) options() *model.DNSOptions {
+	if len(f.resolvers) == 0 && f.transport == "" && !f.authoritative && len(f.expected) == 0 &&
+		f.match == "" && !f.dnssec && !f.consistentSerial && !f.alertOnChange {
+		return nil
+	}
+	return &model.DNSOptions{
+		Resolvers:        f.resolvers,
+		Transport:        f.transport,
+		Authoritative:    f.authoritative,
+		Expected:         f.expected,
+		Match:            f.match,
+		DNSSEC:           f.dnssec,
+		ConsistentSerial: f.consistentSerial,
+		AlertOnChange:    f.alertOnChange,
+	}
+}
+
+// tcpFlags holds the TLS and send/expect options accepted by "monitor add".
+// The n-th --send and n-th --expect form one step.
+type tcpFlags struct {
+	tls        bool
+	skipVerify bool
+	banner     bool
+	send       []string
+	expect     []string
+	regex      bool
+}
+
+func (f *tcpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.tls, "tcp-tls", false, "TCP checks: wrap the connection in TLS")
+	cmd.Flags().BoolVar(&f.skipVerify, "tcp-skip-verify", false, "TCP checks: accept any TLS certificate")
+	cmd.Flags().BoolVar(&f.banner, "banner", false, "TCP checks: read and record the server banner")
+	cmd.Flags().StringArrayVar(&f.send, "send", nil, "TCP checks: payload to send, with Go escapes such as \\r\\n (repeatable)")
+	cmd.Flags().StringArrayVar(&f.expect, "expect", nil, "TCP checks: text the response must contain (repeatable, paired with --send)")
+	cmd.Flags().BoolVar(&f.regex, "expect-regex", false, "TCP checks: treat --expect values as regular expressions")
+}
+
+// options returns the TCP options described by the flags, or nil if none were set.
+func (f *tcpFlags) options() (*model.TCPOptions, error) {
+	if !f.tls && !f.skipVerify && !f.banner && len(f.send) == 0 && len(f.expect) == 0 {
+		return nil, nil
+	}
+
+	opts := &model.TCPOptions{
+		TLS:        f.tls,
+		SkipVerify: f.skipVerify,
+		ReadBanner: f.banner,
+	}
+	for i := 0; i < max(len(f.send), len(f.expect)); i++ {
+		var step model.TCPStep
+		if i < len(f.send) {
+			payload, err := strconv.Unquote(`"` + strings.ReplaceAll(f.send[i], `"`, `\"`) + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --send %q: %w", f.send[i], err)
+			}
+			step.Send = payload
+		}
+		if i < len(f.expect) {
+			step.Expect = f.expect[i]
+			if f.regex {
+				step.Match = "regex"
+			}
+		}
+		opts.Steps = append(opts.Steps, step)
+	}
+	return opts, nil
+}
+
+// icmpFlags holds the multi-packet ping options accepted by "monitor add".
+type icmpFlags struct {
+	count        int
+	interval     string
+	size         int
+	dontFragment bool
+	privileged   bool
+	lossWarn     float64
+	lossCritical float64
+}
+
+func (f *icmpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.count, "count", 0, "ICMP checks: packets per check (default 1)")
+	cmd.Flags().StringVar(&f.interval, "ping-interval", "", "ICMP checks: time between packets (default 1s)")
+	cmd.Flags().IntVar(&f.size, "packet-size", 0, "ICMP checks: payload size in bytes, at least 24")
+	cmd.Flags().BoolVar(&f.dontFragment, "dont-fragment", false, "ICMP checks: set the DF bit, for MTU testing")
+	cmd.Flags().BoolVar(&f.privileged, "privileged", false, "ICMP checks: use raw sockets (needs root or CAP_NET_RAW)")
+	cmd.Flags().Float64Var(&f.lossWarn, "loss-warn", 0, "ICMP checks: packet loss percentage that marks the check degraded")
+	cmd.Flags().Float64Var(&f.lossCritical, "loss-critical", 0, "ICMP checks: packet loss percentage that marks the check down")
+}
+
+// options returns the ICMP options described by the flags, or nil if none were set.
+func (f *icmpFlags) options() (*model.ICMPOptions, error) {
+	if f.count == 0 && f.interval == "" && f.size == 0 && !f.dontFragment && !f.privileged &&
+		f.lossWarn == 0 && f.lossCritical == 0 {
+		return nil, nil
+	}
+
+	opts := &model.ICMPOptions{
+		Count:               f.count,
+		Size:                f.size,
+		DontFragment:        f.dontFragment,
+		Privileged:          f.privileged,
+		LossWarnPercent:     f.lossWarn,
+		LossCriticalPercent: f.lossCritical,
+	}
+	if f.interval != "" {
+		ms, err := parseDurationMS(f.interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ping interval: %w", err)
+		}
+		opts.IntervalMS = ms
+	}
+	return opts, nil
+}
+
+// traceFlags holds the traceroute options accepted by "monitor add". They
+// apply to traceroute checks and to the paths captured when any monitor's
+// down incident opens.
+type traceFlags struct {
+	protocol     string
+	port         int
+	maxHops      int
+	probes       int
+	probeTimeout string
+	noCapture    bool
+}
+
+func (f *traceFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.protocol, "trace-protocol", "", "traceroute: probe protocol (icmp, udp or tcp; default icmp)")
+	cmd.Flags().IntVar(&f.port, "trace-port", 0, "traceroute: udp base port or tcp destination port")
+	cmd.Flags().IntVar(&f.maxHops, "max-hops", 0, "traceroute: maximum TTL (default 30)")
+	cmd.Flags().IntVar(&f.probes, "probes", 0, "traceroute: probes per hop (default 3)")
+	cmd.Flags().StringVar(&f.probeTimeout, "probe-timeout", "", "traceroute: wait for replies per round (default 1s)")
+	cmd.Flags().BoolVar(&f.noCapture, "no-path-capture", false, "do not capture traceroutes when a down incident opens")
+}
+
+// options returns the traceroute options described by the flags, or nil if none were set.
+func (f *traceFlags) options() (*model.TracerouteOptions, error) {
+	if f.protocol == "" && f.port == 0 && f.maxHops == 0 && f.probes == 0 && f.probeTimeout == "" && !f.noCapture {
+		return nil, nil
+	}
+
+	opts := &model.TracerouteOptions{
+		Protocol:  f.protocol,
+		Port:      f.port,
+		MaxHops:   f.maxHops,
+		Probes:    f.probes,
+		NoCapture: f.noCapture,
+	}
+	if f.probeTimeout != "" {
+		ms, err := parseDurationMS(f.probeTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe timeout: %w", err)
+		}
+		opts.ProbeTimeoutMS = ms
+	}
+	return opts, nil
+}
+
+// grpcFlags holds the gRPC health check options accepted by "monitor add".
+type grpcFlags struct {
+	service    string
+	tls        bool
+	serverName string
+	skipVerify bool
+	caCert     string
+	clientCert string
+	clientKey  string
+	metadata   []string
+	statusMap  []string
+}
+
+func (f *grpcFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.service, "grpc-service", "", "gRPC checks: service name to check (default the whole server)")
+	cmd.Flags().BoolVar(&f.tls, "grpc-tls", false, "gRPC checks: connect with TLS instead of plaintext")
+	cmd.Flags().StringVar(&f.serverName, "grpc-server-name", "", "gRPC checks: TLS server name (default the target host)")
+	cmd.Flags().BoolVar(&f.skipVerify, "grpc-skip-verify", false, "gRPC checks: accept any server certificate")
+	cmd.Flags().StringVar(&f.caCert, "grpc-ca-cert", "", "gRPC checks: CA bundle file used to verify the server")
+	cmd.Flags().StringVar(&f.clientCert, "grpc-client-cert", "", "gRPC checks: client certificate file for mTLS")
+	cmd.Flags().StringVar(&f.clientKey, "grpc-client-key", "", "gRPC checks: client key file for mTLS")
+	cmd.Flags().StringArrayVar(&f.metadata, "metadata", nil, "gRPC checks: request metadata as 'key: value' (repeatable)")
+	cmd.Flags().StringArrayVar(&f.statusMap, "grpc-status", nil, "gRPC checks: map a health status to up, down or degraded, e.g. UNKNOWN=down (repeatable)")
+}
+
+// options returns the gRPC options described by the flags, or nil if none were set.
+func (f *grpcFlags) options() (*model.GRPCOptions, error) {
+	if f.service == "" && !f.tls && f.serverName == "" && !f.skipVerify && f.caCert == "" &&
+		f.clientCert == "" && f.clientKey == "" && len(f.metadata) == 0 && len(f.statusMap) == 0 {
+		return nil, nil
+	}
+
+	opts := &model.GRPCOptions{
+		Service:    f.service,
+		TLS:        f.tls,
+		ServerName: f.serverName,
+		SkipVerify: f.skipVerify,
+		CACert:     f.caCert,
+		ClientCert: f.clientCert,
+		ClientKey:  f.clientKey,
+	}
+
+	for _, md := range f.metadata {
+		key, value, ok := strings.Cut(md, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid metadata %q (expected 'key: value')", md)
+		}
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]string)
+		}
+		opts.Metadata[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	for _, mapping := range f.statusMap {
+		health, state, ok := strings.Cut(mapping, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid grpc status mapping %q (expected STATUS=state)", mapping)
+		}
+		if opts.StatusMap == nil {
+			opts.StatusMap = make(map[string]string)
+		}
+		opts.StatusMap[strings.ToUpper(health)] = strings.ToLower(state)
+	}
+
+	return opts, nil
+}
+
+// dbFlags holds the postgres, mysql and redis options accepted by "monitor add".
+type dbFlags struct {
+	username     string
+	password     string
+	passwordEnv  string
+	passwordFile string
+	database     string
+	query        string
+	tls          string
+	replication  bool
+	maxLag       float64
+}
+
+func (f *dbFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.username, "db-user", "", "database checks: username")
+	cmd.Flags().StringVar(&f.password, "db-password", "", "database checks: password, stored with the monitor")
+	cmd.Flags().StringVar(&f.passwordEnv, "db-password-env", "", "database checks: environment variable holding the password on each node")
+	cmd.Flags().StringVar(&f.passwordFile, "db-password-file", "", "database checks: file holding the password on each node")
+	cmd.Flags().StringVar(&f.database, "database", "", "database checks: database name, or DB index for redis")
+	cmd.Flags().StringVar(&f.query, "query", "", "database checks: query to run (default SELECT 1, or PING for redis)")
+	cmd.Flags().StringVar(&f.tls, "db-tls", "", "database checks: TLS mode (disable, require or verify)")
+	cmd.Flags().BoolVar(&f.replication, "replication", false, "database checks: report role and replication lag")
+	cmd.Flags().Float64Var(&f.maxLag, "max-lag", 0, "database checks: replication lag in seconds that marks a replica degraded")
+}
+
+// options returns the database options described by the flags, or nil if none were set.
+func (f *dbFlags) options() *model.DatabaseOptions {
+	if f.username == "" && f.password == "" && f.passwordEnv == "" && f.passwordFile == "" &&
+		f.database == "" && f.query == "" && f.tls == "" && !f.replication && f.maxLag == 0 {
+		return nil
+	}
+	return &model.DatabaseOptions{
+		Credentials: model.Credentials{
+			Username:     f.username,
+			Password:     f.password,
+			PasswordEnv:  f.passwordEnv,
+			PasswordFile: f.passwordFile,
+		},
+		Database:    f.database,
+		Query:       f.query,
+		TLS:         f.tls,
+		Replication: f.replication,
+		MaxLagSec:   f.maxLag,
+	}
+}
+
+// mailFlags holds the smtp, imap and pop3 options accepted by "monitor add".
+type mailFlags struct {
+	username     string
+	password     string
+	passwordEnv  string
+	passwordFile string
+	tls          string
+	serverName   string
+	skipVerify   bool
+	helo         string
+	mailbox      string
+
+	rtFrom       string
+	rtTo         string
+	imapHost     string
+	imapPort     int
+	imapTLS      string
+	imapUser     string
+	imapPassword string
+	imapPassEnv  string
+	imapPassFile string
+	keepMessages bool
+}
+
+func (f *mailFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.username, "mail-user", "", "mail checks: username for SMTP AUTH or the IMAP/POP3 login")
+	cmd.Flags().StringVar(&f.password, "mail-password", "", "mail checks: password, stored with the monitor")
+	cmd.Flags().StringVar(&f.passwordEnv, "mail-password-env", "", "mail checks: environment variable holding the password on each node")
+	cmd.Flags().StringVar(&f.passwordFile, "mail-password-file", "", "mail checks: file holding the password on each node")
+	cmd.Flags().StringVar(&f.tls, "mail-tls", "", "mail checks: none, starttls or implicit (default implicit on 465/993/995, else STARTTLS if offered)")
+	cmd.Flags().StringVar(&f.serverName, "mail-server-name", "", "mail checks: TLS server name (default target host)")
+	cmd.Flags().BoolVar(&f.skipVerify, "mail-skip-verify", false, "mail checks: accept any server certificate")
+	cmd.Flags().StringVar(&f.helo, "helo", "", "SMTP checks: EHLO name (default localhost)")
+	cmd.Flags().StringVar(&f.mailbox, "mailbox", "", "IMAP checks: mailbox to examine after login (default INBOX)")
+
+	cmd.Flags().StringVar(&f.rtFrom, "round-trip-from", "", "SMTP checks: send a probe from this address and wait for it over IMAP")
+	cmd.Flags().StringVar(&f.rtTo, "round-trip-to", "", "SMTP round trip: recipient address")
+	cmd.Flags().StringVar(&f.imapHost, "imap-host", "", "SMTP round trip: IMAP server holding the recipient's mailbox")
+	cmd.Flags().IntVar(&f.imapPort, "imap-port", 0, "SMTP round trip: IMAP port (default 993)")
+	cmd.Flags().StringVar(&f.imapTLS, "imap-tls", "", "SMTP round trip: IMAP TLS mode (default implicit)")
+	cmd.Flags().StringVar(&f.imapUser, "imap-user", "", "SMTP round trip: IMAP username")
+	cmd.Flags().StringVar(&f.imapPassword, "imap-password", "", "SMTP round trip: IMAP password, stored with the monitor")
+	cmd.Flags().StringVar(&f.imapPassEnv, "imap-password-env", "", "SMTP round trip: environment variable holding the IMAP password")
+	cmd.Flags().StringVar(&f.imapPassFile, "imap-password-file", "", "SMTP round trip: file holding the IMAP password")
+	cmd.Flags().BoolVar(&f.keepMessages, "keep-probes", false, "SMTP round trip: leave delivered probes in the mailbox")
+}
+
+// options returns the mail options described by the flags, or nil if none were set.
+func (f *mailFlags) options() *model.MailOptions {
+	opts := &model.MailOptions{
+		Credentials: model.Credentials{
+			Username:     f.username,
+			Password:     f.password,
+			PasswordEnv:  f.passwordEnv,
+			PasswordFile: f.passwordFile,
+		},
+		TLS:        f.tls,
+		ServerName: f.serverName,
+		SkipVerify: f.skipVerify,
+		HeloName:   f.helo,
+		Mailbox:    f.mailbox,
+	}
+	if f.rtFrom != "" || f.rtTo != "" || f.imapHost != "" {
+		opts.RoundTrip = &model.MailRoundTrip{
+			From:     f.rtFrom,
+			To:       f.rtTo,
+			IMAPHost: f.imapHost,
+			IMAPPort: f.imapPort,
+			IMAPTLS:  f.imapTLS,
+			IMAP: model.Credentials{
+				Username:     f.imapUser,
+				Password:     f.imapPassword,
+				PasswordEnv:  f.imapPassEnv,
+				PasswordFile: f.imapPassFile,
+			},
+			KeepMessages: f.keepMessages,
+		}
+	}
+	if *opts == (model.MailOptions{}) {
+		return nil
+	}
+	return opts
+}
+
+// domainFlags holds the domain registration options accepted by "monitor add".
+type domainFlags struct {
+	warnDays    int
+	nameservers []string
+	status      []string
+	rdapServer  string
+}
+
+func (f *domainFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.warnDays, "domain-warn-days", 0, "domain checks: degraded within this many days of expiry (default 30)")
+	cmd.Flags().StringArrayVar(&f.nameservers, "expect-ns", nil, "domain checks: expected nameserver (repeatable); any other set is down")
+	cmd.Flags().StringArrayVar(&f.status, "expect-status", nil, "domain checks: expected registry status flag, e.g. clientTransferProhibited (repeatable); any other set is down")
+	cmd.Flags().StringVar(&f.rdapServer, "rdap-server", "", "domain checks: RDAP base URL (default from the IANA bootstrap registry)")
+}
+
+// options returns the domain options described by the flags, or nil if none were set.
+func (f *domainFlags) options() *model.DomainOptions {
+	if f.warnDays == 0 && len(f.nameservers) == 0 && len(f.status) == 0 && f.rdapServer == "" {
+		return nil
+	}
+	return &model.DomainOptions{
+		ExpiryWarnDays: f.warnDays,
+		Nameservers:    f.nameservers,
+		Status:         f.status,
+		RDAPServer:     f.rdapServer,
+	}
+}
+
+// udpFlags holds the udp, ntp, snmp and radius options accepted by "monitor add".
+type udpFlags struct {
+	send       string
+	expect     string
+	regex      bool
+	secret     string
+	secretEnv  string
+	secretFile string
+	warnOffset float64
+	maxOffset  float64
+	maxStratum int
+	oid        string
+}
+
+func (f *udpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.send, "udp-send", "", "UDP checks: datagram to send, with Go escapes such as \\x00")
+	cmd.Flags().StringVar(&f.expect, "udp-expect", "", "UDP checks: text the reply must contain; SNMP checks: expected value")
+	cmd.Flags().BoolVar(&f.regex, "udp-expect-regex", false, "UDP and SNMP checks: treat --udp-expect as a regular expression")
+	cmd.Flags().StringVar(&f.secret, "secret", "", "SNMP community (default public) or RADIUS shared secret, stored with the monitor")
+	cmd.Flags().StringVar(&f.secretEnv, "secret-env", "", "SNMP and RADIUS checks: environment variable holding the secret on each node")
+	cmd.Flags().StringVar(&f.secretFile, "secret-file", "", "SNMP and RADIUS checks: file holding the secret on each node")
+	cmd.Flags().Float64Var(&f.warnOffset, "warn-offset", 0, "NTP checks: clock offset in ms that marks the check degraded")
+	cmd.Flags().Float64Var(&f.maxOffset, "max-offset", 0, "NTP checks: clock offset in ms that marks the check down")
+	cmd.Flags().IntVar(&f.maxStratum, "max-stratum", 0, "NTP checks: highest acceptable stratum (default 15)")
+	cmd.Flags().StringVar(&f.oid, "oid", "", "SNMP checks: OID to GET (default sysUpTime.0, 1.3.6.1.2.1.1.3.0)")
+}
+
+// options returns the UDP options described by the flags, or nil if none were set.
+func (f *udpFlags) options() (*model.UDPOptions, error) {
+	opts := &model.UDPOptions{
+		Credentials: model.Credentials{
+			Password:     f.secret,
+			PasswordEnv:  f.secretEnv,
+			PasswordFile: f.secretFile,
+		},
+		Expect:       f.expect,
+		WarnOffsetMS: f.warnOffset,
+		MaxOffsetMS:  f.maxOffset,
+		MaxStratum:   f.maxStratum,
+		OID:          f.oid,
+	}
+	if f.send != "" {
+		payload, err := strconv.Unquote(`"` + strings.ReplaceAll(f.send, `"`, `\"`) + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --udp-send %q: %w", f.send, err)
+		}
+		opts.Send = payload
+	}
+	if f.regex {
+		opts.Match = "regex"
+	}
+	if *opts == (model.UDPOptions{}) {
+		return nil, nil
+	}
+	return opts, nil
+}
+
+// streamFlags holds the websocket and sse options accepted by "monitor add".
+type streamFlags struct {
+	send   string
+	expect string
+	regex  bool
+	event  string
+	wait   string
+}
+
+func (f *streamFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.send, "ws-send", "", "WebSocket checks: text message to send once connected")
+	cmd.Flags().StringVar(&f.expect, "stream-expect", "", "WebSocket and SSE checks: text a message must contain (default any message)")
+	cmd.Flags().BoolVar(&f.regex, "stream-expect-regex", false, "WebSocket and SSE checks: treat --stream-expect as a regular expression")
+	cmd.Flags().StringVar(&f.event, "sse-event", "", "SSE checks: event type to wait for (default any)")
+	cmd.Flags().StringVar(&f.wait, "wait", "", "WebSocket and SSE checks: how long to wait for the message once connected (default the rest of --timeout)")
+}
+
+// options returns the stream options described by the flags, or nil if none were set.
+func (f *streamFlags) options() (*model.StreamOptions, error) {
+	if f.send == "" && f.expect == "" && !f.regex && f.event == "" && f.wait == "" {
+		return nil, nil
+	}
+	opts := &model.StreamOptions{
+		Send:   f.send,
+		Expect: f.expect,
+		Event:  f.event,
+	}
+	if f.regex {
+		opts.Match = "regex"
+	}
+	if f.wait != "" {
+		ms, err := parseDurationMS(f.wait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wait: %w", err)
+		}
+		opts.WaitMS = ms
+	}
+	return opts, nil
+}
+
+// contentFlags holds the content-change options accepted by "monitor add".
+type contentFlags struct {
+	enabled    bool
+	similarity float64
+	ignore     []string
+}
+
+func (f *contentFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.enabled, "content-change", false, "http_keyword checks: alert when the page content changes from the first version seen")
+	cmd.Flags().Float64Var(&f.similarity, "content-similarity", 0, "http_keyword checks: percentage of the content that must be unchanged (default 90)")
+	cmd.Flags().StringArrayVar(&f.ignore, "content-ignore", nil, "http_keyword checks: regular expression for text to ignore when comparing, such as dates (repeatable)")
+}
+
+// options returns the content options described by the flags, or nil if none were set.
+func (f *contentFlags) options() *model.ContentOptions {
+	if !f.enabled && f.similarity == 0 && len(f.ignore) == 0 {
+		return nil
+	}
+	return &model.ContentOptions{
+		MinSimilarity: f.similarity,
+		Ignore:        f.ignore,
+	}
+}
+
+// assetFlags holds the page asset options accepted by "monitor add".
+type assetFlags struct {
+	enabled    bool
+	maxAssets  int
+	skipImages bool
+	maxWeight  int
+}
+
+func (f *assetFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.enabled, "check-assets", false, "HTTP checks: fetch the scripts, stylesheets and images an HTML page links to, degraded if any is broken")
+	cmd.Flags().IntVar(&f.maxAssets, "max-assets", 0, "HTTP checks: most assets to fetch (default 50)")
+	cmd.Flags().BoolVar(&f.skipImages, "skip-images", false, "HTTP checks: fetch scripts and stylesheets only")
+	cmd.Flags().IntVar(&f.maxWeight, "max-page-weight", 0, "HTTP checks: total KB of the page and its assets above which the check is degraded")
+}
+
+// options returns the asset options described by the flags, or nil if none were set.
+func (f *assetFlags) options() *model.AssetOptions {
+	if !f.enabled && f.maxAssets == 0 && !f.skipImages && f.maxWeight == 0 {
+		return nil
+	}
+	return &model.AssetOptions{
+		MaxAssets:   f.maxAssets,
+		SkipImages:  f.skipImages,
+		MaxWeightKB: f.maxWeight,
+	}
+}
+
+// degradedFlags holds the degraded-incident options accepted by "monitor add".
+type degradedFlags struct {
+	incidents  bool
+	quorumType string
+	quorumN    int
+	severity   string
+}
+
+func (f *degradedFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.incidents, "degraded-incidents", false, "open separate incidents when a quorum reports degraded")
+	cmd.Flags().StringVar(&f.quorumType, "degraded-quorum", "", "quorum for degraded incidents (majority, n_of_m, regions; default: monitor quorum)")
+	cmd.Flags().IntVar(&f.quorumN, "degraded-quorum-n", 0, "required node weight for n_of_m, or distinct regions for regions, when --degraded-quorum is set")
+	cmd.Flags().StringVar(&f.severity, "degraded-severity", "", "alert severity for degraded incidents (warning, critical; default warning)")
+}
+
+// options returns the degraded-incident options, or nil if not enabled.
+func (f *degradedFlags) options() *model.DegradedOptions {
+	if !f.incidents {
+		return nil
+	}
+	return &model.DegradedOptions{
+		Incidents:  true,
+		QuorumType: f.quorumType,
+		QuorumN:    f.quorumN,
+		Severity:   f.severity,
+	}
+}
+
+// parseAssertion parses an --assert flag of the form
+// "SOURCE[:PROPERTY] OPERATOR [VALUE]", for example:
+//
+//	body not_contains Internal Server Error
+//	header:Content-Type contains application/json
+//	json:$.status equals ok
+func parseAssertion(spec string) (model.Assertion, error) {
+	fields := strings.SplitN(strings.TrimSpace(spec), " ", 3)
+	if len(fields) < 2 {
+		return model.Assertion{}, fmt.Errorf("invalid assertion %q (expected 'SOURCE[:PROPERTY] OPERATOR [VALUE]')", spec)
+	}
+
+	source, property, _ := strings.Cut(fields[0], ":")
+	a := model.Assertion{
+		Source:   source,
+		Property: property,
+		Operator: fields[1],
+	}
+	if len(fields) == 3 {
+		a.Value = fields[2]
+	}
+	return a, nil
+}
+
+// parsePhaseThresholds combines --phase-warn and --phase-critical values,
+// each "PHASE=MS", into per-phase thresholds.
+func parsePhaseThresholds(warn, crit []string) (map[string]model.PhaseThreshold, error) {
+	if len(warn) == 0 && len(crit) == 0 {
+		return nil, nil
+	}
+	phases := map[string]model.PhaseThreshold{}
+	for _, set := range []struct {
+		flag  string
+		specs []string
+	}{{"--phase-warn", warn}, {"--phase-critical", crit}} {
+		for _, spec := range set.specs {
+			phase, value, ok := strings.Cut(spec, "=")
+			ms, err := strconv.ParseFloat(strings.TrimSuffix(value, "ms"), 64)
+			if !ok || err != nil {
+				return nil, fmt.Errorf("invalid %s %q (expected PHASE=MS, e.g. ttfb=500)", set.flag, spec)
+			}
+			t := phases[phase]
+			if set.flag == "--phase-warn" {
+				t.WarnMS = ms
+			} else {
+				t.CriticalMS = ms
+			}
+			phases[phase] = t
+		}
+	}
+	return phases, nil
+}
+
+// httpFlags holds the HTTP request options accepted by "monitor add".
+type httpFlags struct {
+	method         string
+	headers        []string
+	body           string
+	basicAuth      string
+	bearerToken    string
+	noFollow       bool
+	acceptedStatus string
+}
+
+func (f *httpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.method, "method", "", "HTTP method (default GET)")
+	cmd.Flags().StringArrayVar(&f.headers, "header", nil, "request header as 'Name: value' (repeatable)")
+	cmd.Flags().StringVar(&f.body, "body", "", "request body")
+	cmd.Flags().StringVar(&f.basicAuth, "basic-auth", "", "basic auth credentials as 'user:password'")
+	cmd.Flags().StringVar(&f.bearerToken, "bearer-token", "", "bearer token for the Authorization header")
+	cmd.Flags().BoolVar(&f.noFollow, "no-follow-redirects", false, "do not follow HTTP redirects")
+	cmd.Flags().StringVar(&f.acceptedStatus, "accept-status", "", "accepted status codes, e.g. 200-299,301")
+}
+
+// options returns the HTTP options described by the flags, or nil if none were set.
+func (f *httpFlags) options() (*model.HTTPOptions, error) {
+	opts := &model.HTTPOptions{
+		Method:         f.method,
+		Body:           f.body,
+		BearerToken:    f.bearerToken,
+		AcceptedStatus: f.acceptedStatus,
+	}
+
+	for _, h := range f.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q (expected 'Name: value')", h)
+		}
+		if opts.Headers == nil {
+			opts.Headers = make(map[string]string)
+		}
+		opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	if f.basicAuth != "" {
+		user, pass, _ := strings.Cut(f.basicAuth, ":")
+		opts.BasicAuthUser = user
+		opts.BasicAuthPassword = pass
+	}
+
+	if f.noFollow {
+		follow := false
+		opts.FollowRedirects = &follow
+	}
+
+	if opts.Method == "" && len(opts.Headers) == 0 && opts.Body == "" && opts.BasicAuthUser == "" &&
+		opts.BearerToken == "" && opts.FollowRedirects == nil && opts.AcceptedStatus == "" {
+		return nil, nil
+	}
+	return opts, nil
+}
+
 func newMonitorShowCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "show <id>",
@@ -223,8 +1223,266 @@ func newMonitorShowCmd() *cobra.Command {
 			fmt.Printf("Retries:           %d\n", m.Retries)
 			fmt.Printf("Failure Threshold: %d\n", m.FailureThreshold)
 			fmt.Printf("Recovery Threshold:%d\n", m.RecoveryThreshold)
-			fmt.Printf("Quorum:            %s\n", m.QuorumType)
+			switch m.QuorumType {
+			case "n_of_m":
+				fmt.Printf("Quorum:            n_of_m (%d)\n", m.QuorumN)
+			case "regions":
+				fmt.Printf("Quorum:            %d regions\n", m.QuorumN)
+			default:
+				fmt.Printf("Quorum:            %s\n", m.QuorumType)
+			}
 			fmt.Printf("Enabled:           %v\n", m.Enabled)
+			if m.Options != nil && m.Options.HTTP != nil {
+				h := m.Options.HTTP
+				if h.Method != "" {
+					fmt.Printf("HTTP Method:       %s\n", h.Method)
+				}
+				if len(h.Headers) > 0 {
+					fmt.Printf("HTTP Headers:      %d\n", len(h.Headers))
+				}
+				if h.AcceptedStatus != "" {
+					fmt.Printf("Accepted Status:   %s\n", h.AcceptedStatus)
+				}
+				if h.FollowRedirects != nil && !*h.FollowRedirects {
+					fmt.Printf("Follow Redirects:  false\n")
+				}
+			}
+			if m.Options != nil && m.Options.DNS != nil {
+				if len(m.Options.DNS.Resolvers) > 0 {
+					fmt.Printf("Resolvers:         %s\n", strings.Join(m.Options.DNS.Resolvers, ", "))
+				}
+				if m.Options.DNS.Transport != "" {
+					fmt.Printf("DNS Transport:     %s\n", m.Options.DNS.Transport)
+				}
+				if m.Options.DNS.Authoritative {
+					fmt.Printf("Authoritative:     yes\n")
+				}
+				if len(m.Options.DNS.Expected) > 0 {
+					fmt.Printf("DNS Expected:      %s\n", strings.Join(m.Options.DNS.Expected, ", "))
+				}
+				if m.Options.DNS.Match != "" {
+					fmt.Printf("DNS Match:         %s\n", m.Options.DNS.Match)
+				}
+				if m.Options.DNS.DNSSEC {
+					fmt.Printf("DNSSEC:            required\n")
+				}
+			}
+			if m.Options != nil && m.Options.Traceroute != nil {
+				t := m.Options.Traceroute
+				if t.Protocol != "" {
+					fmt.Printf("Trace Protocol:    %s\n", t.Protocol)
+				}
+				if t.NoCapture {
+					fmt.Printf("Path Capture:      off\n")
+				}
+			}
+			if m.Options != nil && m.Options.GRPC != nil {
+				g := m.Options.GRPC
+				if g.Service != "" {
+					fmt.Printf("gRPC Service:      %s\n", g.Service)
+				}
+				if g.TLS {
+					mode := "tls"
+					if g.ClientCert != "" {
+						mode = "mtls"
+					}
+					fmt.Printf("gRPC Transport:    %s\n", mode)
+				}
+			}
+			if m.Options != nil && m.Options.Database != nil {
+				d := m.Options.Database
+				if d.Username != "" {
+					fmt.Printf("DB User:           %s\n", d.Username)
+				}
+				switch {
+				case d.PasswordEnv != "":
+					fmt.Printf("DB Password:       $%s\n", d.PasswordEnv)
+				case d.PasswordFile != "":
+					fmt.Printf("DB Password:       %s\n", d.PasswordFile)
+				case d.Password != "":
+					fmt.Printf("DB Password:       (configured)\n")
+				}
+				if d.Database != "" {
+					fmt.Printf("Database:          %s\n", d.Database)
+				}
+				if d.Query != "" {
+					fmt.Printf("Query:             %s\n", d.Query)
+				}
+				if d.Replication {
+					fmt.Printf("Replication:       reported\n")
+				}
+			}
+			if m.Options != nil && m.Options.HTTPFlow != nil {
+				for i, step := range m.Options.HTTPFlow.Steps {
+					method := step.Method
+					if method == "" {
+						method = http.MethodGet
+					}
+					label := fmt.Sprintf("Step %d:", i+1)
+					fmt.Printf("%-19s%s %s", label, strings.ToUpper(method), step.URL)
+					if step.Name != "" {
+						fmt.Printf(" (%s)", step.Name)
+					}
+					fmt.Println()
+				}
+			}
+			if m.Options != nil && m.Options.Exec != nil {
+				fmt.Printf("Command:           %s\n", strings.Join(append([]string{m.Options.Exec.Command}, m.Options.Exec.Args...), " "))
+				if len(m.Options.Exec.Env) > 0 {
+					fmt.Printf("Exec Env:          %d\n", len(m.Options.Exec.Env))
+				}
+			}
+			if m.Options != nil && m.Options.Domain != nil {
+				d := m.Options.Domain
+				if d.ExpiryWarnDays > 0 {
+					fmt.Printf("Domain Warn:       %d days\n", d.ExpiryWarnDays)
+				}
+				if len(d.Nameservers) > 0 {
+					fmt.Printf("Nameservers:       %s\n", strings.Join(d.Nameservers, ", "))
+				}
+				if len(d.Status) > 0 {
+					fmt.Printf("Domain Status:     %s\n", strings.Join(d.Status, ", "))
+				}
+				if d.RDAPServer != "" {
+					fmt.Printf("RDAP Server:       %s\n", d.RDAPServer)
+				}
+			}
+			if m.Options != nil && m.Options.Push != nil {
+				if m.Options.Push.Token == model.RedactedSecret {
+					fmt.Printf("Push URL:          hidden; printed by monitor add\n")
+				} else {
+					fmt.Printf("Push URL:          %s\n", pushURL(cfg, m.Options.Push.Token))
+				}
+				if m.Options.Push.GraceMS > 0 {
+					fmt.Printf("Grace:             %dms\n", m.Options.Push.GraceMS)
+				}
+			}
+			if m.Options != nil && m.Options.Mail != nil {
+				ml := m.Options.Mail
+				if ml.Username != "" {
+					fmt.Printf("Mail User:         %s\n", ml.Username)
+				}
+				switch {
+				case ml.PasswordEnv != "":
+					fmt.Printf("Mail Password:     $%s\n", ml.PasswordEnv)
+				case ml.PasswordFile != "":
+					fmt.Printf("Mail Password:     %s\n", ml.PasswordFile)
+				case ml.Password != "":
+					fmt.Printf("Mail Password:     (configured)\n")
+				}
+				if ml.TLS != "" {
+					fmt.Printf("Mail TLS:          %s\n", ml.TLS)
+				}
+				if rt := ml.RoundTrip; rt != nil {
+					fmt.Printf("Round Trip:        %s -> %s via %s\n", rt.From, rt.To, rt.IMAPHost)
+				}
+			}
+			if m.Options != nil && m.Options.UDP != nil {
+				u := m.Options.UDP
+				if u.Send != "" {
+					fmt.Printf("UDP Send:          %q\n", u.Send)
+				}
+				if u.Expect != "" {
+					fmt.Printf("UDP Expect:        %s\n", u.Expect)
+				}
+				switch {
+				case u.PasswordEnv != "":
+					fmt.Printf("Secret:            $%s\n", u.PasswordEnv)
+				case u.PasswordFile != "":
+					fmt.Printf("Secret:            %s\n", u.PasswordFile)
+				case u.Password != "":
+					fmt.Printf("Secret:            (configured)\n")
+				}
+				if u.WarnOffsetMS > 0 {
+					fmt.Printf("Offset Warn:       %gms\n", u.WarnOffsetMS)
+				}
+				if u.MaxOffsetMS > 0 {
+					fmt.Printf("Offset Max:        %gms\n", u.MaxOffsetMS)
+				}
+				if u.MaxStratum > 0 {
+					fmt.Printf("Max Stratum:       %d\n", u.MaxStratum)
+				}
+				if u.OID != "" {
+					fmt.Printf("OID:               %s\n", u.OID)
+				}
+			}
+			if m.Options != nil && m.Options.Content != nil {
+				similarity := m.Options.Content.MinSimilarity
+				if similarity == 0 {
+					similarity = 90
+				}
+				fmt.Printf("Content Change:    below %g%% similar\n", similarity)
+				for _, pattern := range m.Options.Content.Ignore {
+					fmt.Printf("Content Ignore:    %s\n", pattern)
+				}
+			}
+			if m.Options != nil && m.Options.Assets != nil {
+				a := m.Options.Assets
+				maxAssets := a.MaxAssets
+				if maxAssets == 0 {
+					maxAssets = 50
+				}
+				kinds := "scripts, stylesheets, images"
+				if a.SkipImages {
+					kinds = "scripts, stylesheets"
+				}
+				fmt.Printf("Page Assets:       up to %d (%s)\n", maxAssets, kinds)
+				if a.MaxWeightKB > 0 {
+					fmt.Printf("Max Page Weight:   %d KB\n", a.MaxWeightKB)
+				}
+			}
+			if m.Options != nil && m.Options.Stream != nil {
+				st := m.Options.Stream
+				if st.Send != "" {
+					fmt.Printf("Stream Send:       %s\n", st.Send)
+				}
+				if st.Event != "" {
+					fmt.Printf("SSE Event:         %s\n", st.Event)
+				}
+				if st.Expect != "" {
+					fmt.Printf("Stream Expect:     %s\n", st.Expect)
+				}
+				if st.WaitMS > 0 {
+					fmt.Printf("Stream Wait:       %dms\n", st.WaitMS)
+				}
+			}
+			if m.Options != nil && m.Options.Latency != nil {
+				l := m.Options.Latency
+				if l.WarnMS > 0 || l.CriticalMS > 0 {
+					fmt.Printf("Latency Warn:      %.0fms\n", l.WarnMS)
+					fmt.Printf("Latency Critical:  %.0fms\n", l.CriticalMS)
+				}
+				for _, phase := range model.HTTPPhases {
+					t, ok := l.Phases[phase]
+					if !ok {
+						continue
+					}
+					var limits []string
+					if t.WarnMS > 0 {
+						limits = append(limits, fmt.Sprintf("warn %gms", t.WarnMS))
+					}
+					if t.CriticalMS > 0 {
+						limits = append(limits, fmt.Sprintf("critical %gms", t.CriticalMS))
+					}
+					label := fmt.Sprintf("Phase %s:", strings.ToUpper(phase))
+					fmt.Printf("%-19s%s\n", label, strings.Join(limits, ", "))
+				}
+			}
+			if m.Options != nil && m.Options.AddressFamily != "" {
+				fmt.Printf("Address Family:    %s\n", m.Options.AddressFamily)
+			}
+			if m.Options != nil && m.Options.Degraded != nil && m.Options.Degraded.Incidents {
+				fmt.Printf("Degraded Alerts:   %s\n", m.Options.Degraded.Severity)
+			}
+			if m.Options != nil {
+				for _, a := range m.Options.Assertions {
+					source := a.Source
+					if a.Property != "" {
+						source += ":" + a.Property
+					}
+					fmt.Printf("Assertion:         %s %s %s\n", source, a.Operator, a.Value)
+				}
+			}
 
 			return nil
 		},
@@ -272,6 +1530,10 @@ func newMonitorEditCmd() *cobra.Command {
 			if resp.StatusCode == http.StatusNotFound {
 				return fmt.Errorf("monitor not found: %s", args[0])
 			}
+			if resp.StatusCode != http.StatusOK {
+				respBody, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to update monitor: %s", string(respBody))
+			}
 
 			fmt.Println("Monitor updated.")
 			return nil
@@ -285,6 +1547,37 @@ func newMonitorEditCmd() *cobra.Command {
 	return cmd
 }
 
+func newMonitorAcceptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "accept <id>",
+		Short: "Accept a monitor's changed content or DNS answers as its new baseline",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(dataDir)
+			if err != nil {
+				return err
+			}
+
+			resp, err := http.Post(fmt.Sprintf("http://%s/api/v1/monitors/%s/accept-baseline", cfg.CLIAddr, args[0]), "application/json", nil)
+			if err != nil {
+				return fmt.Errorf("connecting to agent: %w (is the agent running?)", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("monitor not found: %s", args[0])
+			}
+			if resp.StatusCode != http.StatusOK {
+				respBody, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to accept baseline: %s", string(respBody))
+			}
+
+			fmt.Println("Baseline accepted; nodes relearn it on their next check.")
+			return nil
+		},
+	}
+}
+
 func newMonitorDeleteCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "delete <id>",
@@ -335,6 +1628,12 @@ func parseDurationMS(s string) (int64, error) {
 		_, err := fmt.Sscanf(s, "%d", &sec)
 		return sec * 1000, err
 	}
+	if strings.HasSuffix(s, "h") {
+		s = strings.TrimSuffix(s, "h")
+		var hours int64
+		_, err := fmt.Sscanf(s, "%d", &hours)
+		return hours * 3600000, err
+	}
 	if strings.HasSuffix(s, "m") {
 		s = strings.TrimSuffix(s, "m")
 		var min int64
@@ -345,3 +1644,34 @@ func parseDurationMS(s string) (int64, error) {
 	_, err := fmt.Sscanf(s, "%d", &ms)
 	return ms, err
 }
+
+// pushURL builds the URL jobs ping for a push monitor, using this node's
+// peer API address. Any node's peer or CLI API accepts the same path.
+func pushURL(cfg *config.Config, token string) string {
+	host, port, err := net.SplitHostPort(cfg.ListenAddr)
+	if err != nil {
+		return "/api/v1/push/" + token
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if h, err := os.Hostname(); err == nil {
+			host = h
+		}
+	}
+	return fmt.Sprintf("http://%s/api/v1/push/%s", net.JoinHostPort(host, port), token)
+}
+
+// readFlowFile loads an http_flow definition. Unknown fields are rejected
+// so a misspelt key doesn't silently drop a step's settings.
+func readFlowFile(path string) (*model.HTTPFlowOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading flow file: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var flow model.HTTPFlowOptions
+	if err := dec.Decode(&flow); err != nil {
+		return nil, fmt.Errorf("parsing flow file %s: %w", path, err)
+	}
+	return &flow, nil
+}
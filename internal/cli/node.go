@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/pingmesh/pingmesh/internal/config"
+	"github.com/pingmesh/pingmesh/internal/consensus"
 	"github.com/pingmesh/pingmesh/internal/model"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +22,7 @@ func newNodeCmd() *cobra.Command {
 	cmd.AddCommand(
 		newNodeListCmd(),
 		newNodeShowCmd(),
+		newNodeEditCmd(),
 		newNodeRemoveCmd(),
 	)
 
@@ -52,9 +55,10 @@ func newNodeListCmd() *cobra.Command {
 				return nil
 			}
 
-			fmt.Printf("%-36s  %-15s  %-12s  %-8s  %s\n", "ID", "NAME", "ROLE", "STATUS", "ADDRESS")
+			fmt.Printf("%-36s  %-15s  %-12s  %-8s  %6s  %5s  %s\n", "ID", "NAME", "ROLE", "STATUS", "WEIGHT", "TRUST", "ADDRESS")
 			for _, n := range nodes {
-				fmt.Printf("%-36s  %-15s  %-12s  %-8s  %s\n", n.ID, n.Name, n.Role, n.Status, n.Address)
+				fmt.Printf("%-36s  %-15s  %-12s  %-8s  %6g  %5.2f  %s\n",
+					n.ID, n.Name, n.Role, n.Status, consensus.NodeWeight(n), consensus.Trust(n), n.Address)
 			}
 
 			return nil
@@ -93,12 +97,73 @@ func newNodeShowCmd() *cobra.Command {
 			fmt.Printf("Address:   %s\n", node.Address)
 			fmt.Printf("Role:      %s\n", node.Role)
 			fmt.Printf("Status:    %s\n", node.Status)
+			fmt.Printf("Weight:    %g\n", consensus.NodeWeight(node))
+			fmt.Printf("Trust:     %.2f (%d down reports, %d not confirmed)\n",
+				consensus.Trust(node), node.DownReports, node.FalsePositives)
 
 			return nil
 		},
 	}
 }
 
+func newNodeEditCmd() *cobra.Command {
+	var (
+		location   string
+		weight     float64
+		resetTrust bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit a node's location or vote weight",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(dataDir)
+			if err != nil {
+				return err
+			}
+
+			updates := model.NodeUpdate{
+				Location:   location,
+				Weight:     weight,
+				ResetTrust: resetTrust,
+			}
+
+			body, _ := json.Marshal(updates)
+			req, err := http.NewRequest(http.MethodPut,
+				fmt.Sprintf("http://%s/api/v1/nodes/%s", cfg.CLIAddr, args[0]),
+				bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("connecting to agent: %w (is the agent running?)", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("node not found: %s", args[0])
+			}
+			if resp.StatusCode != http.StatusOK {
+				respBody, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to update node: %s", string(respBody))
+			}
+
+			fmt.Println("Node updated.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&location, "location", "", "new location label")
+	cmd.Flags().Float64Var(&weight, "weight", 0, "vote weight in quorum evaluation (default 1)")
+	cmd.Flags().BoolVar(&resetTrust, "reset-trust", false, "forget the node's report history, restoring full trust")
+
+	return cmd
+}
+
 func newNodeRemoveCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "remove <id>",
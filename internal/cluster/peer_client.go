@@ -17,6 +17,9 @@ type PeerClient struct {
 	client *http.Client
 }
 
+// maxTraceWait bounds how long RequestTrace waits for a peer's traceroute.
+const maxTraceWait = 90 * time.Second
+
 // NewPeerClient creates a new PeerClient with sensible timeouts.
 func NewPeerClient() *PeerClient {
 	return &PeerClient{
@@ -41,6 +44,12 @@ func (c *PeerClient) PushResult(addr string, result *model.CheckResult) error {
 	return c.postJSON(addr, "/api/v1/peer/result", result)
 }
 
+// ForwardPushPing relays a ping received for a push monitor to the
+// coordinator, which evaluates every push monitor.
+func (c *PeerClient) ForwardPushPing(addr string, ping *model.PushPing) error {
+	return c.postJSON(addr, "/api/v1/peer/push-ping", ping)
+}
+
 // PushConfigSync sends a config sync to a peer node.
 func (c *PeerClient) PushConfigSync(addr string, sync *model.ConfigSync) error {
 	return c.postJSON(addr, "/api/v1/peer/config-sync", sync)
@@ -98,6 +107,35 @@ func (c *PeerClient) PullConfigSync(addr string) (*model.ConfigSync, error) {
 	return &sync, nil
 }
 
+// RequestTrace asks a peer to traceroute a monitor's target and returns the
+// path it saw. Traces outlast the default timeout, so the wait is extended.
+func (c *PeerClient) RequestTrace(addr string, req *model.PeerTraceRequest) (*model.TraceResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling trace request: %w", err)
+	}
+
+	client := *c.client
+	client.Timeout = maxTraceWait
+	url := fmt.Sprintf("http://%s/api/v1/peer/traceroute", addr)
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("POST /api/v1/peer/traceroute: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("POST /api/v1/peer/traceroute returned HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var trace model.TraceResult
+	if err := json.NewDecoder(resp.Body).Decode(&trace); err != nil {
+		return nil, fmt.Errorf("decoding trace result: %w", err)
+	}
+	return &trace, nil
+}
+
 func (c *PeerClient) postJSON(addr, path string, v any) error {
 	body, err := json.Marshal(v)
 	if err != nil {
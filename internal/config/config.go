@@ -25,6 +25,9 @@ type Config struct {
 
 	Coordinator *CoordinatorConfig `json:"coordinator,omitempty"`
 	TLS         *TLSConfig         `json:"tls,omitempty"`
+	Exec        *ExecConfig        `json:"exec,omitempty"`
+	Secrets     *SecretsConfig     `json:"secrets,omitempty"`
+	Consensus   *ConsensusConfig   `json:"consensus,omitempty"`
 }
 
 // CoordinatorConfig holds coordinator-specific settings.
@@ -39,6 +42,35 @@ type TLSConfig struct {
 	KeyPath  string `json:"key_path"`
 }
 
+// ExecConfig controls which executables exec checks may run on this node.
+type ExecConfig struct {
+	// Allow lists absolute paths or glob patterns, e.g.
+	// "/usr/lib/nagios/plugins/*". Nothing runs when it is empty.
+	Allow []string `json:"allow"`
+	// MaxOutputBytes caps the stdout and stderr kept from each run,
+	// default 65536.
+	MaxOutputBytes int `json:"max_output_bytes,omitempty"`
+}
+
+// SecretsConfig controls which secrets on this node monitors may read with
+// password_env and password_file, or as {{env.NAME}} in http_flow steps.
+type SecretsConfig struct {
+	// Env lists environment variable names. Nothing is readable when it
+	// is empty.
+	Env []string `json:"env,omitempty"`
+	// Files lists absolute paths or glob patterns, e.g.
+	// "/etc/pingmesh/secrets/*".
+	Files []string `json:"files,omitempty"`
+}
+
+// ConsensusConfig tunes how the coordinator weighs node reports.
+type ConsensusConfig struct {
+	// MinTrust is the trust score, from 0 to 1, below which a node's
+	// failures are ignored while no other node sees them. Default 0.5;
+	// 0 counts every failure.
+	MinTrust *float64 `json:"min_trust,omitempty"`
+}
+
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
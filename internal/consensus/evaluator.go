@@ -1,13 +1,64 @@
 package consensus
 
-// EvaluateQuorum determines if enough nodes agree on a failure.
-func EvaluateQuorum(quorumType string, quorumN int, failCount int, totalCount int) bool {
+import (
+	"strings"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// UnlabelledRegion is the region of nodes without a location.
+const UnlabelledRegion = "unlabelled"
+
+// EvaluateQuorum determines if enough of the online nodes agree on a
+// failure. For majority and n_of_m, votes are weighted per node: a majority
+// needs more than half of the total weight, and n_of_m needs a weight of at
+// least quorumN, which with the default weight of 1 is N nodes. regions
+// needs agreeing nodes in at least quorumN distinct locations, so failures
+// seen from one datacenter alone never confirm.
+func EvaluateQuorum(quorumType string, quorumN int, agreeing, online []model.Node) bool {
 	switch quorumType {
 	case "majority":
-		return failCount > totalCount/2
+		return totalWeight(agreeing) > totalWeight(online)/2
 	case "n_of_m":
-		return failCount >= quorumN
+		return totalWeight(agreeing) >= float64(quorumN)
+	case "regions":
+		return len(GroupByRegion(agreeing)) >= quorumN
 	default:
-		return failCount > totalCount/2
+		return totalWeight(agreeing) > totalWeight(online)/2
+	}
+}
+
+// NodeWeight returns a node's vote weight, treating an unset weight as 1.
+func NodeWeight(n model.Node) float64 {
+	if n.Weight <= 0 {
+		return 1
+	}
+	return n.Weight
+}
+
+func totalWeight(nodes []model.Node) float64 {
+	total := 0.0
+	for _, n := range nodes {
+		total += NodeWeight(n)
+	}
+	return total
+}
+
+// NodeRegion returns the region a node votes from: its location label, or
+// UnlabelledRegion.
+func NodeRegion(n model.Node) string {
+	if loc := strings.TrimSpace(n.Location); loc != "" {
+		return loc
+	}
+	return UnlabelledRegion
+}
+
+// GroupByRegion returns the IDs of nodes by region.
+func GroupByRegion(nodes []model.Node) map[string][]string {
+	regions := make(map[string][]string)
+	for _, n := range nodes {
+		region := NodeRegion(n)
+		regions[region] = append(regions[region], n.ID)
 	}
+	return regions
 }
@@ -0,0 +1,60 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+func TestEvaluateQuorum(t *testing.T) {
+	online := []model.Node{
+		{ID: "a", Location: "eu-west"},
+		{ID: "b", Location: "eu-west"},
+		{ID: "c", Location: "us-east", Weight: 2},
+		{ID: "d"},
+	}
+	pick := func(ids ...string) []model.Node {
+		var nodes []model.Node
+		for _, n := range online {
+			for _, id := range ids {
+				if n.ID == id {
+					nodes = append(nodes, n)
+				}
+			}
+		}
+		return nodes
+	}
+
+	tests := []struct {
+		name       string
+		quorumType string
+		quorumN    int
+		agreeing   []model.Node
+		want       bool
+	}{
+		{"majority of weight", "majority", 0, pick("a", "c"), true},
+		{"two nodes of five weight are no majority", "majority", 0, pick("a", "b"), false},
+		{"heavy node alone is no majority", "majority", 0, pick("c"), false},
+		{"unknown type falls back to majority", "", 0, pick("a", "b", "c"), true},
+		{"n_of_m counts weight", "n_of_m", 3, pick("a", "c"), true},
+		{"n_of_m short of weight", "n_of_m", 3, pick("a", "b"), false},
+		{"regions in one location", "regions", 2, pick("a", "b"), false},
+		{"regions across locations", "regions", 2, pick("a", "c"), true},
+		{"unlabelled counts as one region", "regions", 3, pick("a", "c", "d"), true},
+		{"regions short", "regions", 3, pick("a", "b", "c"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EvaluateQuorum(tt.quorumType, tt.quorumN, tt.agreeing, online); got != tt.want {
+				t.Errorf("EvaluateQuorum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByRegion(t *testing.T) {
+	regions := GroupByRegion([]model.Node{{ID: "a", Location: " eu-west "}, {ID: "b"}, {ID: "c", Location: "eu-west"}})
+	if len(regions) != 2 || len(regions["eu-west"]) != 2 || len(regions[UnlabelledRegion]) != 1 {
+		t.Errorf("GroupByRegion() = %v", regions)
+	}
+}
@@ -19,9 +19,10 @@ func NewIncidentManager(st store.Store) *IncidentManager {
 	return &IncidentManager{store: st}
 }
 
-// GetOrCreateIncident returns the active incident for a monitor, or creates a new one.
-func (m *IncidentManager) GetOrCreateIncident(monitorID string) (*model.Incident, error) {
-	incident, err := m.store.GetActiveIncident(monitorID)
+// GetOrCreateIncident returns the active incident of the given kind for a
+// monitor, or creates a new one with the given severity.
+func (m *IncidentManager) GetOrCreateIncident(monitorID, kind, severity string) (*model.Incident, error) {
+	incident, err := m.store.GetActiveIncident(monitorID, kind)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +34,8 @@ func (m *IncidentManager) GetOrCreateIncident(monitorID string) (*model.Incident
 	incident = &model.Incident{
 		ID:        uuid.New().String(),
 		MonitorID: monitorID,
+		Kind:      kind,
+		Severity:  severity,
 		Status:    model.IncidentSuspect,
 		StartedAt: now,
 		CreatedAt: now,
@@ -43,20 +46,25 @@ func (m *IncidentManager) GetOrCreateIncident(monitorID string) (*model.Incident
 		return nil, err
 	}
 
-	log.Printf("[incident] created suspect incident %s for monitor %s", incident.ID, monitorID)
+	log.Printf("[incident] created suspect %s incident %s for monitor %s", kind, incident.ID, monitorID)
 	return incident, nil
 }
 
-// ConfirmIncident transitions an incident to confirmed status.
-func (m *IncidentManager) ConfirmIncident(incident *model.Incident, confirmingNodes []string) error {
+// ConfirmIncident transitions an incident to confirmed status, recording
+// the confirming nodes and their regions.
+func (m *IncidentManager) ConfirmIncident(incident *model.Incident, confirming []model.Node) error {
 	now := time.Now().UnixMilli()
 	incident.Status = model.IncidentConfirmed
 	incident.ConfirmedAt = now
-	incident.ConfirmingNodes = confirmingNodes
+	incident.ConfirmingNodes = make([]string, 0, len(confirming))
+	for _, n := range confirming {
+		incident.ConfirmingNodes = append(incident.ConfirmingNodes, n.ID)
+	}
+	incident.ConfirmingRegions = GroupByRegion(confirming)
 	incident.UpdatedAt = now
 
-	log.Printf("[incident] confirmed incident %s for monitor %s by nodes %v",
-		incident.ID, incident.MonitorID, confirmingNodes)
+	log.Printf("[incident] confirmed incident %s for monitor %s by nodes %v in regions %v",
+		incident.ID, incident.MonitorID, incident.ConfirmingNodes, incident.ConfirmingRegions)
 	return m.store.UpdateIncident(incident)
 }
 
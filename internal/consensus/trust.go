@@ -0,0 +1,78 @@
+package consensus
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+// DefaultMinTrust is the trust score below which a node's failures are
+// suppressed when no other node sees them.
+const DefaultMinTrust = 0.5
+
+// trustPrior is the number of agreed reports a node is credited with
+// before it has a history, so a new node starts fully trusted and a
+// single false positive does not condemn it.
+const trustPrior = 5
+
+// Trust returns a node's trust score from 0 to 1: the share of its down
+// reports the quorum agreed with, smoothed towards 1 while the node has
+// made few reports.
+func Trust(n model.Node) float64 {
+	return float64(n.DownReports-n.FalsePositives+trustPrior) / float64(n.DownReports+trustPrior)
+}
+
+// ReportTracker follows each node's down reports for a monitor, from the
+// first evaluation in which the node votes down to the first in which it
+// no longer does, and then records on the node whether the quorum agreed
+// with it at any point. Reports still open when the coordinator restarts
+// are not counted.
+type ReportTracker struct {
+	store store.Store
+
+	mu   sync.Mutex
+	open map[string]map[string]bool // monitor ID -> node ID -> quorum agreed
+}
+
+// NewReportTracker creates a tracker that records outcomes in st.
+func NewReportTracker(st store.Store) *ReportTracker {
+	return &ReportTracker{store: st, open: make(map[string]map[string]bool)}
+}
+
+// Observe records one evaluation of a monitor's down quorum: the online
+// nodes, those voting down and whether the quorum was met.
+func (t *ReportTracker) Observe(monitorID string, onlineNodes []model.Node, failing []string, quorumMet bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := t.open[monitorID]
+	if reports == nil {
+		reports = make(map[string]bool)
+		t.open[monitorID] = reports
+	}
+	for _, id := range failing {
+		reports[id] = reports[id] || quorumMet
+	}
+
+	// Only online nodes close their reports; an offline node's report
+	// stays open until it is back.
+	for _, n := range onlineNodes {
+		agreed, ok := reports[n.ID]
+		if !ok || slices.Contains(failing, n.ID) {
+			continue
+		}
+		delete(reports, n.ID)
+		if !agreed {
+			log.Printf("[consensus] node %s reported monitor %s down without quorum agreement", n.ID, monitorID)
+		}
+		if err := t.store.RecordNodeReport(n.ID, !agreed); err != nil {
+			log.Printf("[consensus] error recording report of node %s: %v", n.ID, err)
+		}
+	}
+	if len(reports) == 0 {
+		delete(t.open, monitorID)
+	}
+}
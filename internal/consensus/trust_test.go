@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+	"github.com/pingmesh/pingmesh/internal/store"
+)
+
+func TestTrust(t *testing.T) {
+	tests := []struct {
+		reports, falsePositives int
+		want                    float64
+	}{
+		{0, 0, 1},
+		{1, 1, 0.8333},
+		{10, 0, 1},
+		{10, 5, 0.6667},
+		{20, 20, 0.2},
+	}
+	for _, tt := range tests {
+		got := Trust(model.Node{DownReports: tt.reports, FalsePositives: tt.falsePositives})
+		if math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("Trust(%d reports, %d false) = %.4f, want %.4f", tt.reports, tt.falsePositives, got, tt.want)
+		}
+	}
+}
+
+func TestReportTrackerObserve(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	nodes := []model.Node{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	for i := range nodes {
+		if err := st.CreateNode(&nodes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracker := NewReportTracker(st)
+	// All three nodes see an outage of m1 the quorum agrees with at some
+	// point. On m2, c alone reports a false positive and goes offline
+	// before it recovers.
+	tracker.Observe("m1", nodes, []string{"a"}, false)
+	tracker.Observe("m1", nodes, []string{"a", "b", "c"}, true)
+	tracker.Observe("m1", nodes, []string{"c"}, false)
+	tracker.Observe("m2", nodes, []string{"c"}, false)
+	tracker.Observe("m2", nodes[:2], nil, false)
+	tracker.Observe("m1", nodes, nil, false)
+
+	want := map[string][2]int{"a": {1, 0}, "b": {1, 0}, "c": {1, 0}}
+	for id, counts := range want {
+		n, _ := st.GetNode(id)
+		if n.DownReports != counts[0] || n.FalsePositives != counts[1] {
+			t.Errorf("node %s counters = %d/%d, want %d/%d", id, n.DownReports, n.FalsePositives, counts[0], counts[1])
+		}
+	}
+
+	// c's report on m2 closes once it is back online.
+	tracker.Observe("m2", nodes, nil, false)
+	n, _ := st.GetNode("c")
+	if n.DownReports != 2 || n.FalsePositives != 1 {
+		t.Errorf("node c counters = %d/%d, want 2/1", n.DownReports, n.FalsePositives)
+	}
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+)
+
+// secret is one stored secret in a monitor's options, read and written in
+// place so it can be redacted wherever it lives.
+type secret struct {
+	get func() string
+	set func(string)
+}
+
+func fieldSecret(p *string) secret {
+	return secret{
+		get: func() string { return *p },
+		set: func(v string) { *p = v },
+	}
+}
+
+// mapSecret is a secret map value, such as a header. Setting it to ""
+// removes the entry.
+func mapSecret(m map[string]string, key string) secret {
+	return secret{
+		get: func() string { return m[key] },
+		set: func(v string) {
+			if v == "" {
+				delete(m, key)
+				return
+			}
+			m[key] = v
+		},
+	}
+}
+
+// secretHeaders are request headers and gRPC metadata whose values are
+// credentials, by canonical name.
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// secrets returns every secret in opts, keyed by its path so secrets in an
+// update can be matched with the stored ones.
+func secrets(opts *MonitorOptions) map[string]secret {
+	found := map[string]secret{}
+	if opts == nil {
+		return found
+	}
+	if opts.Database != nil {
+		found["database.password"] = fieldSecret(&opts.Database.Password)
+	}
+	if opts.Mail != nil {
+		found["mail.password"] = fieldSecret(&opts.Mail.Password)
+		if opts.Mail.RoundTrip != nil {
+			found["mail.round_trip.imap.password"] = fieldSecret(&opts.Mail.RoundTrip.IMAP.Password)
+		}
+	}
+	if opts.UDP != nil {
+		found["udp.password"] = fieldSecret(&opts.UDP.Password)
+	}
+	// Websocket and sse checks authenticate with the HTTP options too.
+	if opts.HTTP != nil {
+		httpSecrets(found, "http", opts.HTTP)
+	}
+	if opts.HTTPFlow != nil {
+		for i := range opts.HTTPFlow.Steps {
+			httpSecrets(found, fmt.Sprintf("http_flow.steps.%d", i), &opts.HTTPFlow.Steps[i].HTTPOptions)
+		}
+	}
+	if opts.GRPC != nil {
+		headerSecrets(found, "grpc.metadata", opts.GRPC.Metadata)
+	}
+	if opts.Exec != nil {
+		for name := range opts.Exec.Env {
+			found["exec.env."+name] = mapSecret(opts.Exec.Env, name)
+		}
+	}
+	if opts.Push != nil {
+		found["push.token"] = fieldSecret(&opts.Push.Token)
+	}
+	return found
+}
+
+// httpSecrets adds the passwords, tokens and credential headers of an HTTP
+// request under prefix.
+func httpSecrets(found map[string]secret, prefix string, h *HTTPOptions) {
+	found[prefix+".basic_auth_password"] = fieldSecret(&h.BasicAuthPassword)
+	found[prefix+".bearer_token"] = fieldSecret(&h.BearerToken)
+	headerSecrets(found, prefix+".headers", h.Headers)
+}
+
+// headerSecrets adds the credential headers among headers under prefix.
+func headerSecrets(found map[string]secret, prefix string, headers map[string]string) {
+	for name := range headers {
+		if canonical := textproto.CanonicalMIMEHeaderKey(name); secretHeaders[canonical] {
+			found[prefix+"."+canonical] = mapSecret(headers, name)
+		}
+	}
+}
+
+// RedactSecrets returns a copy of m that is safe to send to API clients,
+// with stored secrets replaced by RedactedSecret.
+func RedactSecrets(m Monitor) Monitor {
+	return redactSecrets(m, "")
+}
+
+// WithholdSecrets returns a copy of m to send to peers in config sync. The
+// peer API is not authenticated, so inline secrets stay on the coordinator
+// and are replaced by RedactedSecret; nodes read theirs with password_env,
+// password_file or {{env.NAME}}. Push tokens are kept, as every node
+// accepts pings.
+func WithholdSecrets(m Monitor) Monitor {
+	return redactSecrets(m, "push.token")
+}
+
+// redactSecrets replaces the secrets in a copy of m, except the one at
+// path keep.
+func redactSecrets(m Monitor, keep string) Monitor {
+	hasSecret := false
+	for path, s := range secrets(m.Options) {
+		hasSecret = hasSecret || (path != keep && s.get() != "")
+	}
+	if !hasSecret {
+		return m
+	}
+
+	// Deep copy so the caller's options keep the real secrets.
+	var opts MonitorOptions
+	data, _ := json.Marshal(m.Options)
+	json.Unmarshal(data, &opts)
+	for path, s := range secrets(&opts) {
+		if path != keep && s.get() != "" {
+			s.set(RedactedSecret)
+		}
+	}
+	m.Options = &opts
+	return m
+}
+
+// KeepRedactedSecrets restores stored secrets that a client sent back as
+// RedactedSecret, so options read from the API can be edited and saved
+// without re-entering them. Secrets without a stored value are cleared.
+func KeepRedactedSecrets(updated, existing *MonitorOptions) {
+	stored := secrets(existing)
+	for path, s := range secrets(updated) {
+		if s.get() != RedactedSecret {
+			continue
+		}
+		restored := ""
+		if old, ok := stored[path]; ok {
+			restored = old.get()
+		}
+		s.set(restored)
+	}
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+// secretOptions returns options with a secret in every place that holds one.
+func secretOptions() *MonitorOptions {
+	return &MonitorOptions{
+		HTTP: &HTTPOptions{
+			Headers:           map[string]string{"authorization": "Basic abc", "X-Trace": "1"},
+			BasicAuthUser:     "admin",
+			BasicAuthPassword: "http-pass",
+			BearerToken:       "http-token",
+		},
+		HTTPFlow: &HTTPFlowOptions{Steps: []HTTPFlowStep{
+			{Name: "login", HTTPOptions: HTTPOptions{BasicAuthPassword: "step-pass"}},
+			{Name: "api", HTTPOptions: HTTPOptions{Headers: map[string]string{"Cookie": "sid=1"}}},
+		}},
+		GRPC:     &GRPCOptions{Metadata: map[string]string{"authorization": "Bearer grpc", "x-env": "prod"}},
+		Database: &DatabaseOptions{Credentials: Credentials{Username: "monitor", Password: "db-pass"}},
+		Mail: &MailOptions{
+			Credentials: Credentials{Password: "mail-pass"},
+			RoundTrip:   &MailRoundTrip{IMAP: Credentials{Password: "imap-pass"}},
+		},
+		UDP:  &UDPOptions{Credentials: Credentials{Password: "community"}},
+		Exec: &ExecOptions{Command: "/bin/true", Env: map[string]string{"PGPASSWORD": "exec-pass"}},
+		Push: &PushOptions{Token: "0483cab00decc4d21daf30e991ea7524"},
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	m := Monitor{ID: "m1", Options: secretOptions()}
+	redacted := RedactSecrets(m)
+
+	if !reflect.DeepEqual(m.Options, secretOptions()) {
+		t.Fatal("RedactSecrets modified the caller's options")
+	}
+
+	r := RedactedSecret
+	o := redacted.Options
+	tests := []struct {
+		path string
+		got  string
+		want string
+	}{
+		{"http.basic_auth_password", o.HTTP.BasicAuthPassword, r},
+		{"http.bearer_token", o.HTTP.BearerToken, r},
+		{"http.headers.authorization", o.HTTP.Headers["authorization"], r},
+		{"http.headers.X-Trace", o.HTTP.Headers["X-Trace"], "1"},
+		{"http.basic_auth_user", o.HTTP.BasicAuthUser, "admin"},
+		{"http_flow.steps.0.basic_auth_password", o.HTTPFlow.Steps[0].BasicAuthPassword, r},
+		{"http_flow.steps.1.headers.Cookie", o.HTTPFlow.Steps[1].Headers["Cookie"], r},
+		{"grpc.metadata.authorization", o.GRPC.Metadata["authorization"], r},
+		{"grpc.metadata.x-env", o.GRPC.Metadata["x-env"], "prod"},
+		{"database.password", o.Database.Password, r},
+		{"database.username", o.Database.Username, "monitor"},
+		{"mail.password", o.Mail.Password, r},
+		{"mail.round_trip.imap.password", o.Mail.RoundTrip.IMAP.Password, r},
+		{"udp.password", o.UDP.Password, r},
+		{"exec.env.PGPASSWORD", o.Exec.Env["PGPASSWORD"], r},
+		{"push.token", o.Push.Token, r},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.path, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWithholdSecrets(t *testing.T) {
+	synced := WithholdSecrets(Monitor{ID: "m1", Options: secretOptions()}).Options
+	if synced.HTTP.BearerToken != RedactedSecret || synced.Database.Password != RedactedSecret ||
+		synced.Exec.Env["PGPASSWORD"] != RedactedSecret || synced.UDP.Password != RedactedSecret {
+		t.Errorf("inline secrets synced: %+v", synced)
+	}
+	if synced.Push.Token != "0483cab00decc4d21daf30e991ea7524" {
+		t.Errorf("push token = %q, want it kept so every node accepts pings", synced.Push.Token)
+	}
+
+	push := &MonitorOptions{Push: &PushOptions{Token: "0483cab00decc4d21daf30e991ea7524"}}
+	if got := WithholdSecrets(Monitor{Options: push}); got.Options != push {
+		t.Error("options with only a push token should be returned as they are")
+	}
+}
+
+func TestRedactSecretsWithoutSecrets(t *testing.T) {
+	opts := &MonitorOptions{HTTP: &HTTPOptions{Method: "HEAD"}}
+	if got := RedactSecrets(Monitor{Options: opts}); got.Options != opts {
+		t.Error("options without secrets should be returned as they are")
+	}
+}
+
+func TestKeepRedactedSecrets(t *testing.T) {
+	existing := secretOptions()
+
+	// Options read back from the API round-trip to the stored secrets.
+	updated := RedactSecrets(Monitor{Options: existing}).Options
+	KeepRedactedSecrets(updated, existing)
+	if !reflect.DeepEqual(updated, existing) {
+		t.Errorf("round trip = %+v, want %+v", updated, existing)
+	}
+
+	tests := []struct {
+		name    string
+		updated *MonitorOptions
+		check   func(*MonitorOptions) bool
+	}{
+		{
+			"new value replaces the stored one",
+			&MonitorOptions{HTTP: &HTTPOptions{BearerToken: "rotated"}},
+			func(o *MonitorOptions) bool { return o.HTTP.BearerToken == "rotated" },
+		},
+		{
+			"header matched by canonical name",
+			&MonitorOptions{HTTP: &HTTPOptions{Headers: map[string]string{"Authorization": RedactedSecret}}},
+			func(o *MonitorOptions) bool { return o.HTTP.Headers["Authorization"] == "Basic abc" },
+		},
+		{
+			"redacted header without a stored value is dropped",
+			&MonitorOptions{HTTP: &HTTPOptions{Headers: map[string]string{"Proxy-Authorization": RedactedSecret}}},
+			func(o *MonitorOptions) bool { _, ok := o.HTTP.Headers["Proxy-Authorization"]; return !ok },
+		},
+		{
+			"database password",
+			&MonitorOptions{Database: &DatabaseOptions{Credentials: Credentials{Password: RedactedSecret}}},
+			func(o *MonitorOptions) bool { return o.Database.Password == "db-pass" },
+		},
+		{
+			"flow steps are matched by position",
+			&MonitorOptions{HTTPFlow: &HTTPFlowOptions{Steps: []HTTPFlowStep{
+				{HTTPOptions: HTTPOptions{BasicAuthPassword: RedactedSecret}},
+			}}},
+			func(o *MonitorOptions) bool { return o.HTTPFlow.Steps[0].BasicAuthPassword == "step-pass" },
+		},
+		{
+			"exec env value",
+			&MonitorOptions{Exec: &ExecOptions{Env: map[string]string{"PGPASSWORD": RedactedSecret}}},
+			func(o *MonitorOptions) bool { return o.Exec.Env["PGPASSWORD"] == "exec-pass" },
+		},
+		{
+			"push token",
+			&MonitorOptions{Push: &PushOptions{Token: RedactedSecret}},
+			func(o *MonitorOptions) bool { return o.Push.Token == "0483cab00decc4d21daf30e991ea7524" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			KeepRedactedSecrets(tt.updated, existing)
+			if !tt.check(tt.updated) {
+				t.Errorf("unexpected options after restore: %+v", tt.updated)
+			}
+		})
+	}
+
+	cleared := &MonitorOptions{UDP: &UDPOptions{Credentials: Credentials{Password: RedactedSecret}}}
+	KeepRedactedSecrets(cleared, nil)
+	if cleared.UDP.Password != "" {
+		t.Errorf("password without a stored value = %q, want empty", cleared.UDP.Password)
+	}
+}
@@ -15,6 +15,16 @@ type Node struct {
 	Status    string `json:"status"`  // "online", "offline", "suspect"
 	LastSeen  int64  `json:"last_seen"`
 	CreatedAt int64  `json:"created_at"`
+
+	// Weight is the node's vote in quorum evaluation, default 1. A node
+	// with weight 2 counts as two nodes.
+	Weight float64 `json:"weight"`
+
+	// DownReports counts the times the node reported a monitor down, from
+	// its first failing evaluation to its recovery, and FalsePositives
+	// those the quorum never agreed with. They give the node's trust score.
+	DownReports    int `json:"down_reports"`
+	FalsePositives int `json:"false_positives"`
 }
 
 const (
@@ -26,6 +36,13 @@ const (
 	NodeSuspect = "suspect"
 )
 
+// NodeUpdate is the body of a node update. Omitted fields are unchanged.
+type NodeUpdate struct {
+	Location   string  `json:"location,omitempty"`
+	Weight     float64 `json:"weight,omitempty"`
+	ResetTrust bool    `json:"reset_trust,omitempty"` // zero the report counts behind the trust score
+}
+
 // CheckType represents the type of monitoring check.
 type CheckType string
 
@@ -36,31 +53,505 @@ const (
 	CheckHTTPS       CheckType = "https"
 	CheckDNS         CheckType = "dns"
 	CheckHTTPKeyword CheckType = "http_keyword"
+	CheckTLS         CheckType = "tls"
+	CheckTraceroute  CheckType = "traceroute"
+	CheckGRPC        CheckType = "grpc"
+	CheckPostgres    CheckType = "postgres"
+	CheckMySQL       CheckType = "mysql"
+	CheckRedis       CheckType = "redis"
+	CheckSMTP        CheckType = "smtp"
+	CheckIMAP        CheckType = "imap"
+	CheckPOP3        CheckType = "pop3"
+	CheckPush        CheckType = "push"
+	CheckHTTPFlow    CheckType = "http_flow"
+	CheckExec        CheckType = "exec"
+	CheckDomain      CheckType = "domain"
+	CheckUDP         CheckType = "udp"
+	CheckNTP         CheckType = "ntp"
+	CheckSNMP        CheckType = "snmp"
+	CheckRADIUS      CheckType = "radius"
+	CheckWebSocket   CheckType = "websocket"
+	CheckSSE         CheckType = "sse"
 )
 
 // Monitor defines a monitoring check configuration.
 type Monitor struct {
-	ID                string    `json:"id"`
-	Name              string    `json:"name"`
-	GroupName         string    `json:"group_name"`
-	CheckType         CheckType `json:"check_type"`
-	Target            string    `json:"target"`
-	Port              int       `json:"port,omitempty"`
-	IntervalMS        int64     `json:"interval_ms"`
-	TimeoutMS         int64     `json:"timeout_ms"`
-	Retries           int       `json:"retries"`
-	ExpectedStatus    int       `json:"expected_status,omitempty"`
-	ExpectedKeyword   string    `json:"expected_keyword,omitempty"`
-	DNSRecordType     string    `json:"dns_record_type,omitempty"`
-	DNSExpected       string    `json:"dns_expected,omitempty"`
-	FailureThreshold  int       `json:"failure_threshold"`
-	RecoveryThreshold int       `json:"recovery_threshold"`
-	QuorumType        string    `json:"quorum_type"` // "majority" or "n_of_m"
-	QuorumN           int       `json:"quorum_n"`
-	CooldownMS        int64     `json:"cooldown_ms"`
-	Enabled           bool      `json:"enabled"`
-	CreatedAt         int64     `json:"created_at"`
-	UpdatedAt         int64     `json:"updated_at"`
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	GroupName         string          `json:"group_name"`
+	CheckType         CheckType       `json:"check_type"`
+	Target            string          `json:"target"`
+	Port              int             `json:"port,omitempty"`
+	IntervalMS        int64           `json:"interval_ms"`
+	TimeoutMS         int64           `json:"timeout_ms"`
+	Retries           int             `json:"retries"`
+	ExpectedStatus    int             `json:"expected_status,omitempty"`
+	ExpectedKeyword   string          `json:"expected_keyword,omitempty"`
+	DNSRecordType     string          `json:"dns_record_type,omitempty"`
+	DNSExpected       string          `json:"dns_expected,omitempty"`
+	FailureThreshold  int             `json:"failure_threshold"`
+	RecoveryThreshold int             `json:"recovery_threshold"`
+	QuorumType        string          `json:"quorum_type"` // "majority", "n_of_m" or "regions"
+	QuorumN           int             `json:"quorum_n"`
+	CooldownMS        int64           `json:"cooldown_ms"`
+	Enabled           bool            `json:"enabled"`
+	Options           *MonitorOptions `json:"options,omitempty"`
+	CreatedAt         int64           `json:"created_at"`
+	UpdatedAt         int64           `json:"updated_at"`
+
+	// BaselineAcceptedAt is when the monitor's current page content or DNS
+	// answers were last accepted. Nodes relearn the baselines they compare
+	// against when it changes.
+	BaselineAcceptedAt int64 `json:"baseline_accepted_at,omitempty"`
+}
+
+// MonitorOptions holds check-specific settings, stored as a single JSON
+// document rather than as individual columns on the monitors table.
+type MonitorOptions struct {
+	HTTP       *HTTPOptions       `json:"http,omitempty"`
+	Assertions []Assertion        `json:"assertions,omitempty"` // http, https and http_keyword checks
+	Content    *ContentOptions    `json:"content,omitempty"`    // http_keyword checks
+	Assets     *AssetOptions      `json:"assets,omitempty"`     // http and https checks
+	TLS        *TLSOptions        `json:"tls,omitempty"`        // tls checks; expiry_warn_days also applies to https
+	DNS        *DNSOptions        `json:"dns,omitempty"`
+	TCP        *TCPOptions        `json:"tcp,omitempty"`
+	ICMP       *ICMPOptions       `json:"icmp,omitempty"`
+	Traceroute *TracerouteOptions `json:"traceroute,omitempty"` // traceroute checks and incident path capture
+	GRPC       *GRPCOptions       `json:"grpc,omitempty"`
+	Database   *DatabaseOptions   `json:"database,omitempty"` // postgres, mysql and redis checks
+	Mail       *MailOptions       `json:"mail,omitempty"`     // smtp, imap and pop3 checks
+	UDP        *UDPOptions        `json:"udp,omitempty"`      // udp, ntp, snmp and radius checks
+	Stream     *StreamOptions     `json:"stream,omitempty"`   // websocket and sse checks
+	Push       *PushOptions       `json:"push,omitempty"`
+	HTTPFlow   *HTTPFlowOptions   `json:"http_flow,omitempty"`
+	Exec       *ExecOptions       `json:"exec,omitempty"`
+	Domain     *DomainOptions     `json:"domain,omitempty"`
+	Latency    *LatencyOptions    `json:"latency,omitempty"`
+	Degraded   *DegradedOptions   `json:"degraded,omitempty"`
+
+	// AddressFamily restricts the check to IPv4 or IPv6, or runs it over
+	// both; see the AddressFamily* constants. Default either, as resolved.
+	AddressFamily string `json:"address_family,omitempty"`
+}
+
+// Address families a monitor can be checked over. With AddressFamilyBoth
+// each check runs once per family and the worse result counts, so an
+// outage of one family is not hidden by the other.
+const (
+	AddressFamilyIPv4 = "v4"
+	AddressFamilyIPv6 = "v6"
+	AddressFamilyBoth = "both"
+)
+
+// LatencyOptions sets per-monitor latency thresholds. A successful check
+// slower than WarnMS is degraded; slower than CriticalMS it is down.
+type LatencyOptions struct {
+	WarnMS     float64 `json:"warn_ms,omitempty"`
+	CriticalMS float64 `json:"critical_ms,omitempty"`
+
+	// Phases sets thresholds on individual HTTP timing phases, keyed by
+	// the HTTPPhase* names.
+	Phases map[string]PhaseThreshold `json:"phases,omitempty"`
+}
+
+// PhaseThreshold is the warning and critical duration of one HTTP phase.
+type PhaseThreshold struct {
+	WarnMS     float64 `json:"warn_ms,omitempty"`
+	CriticalMS float64 `json:"critical_ms,omitempty"`
+}
+
+// HTTP timing phases recorded by http, https, http_keyword and http_flow
+// checks. TTFB is the wait between sending the request and the first
+// response byte; transfer is reading the rest of the response.
+const (
+	HTTPPhaseDNS      = "dns"
+	HTTPPhaseConnect  = "connect"
+	HTTPPhaseTLS      = "tls"
+	HTTPPhaseTTFB     = "ttfb"
+	HTTPPhaseTransfer = "transfer"
+)
+
+// HTTPPhases lists the HTTP timing phases in request order.
+var HTTPPhases = []string{HTTPPhaseDNS, HTTPPhaseConnect, HTTPPhaseTLS, HTTPPhaseTTFB, HTTPPhaseTransfer}
+
+// DegradedOptions enables a separate incident track for degraded results,
+// with its own quorum and alert severity. Without it, degraded results count
+// towards down incidents.
+type DegradedOptions struct {
+	Incidents  bool   `json:"incidents"`
+	QuorumType string `json:"quorum_type,omitempty"` // default: the monitor's quorum_type
+	QuorumN    int    `json:"quorum_n,omitempty"`
+	Severity   string `json:"severity,omitempty"` // default "warning"
+}
+
+// HTTPOptions customises the request sent by HTTP/HTTPS checks.
+type HTTPOptions struct {
+	Method            string            `json:"method,omitempty"` // default GET
+	Headers           map[string]string `json:"headers,omitempty"`
+	Body              string            `json:"body,omitempty"`
+	BasicAuthUser     string            `json:"basic_auth_user,omitempty"`
+	BasicAuthPassword string            `json:"basic_auth_password,omitempty"`
+	BearerToken       string            `json:"bearer_token,omitempty"`
+	FollowRedirects   *bool             `json:"follow_redirects,omitempty"` // default true
+	AcceptedStatus    string            `json:"accepted_status,omitempty"`  // e.g. "200-299,301"
+}
+
+// ContentOptions enables content-change detection on http_keyword checks.
+// The page is normalized to its visible text, one line per block, and
+// compared with the content first seen. Baselines are stored by each node
+// and survive restarts; they are relearned only when the target or ignore
+// rules change, or when the monitor's baseline is accepted.
+type ContentOptions struct {
+	// MinSimilarity is the percentage of the baseline text that must be
+	// unchanged, default 90. Below it the check is down and the diff is
+	// kept in the result details.
+	MinSimilarity float64 `json:"min_similarity,omitempty"`
+
+	// Ignore lists regular expressions whose matches in the normalized
+	// text, such as timestamps or rotating banners, are removed before
+	// comparing.
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// AssetOptions makes HTTP and HTTPS checks fetch the scripts, stylesheets
+// and images linked from an HTML response. The check is degraded when any
+// of them is broken, including active mixed content that browsers block,
+// or when the page outweighs MaxWeightKB.
+type AssetOptions struct {
+	MaxAssets   int  `json:"max_assets,omitempty"`    // subresources fetched, default 50
+	SkipImages  bool `json:"skip_images,omitempty"`   // fetch scripts and stylesheets only
+	MaxWeightKB int  `json:"max_weight_kb,omitempty"` // page plus assets; 0 for no limit
+}
+
+// TLSOptions configures certificate checks.
+type TLSOptions struct {
+	ServerName     string `json:"server_name,omitempty"`      // SNI and verified hostname, default target host
+	StartTLS       string `json:"starttls,omitempty"`         // "smtp", "imap" or "pop3"
+	ExpiryWarnDays int    `json:"expiry_warn_days,omitempty"` // degraded within this many days of expiry, default 7
+}
+
+// DNSOptions selects the resolvers and transport used by DNS checks.
+type DNSOptions struct {
+	// Resolvers lists servers as "host", "host:port", "system" (the first
+	// resolver in /etc/resolv.conf) or, for DoH, an https:// URL. Every
+	// resolver must succeed for the check to be up. Default 8.8.8.8.
+	Resolvers     []string `json:"resolvers,omitempty"`
+	Transport     string   `json:"transport,omitempty"`     // "udp" (default), "tcp", "tls" (DoT) or "https" (DoH)
+	Authoritative bool     `json:"authoritative,omitempty"` // send RD=0 and require the AA flag
+
+	// Expected lists answers to match with Match. When empty, the monitor's
+	// dns_expected value is used.
+	Expected []string `json:"expected,omitempty"`
+	Match    string   `json:"match,omitempty"` // see DNSMatch* constants, default "any"
+
+	DNSSEC           bool `json:"dnssec,omitempty"`            // require a validated (AD) or, with authoritative, signed answer
+	ConsistentSerial bool `json:"consistent_serial,omitempty"` // SOA checks: all resolvers must return the same serial
+	AlertOnChange    bool `json:"alert_on_change,omitempty"`   // report down when answers differ from those first seen
+}
+
+const (
+	DNSTransportUDP   = "udp"
+	DNSTransportTCP   = "tcp"
+	DNSTransportTLS   = "tls"
+	DNSTransportHTTPS = "https"
+
+	DNSMatchAny   = "any"   // at least one expected answer is present
+	DNSMatchAll   = "all"   // every expected answer is present
+	DNSMatchExact = "exact" // answers equal the expected set
+	DNSMatchRegex = "regex" // every expected pattern matches some answer
+)
+
+// TCPOptions adds TLS and a send/expect script to TCP checks.
+type TCPOptions struct {
+	TLS        bool      `json:"tls,omitempty"`
+	ServerName string    `json:"server_name,omitempty"` // SNI and verified hostname, default target
+	SkipVerify bool      `json:"skip_verify,omitempty"` // accept any certificate
+	ReadBanner bool      `json:"read_banner,omitempty"` // read what the server sends first
+	Steps      []TCPStep `json:"steps,omitempty"`
+}
+
+// TCPStep sends a payload and/or waits for a response. Steps run in order
+// and the first failing step marks the check down.
+type TCPStep struct {
+	Send          string `json:"send,omitempty"`
+	Expect        string `json:"expect,omitempty"`
+	Match         string `json:"match,omitempty"`           // "contains" (default) or "regex"
+	ReadTimeoutMS int    `json:"read_timeout_ms,omitempty"` // default: the rest of the monitor timeout
+}
+
+// ICMPOptions configures multi-packet pings. The check is down only when
+// no reply arrives, unless loss thresholds are set.
+type ICMPOptions struct {
+	Count               int     `json:"count,omitempty"`       // packets per check, default 1
+	IntervalMS          int64   `json:"interval_ms,omitempty"` // between packets, default 1000
+	Size                int     `json:"size,omitempty"`        // payload bytes, default 24 (the minimum)
+	DontFragment        bool    `json:"dont_fragment,omitempty"`
+	Privileged          bool    `json:"privileged,omitempty"` // raw sockets; needs root or CAP_NET_RAW
+	LossWarnPercent     float64 `json:"loss_warn_percent,omitempty"`
+	LossCriticalPercent float64 `json:"loss_critical_percent,omitempty"`
+}
+
+// GRPCOptions configures checks against the gRPC health checking protocol
+// (grpc.health.v1.Health/Check). Certificate paths are read on every node
+// that runs the check.
+type GRPCOptions struct {
+	Service    string            `json:"service,omitempty"` // empty checks the server as a whole
+	TLS        bool              `json:"tls,omitempty"`     // default plaintext
+	ServerName string            `json:"server_name,omitempty"`
+	SkipVerify bool              `json:"skip_verify,omitempty"`
+	CACert     string            `json:"ca_cert,omitempty"`     // PEM file replacing the system roots
+	ClientCert string            `json:"client_cert,omitempty"` // PEM files for mTLS
+	ClientKey  string            `json:"client_key,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`   // sent as request headers
+	StatusMap  map[string]string `json:"status_map,omitempty"` // health status to "up", "down" or "degraded"
+}
+
+// DatabaseOptions configures postgres, mysql and redis checks.
+type DatabaseOptions struct {
+	Credentials
+	Database    string  `json:"database,omitempty"`        // database name; for redis the DB index
+	Query       string  `json:"query,omitempty"`           // default "SELECT 1", or PING for redis
+	TLS         string  `json:"tls,omitempty"`             // see DBTLS* constants
+	Replication bool    `json:"replication,omitempty"`     // report the server's role and replication lag
+	MaxLagSec   float64 `json:"max_lag_seconds,omitempty"` // replicas lagging further are degraded
+}
+
+// Credentials authenticate a check against a server. The password can be
+// stored inline or, to keep it out of the database entirely, read on each
+// node from an environment variable or a file. Inline passwords are never
+// returned by the API.
+type Credentials struct {
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	PasswordEnv  string `json:"password_env,omitempty"`
+	PasswordFile string `json:"password_file,omitempty"`
+}
+
+// MailOptions configures smtp, imap and pop3 checks. Credentials are used
+// for SMTP AUTH or the IMAP/POP3 login; without them the check stops after
+// the greeting and TLS negotiation.
+type MailOptions struct {
+	Credentials
+	TLS        string `json:"tls,omitempty"` // see MailTLS* constants
+	ServerName string `json:"server_name,omitempty"`
+	SkipVerify bool   `json:"skip_verify,omitempty"`
+	HeloName   string `json:"helo_name,omitempty"` // smtp EHLO name, default "localhost"
+	Mailbox    string `json:"mailbox,omitempty"`   // imap mailbox examined after login, default INBOX
+
+	// RoundTrip, on smtp checks, sends a tagged message and waits for it to
+	// arrive in an IMAP mailbox.
+	RoundTrip *MailRoundTrip `json:"round_trip,omitempty"`
+}
+
+// MailRoundTrip describes where a round-trip message is sent and how its
+// delivery is confirmed.
+type MailRoundTrip struct {
+	From           string      `json:"from"`
+	To             string      `json:"to"`
+	IMAPHost       string      `json:"imap_host"`
+	IMAPPort       int         `json:"imap_port,omitempty"` // default 993, or 143 with starttls or none
+	IMAPTLS        string      `json:"imap_tls,omitempty"`  // see MailTLS* constants, default implicit
+	IMAP           Credentials `json:"imap"`
+	Mailbox        string      `json:"mailbox,omitempty"`          // default INBOX
+	PollIntervalMS int64       `json:"poll_interval_ms,omitempty"` // default 1000
+	KeepMessages   bool        `json:"keep_messages,omitempty"`    // leave delivered probes in the mailbox
+}
+
+const (
+	MailTLSNone     = "none"     // plaintext
+	MailTLSStartTLS = "starttls" // STARTTLS, required
+	MailTLSImplicit = "implicit" // TLS from the first byte (465, 993, 995)
+)
+
+// UDPOptions configures udp, ntp, snmp and radius checks. Requests are
+// resent every second until a reply arrives or the check times out. The
+// password is the SNMP community (default "public") or the RADIUS shared
+// secret.
+type UDPOptions struct {
+	Credentials
+	Send   string `json:"send,omitempty"`   // udp: datagram payload
+	Expect string `json:"expect,omitempty"` // udp reply or snmp value; empty accepts any
+	Match  string `json:"match,omitempty"`  // "contains" (default) or "regex"
+
+	WarnOffsetMS float64 `json:"warn_offset_ms,omitempty"` // ntp: degraded beyond this clock offset
+	MaxOffsetMS  float64 `json:"max_offset_ms,omitempty"`  // ntp: down beyond this clock offset
+	MaxStratum   int     `json:"max_stratum,omitempty"`    // ntp: down above this stratum, default 15
+
+	OID string `json:"oid,omitempty"` // snmp: object to GET, default sysUpTime.0 (1.3.6.1.2.1.1.3.0)
+}
+
+// StreamOptions configures websocket and sse checks. Both connect, then
+// wait for a message matching Expect: a WebSocket text or binary message,
+// or the data of a server-sent event. Messages that do not match are
+// skipped. Request headers and authentication come from the HTTP options.
+type StreamOptions struct {
+	Send   string `json:"send,omitempty"`    // websocket: text message sent once connected
+	Expect string `json:"expect,omitempty"`  // empty accepts the first message
+	Match  string `json:"match,omitempty"`   // "contains" (default) or "regex"
+	Event  string `json:"event,omitempty"`   // sse: event type to wait for; empty accepts any
+	WaitMS int64  `json:"wait_ms,omitempty"` // how long to wait once connected, default the rest of the timeout
+}
+
+// RedactedSecret replaces stored secrets in API responses and config sync.
+// Sending it back on update keeps the stored value.
+const RedactedSecret = "********"
+
+const (
+	DBTLSDisable = "disable" // plaintext
+	DBTLSRequire = "require" // TLS without certificate verification
+	DBTLSVerify  = "verify"  // TLS with chain and hostname verification
+)
+
+// TracerouteOptions configures traceroute checks and the path captured
+// when a down incident opens.
+type TracerouteOptions struct {
+	Protocol       string `json:"protocol,omitempty"`         // "icmp" (default), "udp" or "tcp"
+	Port           int    `json:"port,omitempty"`             // udp base port (default 33434) or tcp port (default the monitor's)
+	MaxHops        int    `json:"max_hops,omitempty"`         // default 30
+	Probes         int    `json:"probes,omitempty"`           // probes per hop, default 3
+	ProbeTimeoutMS int64  `json:"probe_timeout_ms,omitempty"` // default 1000
+	NoCapture      bool   `json:"no_capture,omitempty"`       // skip path capture on incidents
+}
+
+const (
+	TraceICMP = "icmp"
+	TraceUDP  = "udp"
+	TraceTCP  = "tcp"
+)
+
+// Hop is one TTL step of a traceroute, aggregated over its probes.
+type Hop struct {
+	TTL         int      `json:"ttl"`
+	Address     string   `json:"address,omitempty"`   // most frequent responder, lowest on ties, empty if none
+	Addresses   []string `json:"addresses,omitempty"` // all responders when ECMP spreads probes
+	Sent        int      `json:"sent"`
+	Received    int      `json:"received"`
+	LossPercent float64  `json:"loss_percent"`
+	MinMS       float64  `json:"min_ms,omitempty"`
+	AvgMS       float64  `json:"avg_ms,omitempty"`
+	MaxMS       float64  `json:"max_ms,omitempty"`
+}
+
+// TraceResult is the outcome of one traceroute.
+type TraceResult struct {
+	Target   string `json:"target"`
+	Protocol string `json:"protocol"`
+	Reached  bool   `json:"reached"`
+	Hops     []Hop  `json:"hops"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PushOptions configures push monitors, which wait for pings from a job
+// instead of probing a target. A ping is expected every interval_ms; the
+// monitor goes down when none arrives within the interval plus GraceMS.
+type PushOptions struct {
+	Token   string `json:"token"`              // secret part of the push URL, generated on create
+	GraceMS int64  `json:"grace_ms,omitempty"` // default 60000; also how long a started run may take
+}
+
+// PushPing is a ping received from a job reporting to a push monitor.
+type PushPing struct {
+	MonitorID  string `json:"monitor_id"`
+	NodeID     string `json:"node_id"` // node whose API received the ping
+	Kind       string `json:"kind"`    // see Push* constants
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Message    string `json:"message,omitempty"`
+	ReceivedAt int64  `json:"received_at"`
+}
+
+const (
+	PushStart   = "start"   // a run began
+	PushSuccess = "success" // a run finished successfully, or a plain heartbeat
+	PushFail    = "fail"    // a run failed
+)
+
+// PathCapture is a traceroute taken by one node when an incident opened.
+type PathCapture struct {
+	IncidentID string `json:"incident_id"`
+	NodeID     string `json:"node_id"`
+	Failing    bool   `json:"failing"` // node was failing the check when captured
+	TraceResult
+	CapturedAt int64 `json:"captured_at"`
+}
+
+// Assertion is a condition evaluated against an HTTP response. Any failing
+// assertion marks the check as down.
+type Assertion struct {
+	Source   string `json:"source"`             // "body", "header", "json" or "timing"
+	Property string `json:"property,omitempty"` // header name, JSONPath ($.a[0].b) / JSON pointer (/a/0/b), or HTTP phase
+	Operator string `json:"operator"`           // see Assert* constants
+	Value    string `json:"value,omitempty"`
+}
+
+const (
+	AssertSourceBody   = "body"
+	AssertSourceHeader = "header"
+	AssertSourceJSON   = "json"
+	AssertSourceTiming = "timing" // an HTTPPhase* duration in milliseconds
+
+	AssertEquals      = "equals"
+	AssertNotEquals   = "not_equals"
+	AssertContains    = "contains"
+	AssertNotContains = "not_contains"
+	AssertMatches     = "matches" // regular expression
+	AssertNotMatches  = "not_matches"
+	AssertExists      = "exists"
+	AssertNotExists   = "not_exists"
+	AssertLessThan    = "less_than" // numeric comparison
+	AssertGreaterThan = "greater_than"
+)
+
+// ExecOptions configures exec checks, which run a local executable such as
+// a Nagios plugin. Args may reference {{target}}, {{port}} and {{timeout}}
+// (whole seconds). The command must be allowed by each node's exec.allow
+// config.
+type ExecOptions struct {
+	Command string            `json:"command"` // absolute path
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"` // added to the PINGMESH_* variables
+}
+
+// DomainOptions configures domain checks, which look up the registrable
+// domain of the target over RDAP. Any difference between the observed
+// nameservers or status flags and Nameservers or Status marks the check
+// down; when they are not set, each node compares with the values it first
+// saw, relearned when the target changes or the baseline is accepted.
+type DomainOptions struct {
+	ExpiryWarnDays int      `json:"expiry_warn_days,omitempty"` // degraded within this many days of expiry, default 30
+	Nameservers    []string `json:"nameservers,omitempty"`      // expected nameservers, in any order
+	Status         []string `json:"status,omitempty"`           // expected RDAP status flags, e.g. "client transfer prohibited"
+	RDAPServer     string   `json:"rdap_server,omitempty"`      // RDAP base URL, default from the IANA bootstrap registry
+}
+
+// HTTPFlowOptions defines an http_flow check: requests run in order,
+// sharing cookies and variables, and the check is down at the first step
+// that fails. Strings in a step may reference variables as {{name}}, or
+// environment variables on the checking node as {{env.NAME}}, when the
+// node's secrets.env config lists them.
+type HTTPFlowOptions struct {
+	Variables map[string]string `json:"variables,omitempty"` // initial values
+	Steps     []HTTPFlowStep    `json:"steps"`
+}
+
+// HTTPFlowStep is one request in an http_flow check. Its HTTPOptions set
+// the method, headers, body, auth and accepted status; without
+// accepted_status any 4xx or 5xx response fails the step.
+type HTTPFlowStep struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"` // absolute, or resolved against the monitor target
+	HTTPOptions
+	Assertions []Assertion     `json:"assertions,omitempty"`
+	Extract    []FlowExtractor `json:"extract,omitempty"`
+}
+
+// FlowExtractor copies a value from a step's response into a variable for
+// later steps. Property is a JSONPath or JSON pointer for json, a header
+// name for header, and a regular expression for body, whose first capture
+// group (or whole match) is taken.
+type FlowExtractor struct {
+	Variable string `json:"variable"`
+	Source   string `json:"source"` // AssertSource* constants
+	Property string `json:"property"`
 }
 
 // CheckStatus represents the outcome of a check.
@@ -85,6 +576,17 @@ type CheckResult struct {
 	Timestamp  int64           `json:"timestamp"`
 }
 
+// Baseline is the reference a stateful check compares its results against,
+// such as a page's text or a DNS answer set, as learned by one node.
+type Baseline struct {
+	MonitorID  string          `json:"monitor_id"`
+	Key        string          `json:"key"`         // what it is a baseline of, e.g. "dns_answers"
+	Source     string          `json:"source"`      // the monitor settings it was learned with
+	AcceptedAt int64           `json:"accepted_at"` // the monitor's baseline_accepted_at when learned
+	Data       json.RawMessage `json:"data"`
+	LearnedAt  int64           `json:"learned_at"`
+}
+
 // IncidentStatus represents the lifecycle state of an incident.
 type IncidentStatus string
 
@@ -94,17 +596,30 @@ const (
 	IncidentResolved  IncidentStatus = "resolved"
 )
 
-// Incident represents a detected outage.
+// Incident kinds: outages are driven by down results, degraded incidents by
+// degraded results when the monitor opts in.
+const (
+	IncidentKindDown     = "down"
+	IncidentKindDegraded = "degraded"
+
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
+// Incident represents a detected outage or period of degraded service.
 type Incident struct {
-	ID              string         `json:"id"`
-	MonitorID       string         `json:"monitor_id"`
-	Status          IncidentStatus `json:"status"`
-	StartedAt       int64          `json:"started_at"`
-	ConfirmedAt     int64          `json:"confirmed_at,omitempty"`
-	ResolvedAt      int64          `json:"resolved_at,omitempty"`
-	ConfirmingNodes []string       `json:"confirming_nodes,omitempty"`
-	CreatedAt       int64          `json:"created_at"`
-	UpdatedAt       int64          `json:"updated_at"`
+	ID                string              `json:"id"`
+	MonitorID         string              `json:"monitor_id"`
+	Kind              string              `json:"kind"`
+	Severity          string              `json:"severity"`
+	Status            IncidentStatus      `json:"status"`
+	StartedAt         int64               `json:"started_at"`
+	ConfirmedAt       int64               `json:"confirmed_at,omitempty"`
+	ResolvedAt        int64               `json:"resolved_at,omitempty"`
+	ConfirmingNodes   []string            `json:"confirming_nodes,omitempty"`
+	ConfirmingRegions map[string][]string `json:"confirming_regions,omitempty"` // node IDs by location
+	CreatedAt         int64               `json:"created_at"`
+	UpdatedAt         int64               `json:"updated_at"`
 }
 
 // AlertChannel defines a notification channel (webhook or email).
@@ -157,13 +672,16 @@ type WebhookPayload struct {
 
 // IncidentDetail is the incident portion of a webhook payload.
 type IncidentDetail struct {
-	ID              string   `json:"id"`
-	Status          string   `json:"status"`
-	StartedAt       string   `json:"started_at"`
-	ConfirmedAt     string   `json:"confirmed_at,omitempty"`
-	ResolvedAt      string   `json:"resolved_at,omitempty"`
-	ConfirmingNodes []string `json:"confirming_nodes"`
-	DurationSec     int64    `json:"duration_sec"`
+	ID                string              `json:"id"`
+	Kind              string              `json:"kind"`
+	Severity          string              `json:"severity"`
+	Status            string              `json:"status"`
+	StartedAt         string              `json:"started_at"`
+	ConfirmedAt       string              `json:"confirmed_at,omitempty"`
+	ResolvedAt        string              `json:"resolved_at,omitempty"`
+	ConfirmingNodes   []string            `json:"confirming_nodes"`
+	ConfirmingRegions map[string][]string `json:"confirming_regions"`
+	DurationSec       int64               `json:"duration_sec"`
 }
 
 // MonitorSummary is the monitor portion of a webhook payload.
@@ -183,6 +701,13 @@ type PeerCheckRequest struct {
 	Timestamp   string `json:"timestamp"`
 }
 
+// PeerTraceRequest asks a peer node to trace the path to a monitor's target.
+type PeerTraceRequest struct {
+	MonitorID   string `json:"monitor_id"`
+	IncidentID  string `json:"incident_id"`
+	RequestedBy string `json:"requested_by"`
+}
+
 // PeerCheckResponse is the result of a peer check request.
 type PeerCheckResponse struct {
 	RequestID string          `json:"request_id"`
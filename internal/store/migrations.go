@@ -1,6 +1,11 @@
 package store
 
-const schemaVersion = 1
+import (
+	"database/sql"
+	"fmt"
+)
+
+const schemaVersion = 6
 
 const migrationSQL = `
 CREATE TABLE IF NOT EXISTS nodes (
@@ -67,6 +72,33 @@ CREATE TABLE IF NOT EXISTS incidents (
 
 CREATE INDEX IF NOT EXISTS idx_incidents_monitor ON incidents(monitor_id, status);
 
+CREATE TABLE IF NOT EXISTS incident_paths (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    incident_id TEXT NOT NULL REFERENCES incidents(id),
+    node_id     TEXT NOT NULL,
+    failing     INTEGER NOT NULL,
+    target      TEXT NOT NULL,
+    protocol    TEXT NOT NULL,
+    reached     INTEGER NOT NULL,
+    hops        TEXT NOT NULL,
+    error       TEXT,
+    captured_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_paths_incident ON incident_paths(incident_id);
+
+CREATE TABLE IF NOT EXISTS push_pings (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    monitor_id  TEXT NOT NULL REFERENCES monitors(id),
+    node_id     TEXT NOT NULL,
+    kind        TEXT NOT NULL,
+    duration_ms INTEGER,
+    message     TEXT,
+    received_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_pings_monitor ON push_pings(monitor_id, received_at);
+
 CREATE TABLE IF NOT EXISTS join_tokens (
     token_hash  TEXT PRIMARY KEY,
     expires_at  INTEGER NOT NULL,
@@ -103,13 +135,73 @@ CREATE TABLE IF NOT EXISTS schema_version (
 );
 `
 
+// upgrades holds incremental schema changes for databases created by an
+// earlier release. Each entry upgrades the schema to the given version.
+var upgrades = []struct {
+	version int
+	sql     string
+}{
+	{2, `ALTER TABLE monitors ADD COLUMN options TEXT`},
+	{3, `ALTER TABLE incidents ADD COLUMN kind TEXT NOT NULL DEFAULT 'down';
+	     ALTER TABLE incidents ADD COLUMN severity TEXT NOT NULL DEFAULT 'critical';`},
+	{4, `ALTER TABLE nodes ADD COLUMN weight REAL NOT NULL DEFAULT 1;
+	     ALTER TABLE nodes ADD COLUMN down_reports INTEGER NOT NULL DEFAULT 0;
+	     ALTER TABLE nodes ADD COLUMN false_positives INTEGER NOT NULL DEFAULT 0;`},
+	{5, `ALTER TABLE incidents ADD COLUMN confirming_regions TEXT`},
+	{6, `ALTER TABLE monitors ADD COLUMN baseline_accepted_at INTEGER NOT NULL DEFAULT 0;
+	     CREATE TABLE IF NOT EXISTS baselines (
+	         monitor_id  TEXT NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	         key         TEXT NOT NULL,
+	         source      TEXT NOT NULL,
+	         accepted_at INTEGER NOT NULL,
+	         data        TEXT NOT NULL,
+	         learned_at  INTEGER NOT NULL,
+	         PRIMARY KEY (monitor_id, key)
+	     );`},
+}
+
 func (s *SQLiteStore) migrate() error {
 	_, err := s.db.Exec(migrationSQL)
 	if err != nil {
 		return err
 	}
 
+	// A missing schema_version row means a freshly created database, which
+	// starts at version 1 and then runs every upgrade.
+	current := 1
+	if err := s.db.QueryRow(`SELECT version FROM schema_version WHERE rowid = 1`).Scan(&current); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	for _, u := range upgrades {
+		if u.version <= current {
+			continue
+		}
+		if err := s.upgrade(u.version, u.sql); err != nil {
+			return fmt.Errorf("upgrading schema to v%d: %w", u.version, err)
+		}
+	}
+
 	// Set schema version
 	_, err = s.db.Exec(`INSERT OR REPLACE INTO schema_version (rowid, version) VALUES (1, ?)`, schemaVersion)
 	return err
 }
+
+// upgrade applies one schema upgrade and records its version in a single
+// transaction, so an upgrade that fails partway leaves no columns behind
+// and is retried in full on the next start.
+func (s *SQLiteStore) upgrade(version int, upgradeSQL string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(upgradeSQL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO schema_version (rowid, version) VALUES (1, ?)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
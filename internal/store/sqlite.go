@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/pingmesh/pingmesh/internal/model"
@@ -43,17 +45,19 @@ func (s *SQLiteStore) Close() error {
 
 func (s *SQLiteStore) CreateNode(node *model.Node) error {
 	_, err := s.db.Exec(
-		`INSERT INTO nodes (id, name, location, address, role, status, last_seen, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO nodes (id, name, location, address, role, status, last_seen, created_at, weight, down_reports, false_positives)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		node.ID, node.Name, node.Location, node.Address, node.Role, node.Status, node.LastSeen, node.CreatedAt,
+		node.Weight, node.DownReports, node.FalsePositives,
 	)
 	return err
 }
 
 func (s *SQLiteStore) GetNode(id string) (*model.Node, error) {
-	row := s.db.QueryRow(`SELECT id, name, location, address, role, status, last_seen, created_at FROM nodes WHERE id = ?`, id)
+	row := s.db.QueryRow(`SELECT id, name, location, address, role, status, last_seen, created_at, weight, down_reports, false_positives FROM nodes WHERE id = ?`, id)
 	var n model.Node
-	err := row.Scan(&n.ID, &n.Name, &n.Location, &n.Address, &n.Role, &n.Status, &n.LastSeen, &n.CreatedAt)
+	err := row.Scan(&n.ID, &n.Name, &n.Location, &n.Address, &n.Role, &n.Status, &n.LastSeen, &n.CreatedAt,
+		&n.Weight, &n.DownReports, &n.FalsePositives)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -64,7 +68,7 @@ func (s *SQLiteStore) GetNode(id string) (*model.Node, error) {
 }
 
 func (s *SQLiteStore) ListNodes() ([]model.Node, error) {
-	rows, err := s.db.Query(`SELECT id, name, location, address, role, status, last_seen, created_at FROM nodes ORDER BY created_at`)
+	rows, err := s.db.Query(`SELECT id, name, location, address, role, status, last_seen, created_at, weight, down_reports, false_positives FROM nodes ORDER BY created_at`)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +77,8 @@ func (s *SQLiteStore) ListNodes() ([]model.Node, error) {
 	var nodes []model.Node
 	for rows.Next() {
 		var n model.Node
-		if err := rows.Scan(&n.ID, &n.Name, &n.Location, &n.Address, &n.Role, &n.Status, &n.LastSeen, &n.CreatedAt); err != nil {
+		if err := rows.Scan(&n.ID, &n.Name, &n.Location, &n.Address, &n.Role, &n.Status, &n.LastSeen, &n.CreatedAt,
+			&n.Weight, &n.DownReports, &n.FalsePositives); err != nil {
 			return nil, err
 		}
 		nodes = append(nodes, n)
@@ -81,10 +86,14 @@ func (s *SQLiteStore) ListNodes() ([]model.Node, error) {
 	return nodes, rows.Err()
 }
 
+// UpdateNode saves a node's settings. Its report counters are left alone,
+// as they change concurrently through RecordNodeReport and ResetNodeTrust.
 func (s *SQLiteStore) UpdateNode(node *model.Node) error {
 	_, err := s.db.Exec(
-		`UPDATE nodes SET name = ?, location = ?, address = ?, role = ?, status = ?, last_seen = ? WHERE id = ?`,
-		node.Name, node.Location, node.Address, node.Role, node.Status, node.LastSeen, node.ID,
+		`UPDATE nodes SET name = ?, location = ?, address = ?, role = ?, status = ?, last_seen = ?,
+		 weight = ? WHERE id = ?`,
+		node.Name, node.Location, node.Address, node.Role, node.Status, node.LastSeen,
+		node.Weight, node.ID,
 	)
 	return err
 }
@@ -99,21 +108,35 @@ func (s *SQLiteStore) UpdateNodeStatus(id string, status string, lastSeen int64)
 	return err
 }
 
+// RecordNodeReport counts one down report by a node, and whether the
+// quorum disagreed with it.
+func (s *SQLiteStore) RecordNodeReport(id string, falsePositive bool) error {
+	_, err := s.db.Exec(`UPDATE nodes SET down_reports = down_reports + 1, false_positives = false_positives + ? WHERE id = ?`,
+		boolToInt(falsePositive), id)
+	return err
+}
+
+// ResetNodeTrust clears a node's report counters, restoring full trust.
+func (s *SQLiteStore) ResetNodeTrust(id string) error {
+	_, err := s.db.Exec(`UPDATE nodes SET down_reports = 0, false_positives = 0 WHERE id = ?`, id)
+	return err
+}
+
 // --- Monitor operations ---
 
 func (s *SQLiteStore) CreateMonitor(monitor *model.Monitor) error {
 	_, err := s.db.Exec(
 		`INSERT INTO monitors (id, name, group_name, check_type, target, port, interval_ms, timeout_ms,
 		 retries, expected_status, expected_keyword, dns_record_type, dns_expected,
-		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, options, baseline_accepted_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		monitor.ID, monitor.Name, monitor.GroupName, string(monitor.CheckType), monitor.Target,
 		nullInt(monitor.Port), monitor.IntervalMS, monitor.TimeoutMS, monitor.Retries,
 		nullInt(monitor.ExpectedStatus), nullString(monitor.ExpectedKeyword),
 		nullString(monitor.DNSRecordType), nullString(monitor.DNSExpected),
 		monitor.FailureThreshold, monitor.RecoveryThreshold,
 		monitor.QuorumType, monitor.QuorumN, monitor.CooldownMS,
-		boolToInt(monitor.Enabled), monitor.CreatedAt, monitor.UpdatedAt,
+		boolToInt(monitor.Enabled), marshalOptions(monitor.Options), monitor.BaselineAcceptedAt, monitor.CreatedAt, monitor.UpdatedAt,
 	)
 	return err
 }
@@ -122,7 +145,7 @@ func (s *SQLiteStore) GetMonitor(id string) (*model.Monitor, error) {
 	row := s.db.QueryRow(
 		`SELECT id, name, group_name, check_type, target, port, interval_ms, timeout_ms,
 		 retries, expected_status, expected_keyword, dns_record_type, dns_expected,
-		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, created_at, updated_at
+		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, options, baseline_accepted_at, created_at, updated_at
 		 FROM monitors WHERE id = ?`, id)
 
 	return scanMonitor(row)
@@ -135,13 +158,13 @@ func (s *SQLiteStore) ListMonitors(groupName string) ([]model.Monitor, error) {
 		rows, err = s.db.Query(
 			`SELECT id, name, group_name, check_type, target, port, interval_ms, timeout_ms,
 			 retries, expected_status, expected_keyword, dns_record_type, dns_expected,
-			 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, created_at, updated_at
+			 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, options, baseline_accepted_at, created_at, updated_at
 			 FROM monitors WHERE group_name = ? ORDER BY name`, groupName)
 	} else {
 		rows, err = s.db.Query(
 			`SELECT id, name, group_name, check_type, target, port, interval_ms, timeout_ms,
 			 retries, expected_status, expected_keyword, dns_record_type, dns_expected,
-			 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, created_at, updated_at
+			 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, options, baseline_accepted_at, created_at, updated_at
 			 FROM monitors ORDER BY name`)
 	}
 	if err != nil {
@@ -165,7 +188,7 @@ func (s *SQLiteStore) UpdateMonitor(monitor *model.Monitor) error {
 		`UPDATE monitors SET name = ?, group_name = ?, check_type = ?, target = ?, port = ?,
 		 interval_ms = ?, timeout_ms = ?, retries = ?, expected_status = ?, expected_keyword = ?,
 		 dns_record_type = ?, dns_expected = ?, failure_threshold = ?, recovery_threshold = ?,
-		 quorum_type = ?, quorum_n = ?, cooldown_ms = ?, enabled = ?, updated_at = ?
+		 quorum_type = ?, quorum_n = ?, cooldown_ms = ?, enabled = ?, options = ?, baseline_accepted_at = ?, updated_at = ?
 		 WHERE id = ?`,
 		monitor.Name, monitor.GroupName, string(monitor.CheckType), monitor.Target,
 		nullInt(monitor.Port), monitor.IntervalMS, monitor.TimeoutMS, monitor.Retries,
@@ -173,7 +196,7 @@ func (s *SQLiteStore) UpdateMonitor(monitor *model.Monitor) error {
 		nullString(monitor.DNSRecordType), nullString(monitor.DNSExpected),
 		monitor.FailureThreshold, monitor.RecoveryThreshold,
 		monitor.QuorumType, monitor.QuorumN, monitor.CooldownMS,
-		boolToInt(monitor.Enabled), monitor.UpdatedAt, monitor.ID,
+		boolToInt(monitor.Enabled), marshalOptions(monitor.Options), monitor.BaselineAcceptedAt, monitor.UpdatedAt, monitor.ID,
 	)
 	return err
 }
@@ -187,7 +210,7 @@ func (s *SQLiteStore) ListEnabledMonitors() ([]model.Monitor, error) {
 	rows, err := s.db.Query(
 		`SELECT id, name, group_name, check_type, target, port, interval_ms, timeout_ms,
 		 retries, expected_status, expected_keyword, dns_record_type, dns_expected,
-		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, created_at, updated_at
+		 failure_threshold, recovery_threshold, quorum_type, quorum_n, cooldown_ms, enabled, options, baseline_accepted_at, created_at, updated_at
 		 FROM monitors WHERE enabled = 1 ORDER BY name`)
 	if err != nil {
 		return nil, err
@@ -250,33 +273,9 @@ func (s *SQLiteStore) GetLatestResult(monitorID, nodeID string) (*model.CheckRes
 	return &r, nil
 }
 
-func (s *SQLiteStore) CountConsecutiveFailures(monitorID, nodeID string) (int, error) {
-	rows, err := s.db.Query(
-		`SELECT status FROM check_results
-		 WHERE monitor_id = ? AND node_id = ?
-		 ORDER BY timestamp DESC LIMIT 100`,
-		monitorID, nodeID)
-	if err != nil {
-		return 0, err
-	}
-	defer rows.Close()
-
-	count := 0
-	for rows.Next() {
-		var status string
-		if err := rows.Scan(&status); err != nil {
-			return 0, err
-		}
-		if status != string(model.StatusUp) {
-			count++
-		} else {
-			break
-		}
-	}
-	return count, rows.Err()
-}
-
-func (s *SQLiteStore) CountConsecutiveSuccesses(monitorID, nodeID string) (int, error) {
+// CountConsecutiveResults counts the most recent run of results whose status
+// is one of statuses, stopping at the first result that doesn't match.
+func (s *SQLiteStore) CountConsecutiveResults(monitorID, nodeID string, statuses ...model.CheckStatus) (int, error) {
 	rows, err := s.db.Query(
 		`SELECT status FROM check_results
 		 WHERE monitor_id = ? AND node_id = ?
@@ -293,11 +292,10 @@ func (s *SQLiteStore) CountConsecutiveSuccesses(monitorID, nodeID string) (int,
 		if err := rows.Scan(&status); err != nil {
 			return 0, err
 		}
-		if status == string(model.StatusUp) {
-			count++
-		} else {
+		if !slices.Contains(statuses, model.CheckStatus(status)) {
 			break
 		}
+		count++
 	}
 	return count, rows.Err()
 }
@@ -353,47 +351,117 @@ func (s *SQLiteStore) ListCheckResults(monitorID, nodeID string, since int64, li
 	return results, rows.Err()
 }
 
+// --- Push monitor operations ---
+
+func (s *SQLiteStore) InsertPushPing(ping *model.PushPing) error {
+	_, err := s.db.Exec(
+		`INSERT INTO push_pings (monitor_id, node_id, kind, duration_ms, message, received_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		ping.MonitorID, ping.NodeID, ping.Kind, nullInt64(ping.DurationMS), nullString(ping.Message), ping.ReceivedAt,
+	)
+	return err
+}
+
+// GetLastPushPing returns the most recent ping for the monitor whose kind is
+// one of kinds, or of any kind when none are given.
+func (s *SQLiteStore) GetLastPushPing(monitorID string, kinds ...string) (*model.PushPing, error) {
+	query := `SELECT monitor_id, node_id, kind, duration_ms, message, received_at
+		 FROM push_pings WHERE monitor_id = ?`
+	args := []any{monitorID}
+	if len(kinds) > 0 {
+		query += ` AND kind IN (?` + strings.Repeat(`, ?`, len(kinds)-1) + `)`
+		for _, k := range kinds {
+			args = append(args, k)
+		}
+	}
+	query += ` ORDER BY received_at DESC, id DESC LIMIT 1`
+
+	var p model.PushPing
+	var duration sql.NullInt64
+	var message sql.NullString
+	err := s.db.QueryRow(query, args...).Scan(&p.MonitorID, &p.NodeID, &p.Kind, &duration, &message, &p.ReceivedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.DurationMS = duration.Int64
+	p.Message = message.String
+	return &p, nil
+}
+
+// --- Baseline operations ---
+
+// GetBaseline returns the monitor's baseline stored under key, or nil.
+func (s *SQLiteStore) GetBaseline(monitorID, key string) (*model.Baseline, error) {
+	var b model.Baseline
+	var data string
+	err := s.db.QueryRow(
+		`SELECT monitor_id, key, source, accepted_at, data, learned_at
+		 FROM baselines WHERE monitor_id = ? AND key = ?`, monitorID, key).
+		Scan(&b.MonitorID, &b.Key, &b.Source, &b.AcceptedAt, &data, &b.LearnedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.Data = json.RawMessage(data)
+	return &b, nil
+}
+
+// SaveBaseline creates or replaces the monitor's baseline under its key.
+func (s *SQLiteStore) SaveBaseline(baseline *model.Baseline) error {
+	_, err := s.db.Exec(
+		`INSERT OR REPLACE INTO baselines (monitor_id, key, source, accepted_at, data, learned_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		baseline.MonitorID, baseline.Key, baseline.Source, baseline.AcceptedAt, string(baseline.Data), baseline.LearnedAt,
+	)
+	return err
+}
+
 // --- Incident operations ---
 
 func (s *SQLiteStore) CreateIncident(incident *model.Incident) error {
 	nodesJSON, _ := json.Marshal(incident.ConfirmingNodes)
 	_, err := s.db.Exec(
-		`INSERT INTO incidents (id, monitor_id, status, started_at, confirmed_at, resolved_at, confirming_nodes, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		incident.ID, incident.MonitorID, string(incident.Status), incident.StartedAt,
+		`INSERT INTO incidents (id, monitor_id, kind, severity, status, started_at, confirmed_at, resolved_at, confirming_nodes, confirming_regions, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		incident.ID, incident.MonitorID, incident.Kind, incident.Severity, string(incident.Status), incident.StartedAt,
 		nullInt64(incident.ConfirmedAt), nullInt64(incident.ResolvedAt),
-		string(nodesJSON), incident.CreatedAt, incident.UpdatedAt,
+		string(nodesJSON), marshalRegions(incident.ConfirmingRegions), incident.CreatedAt, incident.UpdatedAt,
 	)
 	return err
 }
 
 func (s *SQLiteStore) GetIncident(id string) (*model.Incident, error) {
 	row := s.db.QueryRow(
-		`SELECT id, monitor_id, status, started_at, confirmed_at, resolved_at, confirming_nodes, created_at, updated_at
+		`SELECT id, monitor_id, kind, severity, status, started_at, confirmed_at, resolved_at, confirming_nodes, confirming_regions, created_at, updated_at
 		 FROM incidents WHERE id = ?`, id)
 	return scanIncident(row)
 }
 
-func (s *SQLiteStore) GetActiveIncident(monitorID string) (*model.Incident, error) {
+func (s *SQLiteStore) GetActiveIncident(monitorID, kind string) (*model.Incident, error) {
 	row := s.db.QueryRow(
-		`SELECT id, monitor_id, status, started_at, confirmed_at, resolved_at, confirming_nodes, created_at, updated_at
-		 FROM incidents WHERE monitor_id = ? AND status != 'resolved' ORDER BY created_at DESC LIMIT 1`, monitorID)
+		`SELECT id, monitor_id, kind, severity, status, started_at, confirmed_at, resolved_at, confirming_nodes, confirming_regions, created_at, updated_at
+		 FROM incidents WHERE monitor_id = ? AND kind = ? AND status != 'resolved' ORDER BY created_at DESC LIMIT 1`, monitorID, kind)
 	return scanIncident(row)
 }
 
 func (s *SQLiteStore) UpdateIncident(incident *model.Incident) error {
 	nodesJSON, _ := json.Marshal(incident.ConfirmingNodes)
 	_, err := s.db.Exec(
-		`UPDATE incidents SET status = ?, confirmed_at = ?, resolved_at = ?, confirming_nodes = ?, updated_at = ?
+		`UPDATE incidents SET status = ?, confirmed_at = ?, resolved_at = ?, confirming_nodes = ?, confirming_regions = ?, updated_at = ?
 		 WHERE id = ?`,
 		string(incident.Status), nullInt64(incident.ConfirmedAt), nullInt64(incident.ResolvedAt),
-		string(nodesJSON), incident.UpdatedAt, incident.ID,
+		string(nodesJSON), marshalRegions(incident.ConfirmingRegions), incident.UpdatedAt, incident.ID,
 	)
 	return err
 }
 
 func (s *SQLiteStore) ListIncidents(activeOnly bool) ([]model.Incident, error) {
-	query := `SELECT id, monitor_id, status, started_at, confirmed_at, resolved_at, confirming_nodes, created_at, updated_at FROM incidents`
+	query := `SELECT id, monitor_id, kind, severity, status, started_at, confirmed_at, resolved_at, confirming_nodes, confirming_regions, created_at, updated_at FROM incidents`
 	if activeOnly {
 		query += ` WHERE status != 'resolved'`
 	}
@@ -416,6 +484,47 @@ func (s *SQLiteStore) ListIncidents(activeOnly bool) ([]model.Incident, error) {
 	return incidents, rows.Err()
 }
 
+func (s *SQLiteStore) InsertPathCapture(capture *model.PathCapture) error {
+	hopsJSON, _ := json.Marshal(capture.Hops)
+	_, err := s.db.Exec(
+		`INSERT INTO incident_paths (incident_id, node_id, failing, target, protocol, reached, hops, error, captured_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		capture.IncidentID, capture.NodeID, boolToInt(capture.Failing), capture.Target, capture.Protocol,
+		boolToInt(capture.Reached), string(hopsJSON), nullString(capture.Error), capture.CapturedAt,
+	)
+	return err
+}
+
+func (s *SQLiteStore) ListPathCaptures(incidentID string) ([]model.PathCapture, error) {
+	rows, err := s.db.Query(
+		`SELECT incident_id, node_id, failing, target, protocol, reached, hops, error, captured_at
+		 FROM incident_paths WHERE incident_id = ? ORDER BY failing DESC, node_id`, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var captures []model.PathCapture
+	for rows.Next() {
+		var c model.PathCapture
+		var failing, reached int
+		var hopsJSON string
+		var errStr sql.NullString
+		if err := rows.Scan(&c.IncidentID, &c.NodeID, &failing, &c.Target, &c.Protocol, &reached,
+			&hopsJSON, &errStr, &c.CapturedAt); err != nil {
+			return nil, err
+		}
+		c.Failing = failing == 1
+		c.Reached = reached == 1
+		c.Error = errStr.String
+		if err := json.Unmarshal([]byte(hopsJSON), &c.Hops); err != nil {
+			return nil, fmt.Errorf("decoding hops for incident %s: %w", incidentID, err)
+		}
+		captures = append(captures, c)
+	}
+	return captures, rows.Err()
+}
+
 // --- Join token operations ---
 
 func (s *SQLiteStore) StoreJoinToken(tokenHash string, expiresAt int64) error {
@@ -584,12 +693,13 @@ func scanMonitor(row scannable) (*model.Monitor, error) {
 	var dnsRecordType sql.NullString
 	var dnsExpected sql.NullString
 	var enabled int
+	var options sql.NullString
 
 	err := row.Scan(
 		&m.ID, &m.Name, &m.GroupName, &m.CheckType, &m.Target, &port,
 		&m.IntervalMS, &m.TimeoutMS, &m.Retries, &expectedStatus, &expectedKeyword,
 		&dnsRecordType, &dnsExpected, &m.FailureThreshold, &m.RecoveryThreshold,
-		&m.QuorumType, &m.QuorumN, &m.CooldownMS, &enabled, &m.CreatedAt, &m.UpdatedAt,
+		&m.QuorumType, &m.QuorumN, &m.CooldownMS, &enabled, &options, &m.BaselineAcceptedAt, &m.CreatedAt, &m.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -614,6 +724,13 @@ func scanMonitor(row scannable) (*model.Monitor, error) {
 		m.DNSExpected = dnsExpected.String
 	}
 	m.Enabled = enabled == 1
+	if options.Valid && options.String != "" {
+		var opts model.MonitorOptions
+		if err := json.Unmarshal([]byte(options.String), &opts); err != nil {
+			return nil, fmt.Errorf("parsing options for monitor %s: %w", m.ID, err)
+		}
+		m.Options = &opts
+	}
 
 	return &m, nil
 }
@@ -627,9 +744,10 @@ func scanIncident(row scannable) (*model.Incident, error) {
 	var confirmedAt sql.NullInt64
 	var resolvedAt sql.NullInt64
 	var nodesJSON string
+	var regionsJSON sql.NullString
 
-	err := row.Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.StartedAt,
-		&confirmedAt, &resolvedAt, &nodesJSON, &inc.CreatedAt, &inc.UpdatedAt)
+	err := row.Scan(&inc.ID, &inc.MonitorID, &inc.Kind, &inc.Severity, &inc.Status, &inc.StartedAt,
+		&confirmedAt, &resolvedAt, &nodesJSON, &regionsJSON, &inc.CreatedAt, &inc.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -646,6 +764,9 @@ func scanIncident(row scannable) (*model.Incident, error) {
 	if nodesJSON != "" {
 		json.Unmarshal([]byte(nodesJSON), &inc.ConfirmingNodes)
 	}
+	if regionsJSON.Valid {
+		json.Unmarshal([]byte(regionsJSON.String), &inc.ConfirmingRegions)
+	}
 
 	return &inc, nil
 }
@@ -654,6 +775,22 @@ func scanIncidentRow(rows *sql.Rows) (*model.Incident, error) {
 	return scanIncident(rows)
 }
 
+func marshalOptions(opts *model.MonitorOptions) sql.NullString {
+	if opts == nil {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(opts)
+	return nullString(string(data))
+}
+
+func marshalRegions(regions map[string][]string) sql.NullString {
+	if len(regions) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(regions)
+	return nullString(string(data))
+}
+
 func nullInt(v int) sql.NullInt64 {
 	if v == 0 {
 		return sql.NullInt64{}
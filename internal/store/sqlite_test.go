@@ -0,0 +1,163 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pingmesh/pingmesh/internal/model"
+)
+
+// openTestStore opens a fresh store in a temporary directory.
+func openTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "pingmesh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestNodeReportCounters(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.CreateNode(&model.Node{ID: "n1", Name: "node-1", Weight: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A node read before reports were recorded and saved afterwards must
+	// not roll the counters back.
+	stale, _ := s.GetNode("n1")
+	s.RecordNodeReport("n1", false)
+	s.RecordNodeReport("n1", true)
+	stale.Location = "fra"
+	stale.Weight = 2
+	if err := s.UpdateNode(stale); err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := s.GetNode("n1")
+	if n.DownReports != 2 || n.FalsePositives != 1 {
+		t.Errorf("counters after update = %d/%d, want 2/1", n.DownReports, n.FalsePositives)
+	}
+	if n.Location != "fra" || n.Weight != 2 {
+		t.Errorf("settings after update = %q/%v, want fra/2", n.Location, n.Weight)
+	}
+
+	if err := s.ResetNodeTrust("n1"); err != nil {
+		t.Fatal(err)
+	}
+	n, _ = s.GetNode("n1")
+	if n.DownReports != 0 || n.FalsePositives != 0 {
+		t.Errorf("counters after reset = %d/%d, want 0/0", n.DownReports, n.FalsePositives)
+	}
+	if n.Location != "fra" {
+		t.Errorf("reset changed the location to %q", n.Location)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	s := openTestStore(t)
+
+	var version int
+	if err := s.db.QueryRow(`SELECT version FROM schema_version WHERE rowid = 1`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != schemaVersion {
+		t.Errorf("schema version = %d, want %d", version, schemaVersion)
+	}
+
+	// Every upgrade's columns and tables exist on a fresh database.
+	for _, q := range []string{
+		`SELECT options, baseline_accepted_at FROM monitors`,
+		`SELECT kind, severity, confirming_regions FROM incidents`,
+		`SELECT weight, down_reports, false_positives FROM nodes`,
+		`SELECT monitor_id, key, source, accepted_at, data, learned_at FROM baselines`,
+	} {
+		if _, err := s.db.Exec(q); err != nil {
+			t.Errorf("%s: %v", q, err)
+		}
+	}
+
+	// Migrating again is a no-op.
+	if err := s.migrate(); err != nil {
+		t.Errorf("second migrate: %v", err)
+	}
+}
+
+func TestUpgradeRollsBack(t *testing.T) {
+	s := openTestStore(t)
+
+	// The second statement fails, so the first one's column must not be left
+	// behind and the version must not move.
+	err := s.upgrade(schemaVersion+1, `ALTER TABLE nodes ADD COLUMN extra TEXT;
+	     ALTER TABLE no_such_table ADD COLUMN extra TEXT;`)
+	if err == nil {
+		t.Fatal("upgrade with a failing statement succeeded")
+	}
+	if _, err := s.db.Exec(`SELECT extra FROM nodes`); err == nil {
+		t.Error("column from the failed upgrade was kept")
+	}
+	var version int
+	s.db.QueryRow(`SELECT version FROM schema_version WHERE rowid = 1`).Scan(&version)
+	if version != schemaVersion {
+		t.Errorf("schema version after failed upgrade = %d, want %d", version, schemaVersion)
+	}
+}
+
+func TestCountConsecutiveResults(t *testing.T) {
+	s := openTestStore(t)
+	s.CreateNode(&model.Node{ID: "n1", Name: "node-1", Weight: 1})
+	s.CreateMonitor(&model.Monitor{ID: "m1", Name: "api", CheckType: model.CheckHTTP, Target: "example.com"})
+
+	// Oldest first.
+	for i, status := range []model.CheckStatus{model.StatusUp, model.StatusDown, model.StatusDegraded, model.StatusDown} {
+		s.InsertCheckResult(&model.CheckResult{MonitorID: "m1", NodeID: "n1", Status: status, Timestamp: int64(i + 1)})
+	}
+
+	tests := []struct {
+		name     string
+		statuses []model.CheckStatus
+		want     int
+	}{
+		{"down only", []model.CheckStatus{model.StatusDown}, 1},
+		{"down or degraded", []model.CheckStatus{model.StatusDown, model.StatusDegraded}, 3},
+		{"up", []model.CheckStatus{model.StatusUp}, 0},
+	}
+	for _, tt := range tests {
+		got, err := s.CountConsecutiveResults("m1", "n1", tt.statuses...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: CountConsecutiveResults() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBaselines(t *testing.T) {
+	s := openTestStore(t)
+	s.CreateMonitor(&model.Monitor{ID: "m1", Name: "dns", CheckType: model.CheckDNS, Target: "example.com"})
+
+	if b, err := s.GetBaseline("m1", "dns_answers"); err != nil || b != nil {
+		t.Fatalf("GetBaseline() before saving = %+v, %v, want nil", b, err)
+	}
+
+	saved := &model.Baseline{MonitorID: "m1", Key: "dns_answers", Source: "example.com A",
+		AcceptedAt: 5, Data: []byte(`["192.0.2.1"]`), LearnedAt: 10}
+	if err := s.SaveBaseline(saved); err != nil {
+		t.Fatal(err)
+	}
+	saved.Data = []byte(`["192.0.2.2"]`)
+	if err := s.SaveBaseline(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := s.GetBaseline("m1", "dns_answers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, saved) {
+		t.Errorf("GetBaseline() = %+v, want the replaced baseline %+v", b, saved)
+	}
+}
@@ -13,6 +13,8 @@ type Store interface {
 	UpdateNode(node *model.Node) error
 	DeleteNode(id string) error
 	UpdateNodeStatus(id string, status string, lastSeen int64) error
+	RecordNodeReport(id string, falsePositive bool) error
+	ResetNodeTrust(id string) error
 
 	// Monitor operations
 	CreateMonitor(monitor *model.Monitor) error
@@ -25,16 +27,25 @@ type Store interface {
 	// Check result operations
 	InsertCheckResult(result *model.CheckResult) error
 	GetLatestResult(monitorID, nodeID string) (*model.CheckResult, error)
-	CountConsecutiveFailures(monitorID, nodeID string) (int, error)
-	CountConsecutiveSuccesses(monitorID, nodeID string) (int, error)
+	CountConsecutiveResults(monitorID, nodeID string, statuses ...model.CheckStatus) (int, error)
 	ListCheckResults(monitorID, nodeID string, since int64, limit int) ([]model.CheckResult, error)
 
+	// Baseline operations
+	GetBaseline(monitorID, key string) (*model.Baseline, error)
+	SaveBaseline(baseline *model.Baseline) error
+
+	// Push monitor operations
+	InsertPushPing(ping *model.PushPing) error
+	GetLastPushPing(monitorID string, kinds ...string) (*model.PushPing, error)
+
 	// Incident operations
 	CreateIncident(incident *model.Incident) error
 	GetIncident(id string) (*model.Incident, error)
-	GetActiveIncident(monitorID string) (*model.Incident, error)
+	GetActiveIncident(monitorID, kind string) (*model.Incident, error)
 	UpdateIncident(incident *model.Incident) error
 	ListIncidents(activeOnly bool) ([]model.Incident, error)
+	InsertPathCapture(capture *model.PathCapture) error
+	ListPathCaptures(incidentID string) ([]model.PathCapture, error)
 
 	// Join token operations
 	StoreJoinToken(tokenHash string, expiresAt int64) error
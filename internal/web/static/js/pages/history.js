@@ -1,5 +1,8 @@
 // PingMesh Dashboard — History Page Component
 
+// HTTP timing phases in request order, as recorded in result details.
+const HTTP_PHASES = ['dns', 'connect', 'tls', 'ttfb', 'transfer'];
+
 function historyPage() {
   return {
     results: [],
@@ -56,5 +59,42 @@ function historyPage() {
     monitorName(id) {
       return Alpine.store('app').monitorName(id);
     },
+
+    nodeName(id) {
+      const n = this.nodes.find(n => n.id === id);
+      return n ? n.name : id.substring(0, 8) + '...';
+    },
+
+    // Per-node phase timings of the selected monitor's HTTP results, oldest
+    // first, with segment heights scaled to the slowest result shown.
+    timingNodes() {
+      if (!this.filterMonitor) return [];
+      const byNode = {};
+      let max = 0;
+      for (const r of [...this.results].reverse()) {
+        const t = r.details && r.details.timings;
+        if (!t) continue;
+        const total = HTTP_PHASES.reduce((sum, p) => sum + (t[p + '_ms'] || 0), 0);
+        max = Math.max(max, total);
+        (byNode[r.node_id] = byNode[r.node_id] || []).push({ id: r.id, ts: r.timestamp, t, total });
+      }
+      return Object.entries(byNode).map(([nodeId, points]) => ({
+        nodeId,
+        name: this.nodeName(nodeId),
+        avg: HTTP_PHASES.map(p => ({
+          phase: p,
+          ms: points.reduce((sum, pt) => sum + (pt.t[p + '_ms'] || 0), 0) / points.length,
+        })),
+        points: points.map(pt => ({
+          id: pt.id,
+          title: formatTs(pt.ts) + '\n' + HTTP_PHASES
+            .filter(p => (p + '_ms') in pt.t)
+            .map(p => p + ': ' + pt.t[p + '_ms'].toFixed(1) + ' ms').join('\n'),
+          segments: HTTP_PHASES
+            .filter(p => pt.t[p + '_ms'] > 0)
+            .map(p => ({ phase: p, pct: max ? pt.t[p + '_ms'] / max * 100 : 0 })),
+        })),
+      }));
+    },
   };
 }
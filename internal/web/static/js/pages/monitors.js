@@ -64,7 +64,7 @@ function monitorsPage() {
     closeModal() { this.showModal = false; this.editing = null; },
 
     needsPort() {
-      return ['tcp', 'http', 'https', 'http_keyword'].includes(this.form.check_type);
+      return ['tcp', 'http', 'https', 'http_keyword', 'tls', 'grpc', 'postgres', 'mysql', 'redis', 'smtp', 'imap', 'pop3', 'ntp', 'snmp'].includes(this.form.check_type);
     },
     needsExpectedStatus() {
       return ['http', 'https', 'http_keyword'].includes(this.form.check_type);